@@ -6,18 +6,30 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"subscription-service/internal/auth"
 	"subscription-service/internal/config"
 	"subscription-service/internal/db"
+	"subscription-service/internal/events"
 	"subscription-service/internal/handler"
+	"subscription-service/internal/metrics"
+	"subscription-service/internal/model"
+	"subscription-service/internal/notify"
 	"subscription-service/internal/repository"
 	"subscription-service/internal/service"
+	"subscription-service/internal/tracing"
+	"subscription-service/internal/webhook"
 
 	_ "subscription-service/docs"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
@@ -35,6 +47,16 @@ import (
 
 // @host localhost:8090
 // @BasePath /
+
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description Bearer token: "Bearer <JWT>"
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+// @description API key for service-to-service access, limited to its scopes
 func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -50,8 +72,25 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// Metrics and tracing
+	registry := prometheus.NewRegistry()
+	httpMetrics := metrics.NewHTTPMetrics()
+	queryTracer := metrics.NewQueryTracer()
+
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		log.Fatalf("ERROR: failed to initialize tracing: %v", err)
+	}
+	defer func() {
+		ctxShutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctxShutdown); err != nil {
+			log.Printf("ERROR: tracing shutdown failed: %v", err)
+		}
+	}()
+
 	// 1️⃣ DB
-	database, err := db.Connect(ctx, cfg)
+	database, err := db.Connect(ctx, cfg, queryTracer, tracing.NewQueryTracer())
 	if err != nil {
 		log.Fatalf("ERROR: failed to connect to database: %v", err)
 	}
@@ -59,27 +98,178 @@ func main() {
 
 	// 2️⃣ Repository
 	subRepo := repository.NewSubscriptionRepository(database.Pool)
+	rateRepo := repository.NewCurrencyRateRepository(database.Pool)
+	auditRepo := repository.NewAuditRepository(database.Pool)
+	keyRepo := repository.NewAPIKeyRepository(database.Pool)
+	catalogRepo := repository.NewCatalogRepository(database.Pool)
+	reminderRepo := repository.NewReminderRepository(database.Pool)
+	webhookRepo := repository.NewWebhookRepository(database.Pool)
+	outboxRepo := repository.NewOutboxRepository(database.Pool)
+	statsRepo := repository.NewStatsRepository(database.Pool)
+
+	txManager := repository.NewTxManager(
+		database.Pool,
+		pgx.TxIsoLevel(cfg.Database.IsolationLevel),
+		cfg.Database.TxRetries,
+	)
 
 	// 3️⃣ Service
-	subService := service.NewSubscriptionService(subRepo)
+	subService := service.NewTracedSubscriptionService(
+		service.NewSubscriptionService(subRepo, rateRepo, catalogRepo, txManager),
+	)
+	rateService := service.NewCurrencyRateService(rateRepo)
+	auditService := service.NewAuditService(auditRepo)
+	keyService := service.NewAPIKeyService(keyRepo)
+	catalogService := service.NewCatalogService(catalogRepo)
+
+	var notifier notify.Notifier = notify.NewLogNotifier()
+	if cfg.Reminders.WebhookURL != "" {
+		notifier = notify.NewWebhookNotifier(cfg.Reminders.WebhookURL, cfg.Reminders.WebhookTimeout)
+	}
+	reminderService := service.NewReminderService(subRepo, reminderRepo, notifier, cfg.Reminders.DaysBefore)
+
+	webhookService := service.NewWebhookService(webhookRepo)
+	webhookDispatcher := service.NewWebhookDispatcher(
+		webhookRepo,
+		outboxRepo,
+		webhook.NewHTTPSender(cfg.Webhooks.Timeout),
+		service.RetryPolicy{
+			MaxAttempts: cfg.Webhooks.MaxAttempts,
+			Backoff:     cfg.Webhooks.RetryBackoff,
+			MaxBackoff:  cfg.Webhooks.MaxRetryBackoff,
+		},
+		cfg.Webhooks.BatchSize,
+		2*cfg.Webhooks.Timeout,
+	)
+
+	var publisher events.EventPublisher = events.NewMemoryPublisher()
+	switch cfg.Events.Publisher {
+	case "nats":
+		publisher, err = events.NewNATSPublisher(cfg.Events.URL, cfg.Events.Topic, cfg.Events.Timeout)
+	case "kafka":
+		publisher, err = events.NewKafkaPublisher(cfg.Events.URL, cfg.Events.Topic, cfg.Events.Timeout)
+	}
+	if err != nil {
+		log.Fatalf("ERROR: failed to initialize event publisher: %v", err)
+	}
+	defer func() { _ = publisher.Close() }()
+	eventRelay := service.NewEventRelay(outboxRepo, publisher, cfg.Events.BatchSize, 2*cfg.Events.Timeout)
+
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpMetrics,
+		queryTracer,
+		metrics.NewPoolCollector(database.Pool),
+		metrics.NewBusinessCollector(statsRepo),
+	)
 
 	// 4️⃣ Handler
 	subHandler := handler.NewSubscriptionHandler(subService)
+	rateHandler := handler.NewCurrencyRateHandler(rateService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	keyHandler := handler.NewAPIKeyHandler(keyService)
+	catalogHandler := handler.NewCatalogHandler(catalogService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
 
 	// 5️⃣ Router
 	r := chi.NewRouter()
+	r.Use(tracing.Middleware)
+	r.Use(httpMetrics.Middleware)
 	r.Use(handler.LoggingMiddleware)
-
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
+	r.Method(http.MethodGet, "/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	r.Group(func(r chi.Router) {
+		if cfg.Auth.Enabled {
+			verifier, err := auth.NewVerifier(cfg.Auth)
+			if err != nil {
+				log.Fatalf("ERROR: failed to initialize JWT verifier: %v", err)
+			}
+			r.Use(handler.AuthMiddleware(verifier, keyService))
+		} else {
+			log.Printf("WARN: authentication is disabled")
+		}
+		r.Use(handler.AuditContextMiddleware)
+
+		r.Group(func(r chi.Router) {
+			r.Use(handler.RequireScope(model.ScopeSubscriptionsRead))
+			r.Get("/subscriptions/{id}", subHandler.Get)
+			r.Get("/subscriptions/{id}/prices", subHandler.Prices)
+			r.Get("/subscriptions", subHandler.List)
+			r.Get("/subscriptions/export", subHandler.Export)
+			r.Get("/subscriptions/{id}/history", auditHandler.History)
+			r.Get("/services", catalogHandler.List)
+			r.Get("/services/{id}", catalogHandler.Get)
+			r.Get("/users/{user_id}/upcoming", subHandler.Upcoming)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(handler.RequireScope(model.ScopeSubscriptionsWrite))
+			r.Post("/subscriptions", subHandler.Create)
+			r.Post("/subscriptions/batch", subHandler.CreateBatch)
+			r.Put("/subscriptions/batch", subHandler.UpdateBatch)
+			r.Post("/subscriptions/batch/delete", subHandler.DeleteBatch)
+			r.Post("/subscriptions/import", subHandler.Import)
+			r.Put("/subscriptions/{id}", subHandler.Update)
+			r.Patch("/subscriptions/{id}", subHandler.Patch)
+			r.Delete("/subscriptions/{id}", subHandler.Delete)
+			r.Post("/subscriptions/{id}/restore", subHandler.Restore)
+			r.Post("/subscriptions/{id}/prices", subHandler.SchedulePrice)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(handler.RequireScope(model.ScopeSummaryRead))
+			r.Get("/subscriptions/summary", subHandler.Summary)
+			r.Get("/analytics/categories", subHandler.Categories)
+		})
+
+		r.Group(func(r chi.Router) {
+			r.Use(handler.DenyAPIKeys)
+			r.Post("/subscriptions/purge", subHandler.Purge)
+			r.Get("/audit", auditHandler.List)
 
-	r.Post("/subscriptions", subHandler.Create)
-	r.Get("/subscriptions/{id}", subHandler.Get)
-	r.Put("/subscriptions/{id}", subHandler.Update)
-	r.Delete("/subscriptions/{id}", subHandler.Delete)
-	r.Get("/subscriptions", subHandler.List)
-	r.Get("/subscriptions/summary", subHandler.Summary)
+			r.Post("/currency-rates", rateHandler.Set)
+			r.Get("/currency-rates", rateHandler.List)
+			r.Delete("/currency-rates/{id}", rateHandler.Delete)
 
-	// 6️⃣ HTTP server
+			r.Post("/api-keys", keyHandler.Create)
+			r.Get("/api-keys", keyHandler.List)
+			r.Delete("/api-keys/{id}", keyHandler.Revoke)
+
+			r.Post("/services", catalogHandler.Create)
+			r.Put("/services/{id}", catalogHandler.Update)
+			r.Delete("/services/{id}", catalogHandler.Delete)
+
+			r.Post("/webhooks", webhookHandler.Create)
+			r.Get("/webhooks", webhookHandler.List)
+			r.Get("/webhooks/{id}", webhookHandler.Get)
+			r.Put("/webhooks/{id}", webhookHandler.Update)
+			r.Delete("/webhooks/{id}", webhookHandler.Delete)
+			r.Get("/webhooks/{id}/deliveries", webhookHandler.Deliveries)
+			r.Post("/webhooks/{id}/deliveries/{delivery_id}/redeliver", webhookHandler.Redeliver)
+		})
+	})
+
+	// 6️⃣ Background workers
+	var workers sync.WaitGroup
+	if cfg.Reminders.Enabled {
+		workers.Go(func() { runEvery(ctx, "reminder scheduler", cfg.Reminders.Interval, reminderService.Dispatch) })
+	} else {
+		log.Printf("WARN: reminders are disabled")
+	}
+	if cfg.Webhooks.Enabled {
+		workers.Go(func() {
+			runEvery(ctx, "webhook delivery worker", cfg.Webhooks.PollInterval, webhookDispatcher.Dispatch)
+		})
+	} else {
+		log.Printf("WARN: webhook delivery is disabled")
+	}
+	if cfg.Events.Enabled {
+		workers.Go(func() { runEvery(ctx, "event relay", cfg.Events.PollInterval, eventRelay.Dispatch) })
+	}
+
+	// 7️⃣ HTTP server
 	server := &http.Server{
 		Addr:    ":" + cfg.App.Port,
 		Handler: r,
@@ -93,6 +283,10 @@ func main() {
 	}()
 
 	waitForShutdown(ctx, server)
+
+	// Stop the workers before the deferred pool close.
+	cancel()
+	workers.Wait()
 }
 
 // waitForShutdown blocks the main goroutine until a termination signal (SIGINT or SIGTERM) is received,
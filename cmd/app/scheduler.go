@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// runEvery calls dispatch right away and then every interval until ctx is cancelled.
+// Failed dispatches are logged and retried on the next tick.
+func runEvery(ctx context.Context, name string, interval time.Duration, dispatch func(context.Context, time.Time) (int, error)) {
+	log.Printf("INFO: %s started, interval %s", name, interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := dispatch(ctx, time.Now()); err != nil && ctx.Err() == nil {
+			log.Printf("ERROR: %s failed: %v", name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("INFO: %s stopped", name)
+			return
+		case <-ticker.C:
+		}
+	}
+}
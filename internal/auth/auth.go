@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+
+	"subscription-service/internal/config"
+	"subscription-service/internal/model"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+var (
+	ErrNoKeys       = errors.New("no HMAC secret or JWKS keys configured")
+	ErrInvalidToken = errors.New("invalid token")
+)
+
+// Claims are the JWT claims understood by the service: the registered claims plus the caller's roles.
+type Claims struct {
+	jwt.RegisteredClaims
+	Roles []string `json:"roles,omitempty"`
+}
+
+// Verifier validates JWT bearer tokens signed with an HMAC secret or with one of the RSA keys of a JWKS file.
+type Verifier struct {
+	secret    []byte
+	rsaKeys   map[string]*rsa.PublicKey
+	adminRole string
+	parser    *jwt.Parser
+}
+
+// NewVerifier builds a verifier from the auth configuration, loading RSA public keys from the JWKS file if set.
+func NewVerifier(cfg config.AuthConfig) (*Verifier, error) {
+	v := &Verifier{
+		rsaKeys:   map[string]*rsa.PublicKey{},
+		adminRole: cfg.AdminRole,
+	}
+
+	var methods []string
+
+	if cfg.HMACSecret != "" {
+		v.secret = []byte(cfg.HMACSecret)
+		methods = append(methods, "HS256", "HS384", "HS512")
+	}
+
+	if cfg.JWKSPath != "" {
+		keys, err := loadJWKS(cfg.JWKSPath)
+		if err != nil {
+			return nil, err
+		}
+		v.rsaKeys = keys
+		methods = append(methods, "RS256", "RS384", "RS512")
+	}
+
+	if len(methods) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	opts := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	v.parser = jwt.NewParser(opts...)
+
+	return v, nil
+}
+
+// Verify checks the token signature and claims and returns the caller identity.
+// Callers without the admin role must have their user ID (a UUID) as the token subject.
+func (v *Verifier) Verify(token string) (model.Identity, error) {
+	var claims Claims
+	if _, err := v.parser.ParseWithClaims(token, &claims, v.key); err != nil {
+		return model.Identity{}, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	id := model.Identity{
+		Subject: claims.Subject,
+		Admin:   v.adminRole != "" && slices.Contains(claims.Roles, v.adminRole),
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	switch {
+	case err == nil:
+		id.UserID = userID
+	case !id.Admin:
+		return model.Identity{}, fmt.Errorf("%w: subject must be a user ID", ErrInvalidToken)
+	}
+
+	return id, nil
+}
+
+// key selects the verification key for the token's algorithm: the HMAC secret or the RSA key named by
+// the "kid" header (the only key when the header is absent and the JWKS holds a single key).
+func (v *Verifier) key(token *jwt.Token) (any, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		return v.secret, nil
+	case *jwt.SigningMethodRSA:
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := v.rsaKeys[kid]; ok {
+			return key, nil
+		}
+		if kid == "" && len(v.rsaKeys) == 1 {
+			for _, key := range v.rsaKeys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key id %q", kid)
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+// jwk is the subset of a JSON Web Key needed to build an RSA public key.
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+// loadJWKS reads the RSA signing keys of a JWKS file, indexed by key ID. Other key types are skipped.
+func loadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read jwks: %w", err)
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, fmt.Errorf("parse jwks: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %q: invalid modulus: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("jwks key %q: invalid exponent: %w", k.Kid, err)
+		}
+
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("jwks %s: %w", path, ErrNoKeys)
+	}
+
+	return keys, nil
+}
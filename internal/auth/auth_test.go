@@ -0,0 +1,137 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"subscription-service/internal/auth"
+	"subscription-service/internal/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const secret = "test-secret"
+
+// sign issues an HS256 token for the subject with the given roles, expiring after ttl.
+func sign(t *testing.T, key string, subject string, roles []string, ttl time.Duration) string {
+	t.Helper()
+
+	claims := auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   subject,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+		},
+		Roles: roles,
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(key))
+	require.NoError(t, err)
+	return token
+}
+
+// TestVerifyHMAC checks signature, expiry and subject validation of HMAC-signed tokens.
+func TestVerifyHMAC(t *testing.T) {
+	verifier, err := auth.NewVerifier(config.AuthConfig{HMACSecret: secret, AdminRole: "admin"})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+
+	t.Run("Valid User Token", func(t *testing.T) {
+		id, err := verifier.Verify(sign(t, secret, userID.String(), nil, time.Hour))
+
+		require.NoError(t, err)
+		assert.Equal(t, userID, id.UserID)
+		assert.False(t, id.Admin)
+	})
+
+	t.Run("Admin Token", func(t *testing.T) {
+		id, err := verifier.Verify(sign(t, secret, "ops", []string{"admin"}, time.Hour))
+
+		require.NoError(t, err)
+		assert.Equal(t, "ops", id.Subject)
+		assert.True(t, id.Admin)
+	})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"Expired", sign(t, secret, userID.String(), nil, -time.Minute)},
+		{"Wrong Secret", sign(t, "other-secret", userID.String(), nil, time.Hour)},
+		{"Non UUID Subject", sign(t, secret, "alice", nil, time.Hour)},
+		{"Malformed", "not-a-jwt"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := verifier.Verify(tt.token)
+			assert.ErrorIs(t, err, auth.ErrInvalidToken)
+		})
+	}
+}
+
+// TestVerifyJWKS checks RSA-signed tokens against a JWKS file, selecting the key by "kid".
+func TestVerifyJWKS(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks := map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "main",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}},
+	}
+	data, err := json.Marshal(jwks)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, data, 0644))
+
+	verifier, err := auth.NewVerifier(config.AuthConfig{JWKSPath: path, Issuer: "issuer"})
+	require.NoError(t, err)
+
+	userID := uuid.New()
+	issue := func(kid, issuer string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.RegisteredClaims{
+			Subject:   userID.String(),
+			Issuer:    issuer,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+
+	id, err := verifier.Verify(issue("main", "issuer"))
+	require.NoError(t, err)
+	assert.Equal(t, userID, id.UserID)
+
+	_, err = verifier.Verify(issue("rotated", "issuer"))
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+	_, err = verifier.Verify(issue("main", "someone-else"))
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+	// HMAC tokens are rejected when only RSA keys are configured.
+	_, err = verifier.Verify(sign(t, secret, userID.String(), nil, time.Hour))
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+// TestNewVerifier checks that a verifier cannot be built without keys.
+func TestNewVerifier(t *testing.T) {
+	_, err := auth.NewVerifier(config.AuthConfig{})
+	assert.ErrorIs(t, err, auth.ErrNoKeys)
+}
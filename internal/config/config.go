@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -11,6 +12,11 @@ type Config struct {
 	App        AppConfig       `mapstructure:"app"`
 	Database   DatabaseConfig  `mapstructure:"database"`
 	Migrations MigrationConfig `mapstructure:"migrations"`
+	Auth       AuthConfig      `mapstructure:"auth"`
+	Reminders  RemindersConfig `mapstructure:"reminders"`
+	Webhooks   WebhooksConfig  `mapstructure:"webhooks"`
+	Events     EventsConfig    `mapstructure:"events"`
+	Tracing    TracingConfig   `mapstructure:"tracing"`
 	Test       TestConfig      `mapstructure:"test"`
 }
 
@@ -18,21 +24,85 @@ type AppConfig struct {
 	Port string `mapstructure:"port"`
 }
 
+// DatabaseConfig configures the connection pool. Units of work run at IsolationLevel ("read committed",
+// "repeatable read" or "serializable") and are retried up to TxRetries times after serialization failures.
 type DatabaseConfig struct {
-	Host     string `mapstructure:"host"`
-	Port     int    `mapstructure:"port"`
-	User     string `mapstructure:"user"`
-	Password string `mapstructure:"password"`
-	Name     string `mapstructure:"name"`
-	SSLMode  string `mapstructure:"sslmode"`
-	MaxConns int32  `mapstructure:"max_conns"`
-	MinConns int32  `mapstructure:"min_conns"`
+	Host           string `mapstructure:"host"`
+	Port           int    `mapstructure:"port"`
+	User           string `mapstructure:"user"`
+	Password       string `mapstructure:"password"`
+	Name           string `mapstructure:"name"`
+	SSLMode        string `mapstructure:"sslmode"`
+	MaxConns       int32  `mapstructure:"max_conns"`
+	MinConns       int32  `mapstructure:"min_conns"`
+	IsolationLevel string `mapstructure:"isolation_level"`
+	TxRetries      int    `mapstructure:"tx_retries"`
 }
 
 type MigrationConfig struct {
 	Path string `mapstructure:"path"`
 }
 
+// AuthConfig configures JWT bearer authentication. Tokens are verified with the HMAC secret (HS256/384/512)
+// and/or the RSA public keys of a local JWKS file (RS256/384/512). Issuer and Audience are checked when set.
+type AuthConfig struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	HMACSecret string `mapstructure:"hmac_secret"`
+	JWKSPath   string `mapstructure:"jwks_path"`
+	Issuer     string `mapstructure:"issuer"`
+	Audience   string `mapstructure:"audience"`
+	AdminRole  string `mapstructure:"admin_role"`
+}
+
+// RemindersConfig configures the background reminder scheduler. Every Interval it emits the reminders of charges
+// and subscription ends due within DaysBefore days; they are POSTed to WebhookURL (with WebhookTimeout) when it is
+// set and written to the log otherwise.
+type RemindersConfig struct {
+	Enabled        bool          `mapstructure:"enabled"`
+	Interval       time.Duration `mapstructure:"interval"`
+	DaysBefore     int           `mapstructure:"days_before"`
+	WebhookURL     string        `mapstructure:"webhook_url"`
+	WebhookTimeout time.Duration `mapstructure:"webhook_timeout"`
+}
+
+// WebhooksConfig configures the webhook delivery worker. Every PollInterval it delivers up to BatchSize events;
+// a failed delivery is retried after RetryBackoff, doubled for every failed attempt up to MaxRetryBackoff,
+// and dead-lettered after MaxAttempts attempts. Requests time out after Timeout.
+type WebhooksConfig struct {
+	Enabled         bool          `mapstructure:"enabled"`
+	PollInterval    time.Duration `mapstructure:"poll_interval"`
+	BatchSize       int           `mapstructure:"batch_size"`
+	Timeout         time.Duration `mapstructure:"timeout"`
+	MaxAttempts     int           `mapstructure:"max_attempts"`
+	RetryBackoff    time.Duration `mapstructure:"retry_backoff"`
+	MaxRetryBackoff time.Duration `mapstructure:"max_retry_backoff"`
+}
+
+// EventsConfig configures the outbox relay to the message broker. Every PollInterval it publishes up to BatchSize
+// events with Publisher: "memory" (kept in process, for development), "nats" (URL nats://host:port, subjects
+// "<Topic>.<event type>") or "kafka" (URL of a Kafka REST Proxy, topic Topic). Publishing times out after Timeout.
+type EventsConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	Publisher    string        `mapstructure:"publisher"`
+	URL          string        `mapstructure:"url"`
+	Topic        string        `mapstructure:"topic"`
+	PollInterval time.Duration `mapstructure:"poll_interval"`
+	BatchSize    int           `mapstructure:"batch_size"`
+	Timeout      time.Duration `mapstructure:"timeout"`
+}
+
+// TracingConfig configures OpenTelemetry tracing. Spans are exported with Exporter: "stdout" (written to the
+// standard output, for development) or "otlp" (OTLP over HTTP to Endpoint, e.g. http://otel-collector:4318; the
+// OTEL_EXPORTER_OTLP_* variables apply when it is empty). SampleRatio of the traces started by the service are
+// recorded; requests carrying a traceparent follow the sampling decision of the caller.
+type TracingConfig struct {
+	Enabled     bool    `mapstructure:"enabled"`
+	Exporter    string  `mapstructure:"exporter"`
+	Endpoint    string  `mapstructure:"endpoint"`
+	ServiceName string  `mapstructure:"service_name"`
+	SampleRatio float64 `mapstructure:"sample_ratio"`
+}
+
 type TestConfig struct {
 	DBHost                string `mapstructure:"db_host"`
 	MigrationsPath        string `mapstructure:"migrations_path"`
@@ -56,6 +126,43 @@ func Load(path string) (*Config, error) {
 	_ = v.BindEnv("database.password", "DB_PASSWORD")
 	_ = v.BindEnv("database.name", "DB_NAME")
 	_ = v.BindEnv("database.sslmode", "DB_SSLMODE")
+	_ = v.BindEnv("auth.enabled", "AUTH_ENABLED")
+	_ = v.BindEnv("auth.hmac_secret", "JWT_HMAC_SECRET")
+	_ = v.BindEnv("auth.jwks_path", "JWT_JWKS_PATH")
+	_ = v.BindEnv("reminders.enabled", "REMINDERS_ENABLED")
+	_ = v.BindEnv("reminders.webhook_url", "REMINDERS_WEBHOOK_URL")
+	_ = v.BindEnv("webhooks.enabled", "WEBHOOKS_ENABLED")
+	_ = v.BindEnv("events.enabled", "EVENTS_ENABLED")
+	_ = v.BindEnv("events.publisher", "EVENTS_PUBLISHER")
+	_ = v.BindEnv("events.url", "EVENTS_URL")
+	_ = v.BindEnv("tracing.enabled", "TRACING_ENABLED")
+	_ = v.BindEnv("tracing.exporter", "TRACING_EXPORTER")
+	_ = v.BindEnv("tracing.endpoint", "TRACING_ENDPOINT")
+
+	v.SetDefault("database.isolation_level", "read committed")
+	v.SetDefault("database.tx_retries", 3)
+	v.SetDefault("auth.admin_role", "admin")
+	v.SetDefault("reminders.enabled", true)
+	v.SetDefault("reminders.interval", time.Hour)
+	v.SetDefault("reminders.days_before", 3)
+	v.SetDefault("reminders.webhook_timeout", 10*time.Second)
+	v.SetDefault("webhooks.enabled", true)
+	v.SetDefault("webhooks.poll_interval", 5*time.Second)
+	v.SetDefault("webhooks.batch_size", 100)
+	v.SetDefault("webhooks.timeout", 10*time.Second)
+	v.SetDefault("webhooks.max_attempts", 8)
+	v.SetDefault("webhooks.retry_backoff", 30*time.Second)
+	v.SetDefault("webhooks.max_retry_backoff", time.Hour)
+	v.SetDefault("events.enabled", false)
+	v.SetDefault("events.publisher", "memory")
+	v.SetDefault("events.topic", "subscriptions")
+	v.SetDefault("events.poll_interval", time.Second)
+	v.SetDefault("events.batch_size", 100)
+	v.SetDefault("events.timeout", 5*time.Second)
+	v.SetDefault("tracing.enabled", false)
+	v.SetDefault("tracing.exporter", "stdout")
+	v.SetDefault("tracing.service_name", "subscription-service")
+	v.SetDefault("tracing.sample_ratio", 1.0)
 
 	if err := v.ReadInConfig(); err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
@@ -77,5 +184,57 @@ func (c *Config) Validate() error {
 	if c.Database.Host == "" {
 		return fmt.Errorf("DB_HOST is required")
 	}
+	switch c.Database.IsolationLevel {
+	case "", "read committed", "repeatable read", "serializable":
+	default:
+		return fmt.Errorf("database.isolation_level must be one of read committed, repeatable read, serializable")
+	}
+	if c.Database.TxRetries < 0 {
+		return fmt.Errorf("database.tx_retries must be >= 0")
+	}
+	if c.Auth.Enabled && c.Auth.HMACSecret == "" && c.Auth.JWKSPath == "" {
+		return fmt.Errorf("JWT_HMAC_SECRET or JWT_JWKS_PATH is required when auth is enabled")
+	}
+	if c.Reminders.Enabled && c.Reminders.Interval <= 0 {
+		return fmt.Errorf("reminders.interval must be positive")
+	}
+	if c.Reminders.Enabled && c.Reminders.DaysBefore < 0 {
+		return fmt.Errorf("reminders.days_before must be >= 0")
+	}
+	if c.Webhooks.Enabled {
+		if c.Webhooks.PollInterval <= 0 || c.Webhooks.Timeout <= 0 {
+			return fmt.Errorf("webhooks.poll_interval and webhooks.timeout must be positive")
+		}
+		if c.Webhooks.BatchSize < 1 || c.Webhooks.MaxAttempts < 1 {
+			return fmt.Errorf("webhooks.batch_size and webhooks.max_attempts must be at least 1")
+		}
+	}
+	if c.Events.Enabled {
+		switch c.Events.Publisher {
+		case "memory":
+		case "nats", "kafka":
+			if c.Events.URL == "" || c.Events.Topic == "" {
+				return fmt.Errorf("EVENTS_URL and events.topic are required for the %s publisher", c.Events.Publisher)
+			}
+		default:
+			return fmt.Errorf("events.publisher must be one of memory, nats, kafka")
+		}
+		if c.Events.PollInterval <= 0 || c.Events.Timeout <= 0 {
+			return fmt.Errorf("events.poll_interval and events.timeout must be positive")
+		}
+		if c.Events.BatchSize < 1 {
+			return fmt.Errorf("events.batch_size must be at least 1")
+		}
+	}
+	if c.Tracing.Enabled {
+		switch c.Tracing.Exporter {
+		case "stdout", "otlp":
+		default:
+			return fmt.Errorf("tracing.exporter must be one of stdout, otlp")
+		}
+		if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+			return fmt.Errorf("tracing.sample_ratio must be between 0 and 1")
+		}
+	}
 	return nil
 }
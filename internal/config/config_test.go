@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -39,6 +40,34 @@ database:
 		assert.Equal(t, "8090", cfg.App.Port)
 		assert.Equal(t, "localhost", cfg.Database.Host)
 		assert.Equal(t, 5432, cfg.Database.Port)
+
+		// Transaction defaults
+		assert.Equal(t, "read committed", cfg.Database.IsolationLevel)
+		assert.Equal(t, 3, cfg.Database.TxRetries)
+
+		// Reminder defaults
+		assert.True(t, cfg.Reminders.Enabled)
+		assert.Equal(t, time.Hour, cfg.Reminders.Interval)
+		assert.Equal(t, 3, cfg.Reminders.DaysBefore)
+		assert.Equal(t, 10*time.Second, cfg.Reminders.WebhookTimeout)
+
+		// Webhook delivery defaults
+		assert.True(t, cfg.Webhooks.Enabled)
+		assert.Equal(t, 8, cfg.Webhooks.MaxAttempts)
+		assert.Equal(t, 30*time.Second, cfg.Webhooks.RetryBackoff)
+		assert.Equal(t, time.Hour, cfg.Webhooks.MaxRetryBackoff)
+
+		// Event relay defaults
+		assert.False(t, cfg.Events.Enabled)
+		assert.Equal(t, "memory", cfg.Events.Publisher)
+		assert.Equal(t, "subscriptions", cfg.Events.Topic)
+		assert.Equal(t, time.Second, cfg.Events.PollInterval)
+
+		// Tracing defaults
+		assert.False(t, cfg.Tracing.Enabled)
+		assert.Equal(t, "stdout", cfg.Tracing.Exporter)
+		assert.Equal(t, "subscription-service", cfg.Tracing.ServiceName)
+		assert.Equal(t, 1.0, cfg.Tracing.SampleRatio)
 	})
 
 	t.Run("Environment variables override file", func(t *testing.T) {
@@ -101,6 +130,102 @@ func TestConfig_Validate(t *testing.T) {
 			wantErr: true,
 			msg:     "DB_HOST is required",
 		},
+		{
+			name: "Auth without keys",
+			cfg: &Config{
+				Database: DatabaseConfig{
+					Host:     "localhost",
+					Password: "pass",
+				},
+				Auth: AuthConfig{Enabled: true},
+			},
+			wantErr: true,
+			msg:     "JWT_HMAC_SECRET or JWT_JWKS_PATH is required",
+		},
+		{
+			name: "Unknown isolation level",
+			cfg: &Config{
+				Database: DatabaseConfig{
+					Host:           "localhost",
+					Password:       "pass",
+					IsolationLevel: "snapshot",
+				},
+			},
+			wantErr: true,
+			msg:     "database.isolation_level must be one of",
+		},
+		{
+			name: "Reminders without interval",
+			cfg: &Config{
+				Database: DatabaseConfig{
+					Host:     "localhost",
+					Password: "pass",
+				},
+				Reminders: RemindersConfig{Enabled: true, DaysBefore: 3},
+			},
+			wantErr: true,
+			msg:     "reminders.interval must be positive",
+		},
+		{
+			name: "Webhooks without attempts",
+			cfg: &Config{
+				Database: DatabaseConfig{
+					Host:     "localhost",
+					Password: "pass",
+				},
+				Webhooks: WebhooksConfig{Enabled: true, PollInterval: time.Second, Timeout: time.Second, BatchSize: 10},
+			},
+			wantErr: true,
+			msg:     "webhooks.batch_size and webhooks.max_attempts must be at least 1",
+		},
+		{
+			name: "NATS publisher without URL",
+			cfg: &Config{
+				Database: DatabaseConfig{
+					Host:     "localhost",
+					Password: "pass",
+				},
+				Events: EventsConfig{Enabled: true, Publisher: "nats", Topic: "subscriptions", PollInterval: time.Second, Timeout: time.Second, BatchSize: 10},
+			},
+			wantErr: true,
+			msg:     "EVENTS_URL and events.topic are required for the nats publisher",
+		},
+		{
+			name: "Unknown publisher",
+			cfg: &Config{
+				Database: DatabaseConfig{
+					Host:     "localhost",
+					Password: "pass",
+				},
+				Events: EventsConfig{Enabled: true, Publisher: "rabbitmq"},
+			},
+			wantErr: true,
+			msg:     "events.publisher must be one of memory, nats, kafka",
+		},
+		{
+			name: "Unknown trace exporter",
+			cfg: &Config{
+				Database: DatabaseConfig{
+					Host:     "localhost",
+					Password: "pass",
+				},
+				Tracing: TracingConfig{Enabled: true, Exporter: "jaeger", SampleRatio: 1},
+			},
+			wantErr: true,
+			msg:     "tracing.exporter must be one of stdout, otlp",
+		},
+		{
+			name: "Sample ratio out of range",
+			cfg: &Config{
+				Database: DatabaseConfig{
+					Host:     "localhost",
+					Password: "pass",
+				},
+				Tracing: TracingConfig{Enabled: true, Exporter: "otlp", SampleRatio: 1.5},
+			},
+			wantErr: true,
+			msg:     "tracing.sample_ratio must be between 0 and 1",
+		},
 	}
 
 	for _, tt := range tests {
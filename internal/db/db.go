@@ -8,6 +8,8 @@ import (
 	"subscription-service/internal/config"
 	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/multitracer"
 	"github.com/jackc/pgx/v5/pgxpool"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"github.com/pressly/goose/v3"
@@ -19,8 +21,8 @@ type Database struct {
 }
 
 // Connect establishes a connection pool to PostgreSQL using environment variables
-// and automatically executes pending migrations.
-func Connect(ctx context.Context, cfg *config.Config) (*Database, error) {
+// and automatically executes pending migrations. The tracers, if any, observe the queries run on the pool.
+func Connect(ctx context.Context, cfg *config.Config, tracers ...pgx.QueryTracer) (*Database, error) {
 
 	dsn := fmt.Sprintf(
 		"postgres://%s:%s@%s:%d/%s?sslmode=%s",
@@ -41,6 +43,13 @@ func Connect(ctx context.Context, cfg *config.Config) (*Database, error) {
 	pgcfg.MinConns = cfg.Database.MinConns
 	pgcfg.MaxConnLifetime = time.Hour
 
+	switch {
+	case len(tracers) == 1:
+		pgcfg.ConnConfig.Tracer = tracers[0]
+	case len(tracers) > 1:
+		pgcfg.ConnConfig.Tracer = multitracer.New(tracers...)
+	}
+
 	pool, err := pgxpool.NewWithConfig(ctx, pgcfg)
 	if err != nil {
 		return nil, fmt.Errorf("create pgx pool: %w", err)
@@ -0,0 +1,52 @@
+package events
+
+import (
+	"context"
+	"sync"
+
+	"subscription-service/internal/model"
+)
+
+// EventPublisher publishes subscription lifecycle events to a message broker. Publish returns once the broker
+// has accepted the event; an event may be published more than once, so consumers de-duplicate by its ID.
+type EventPublisher interface {
+	Publish(ctx context.Context, event *model.Event) error
+	Close() error
+}
+
+// MemoryPublisher keeps published events in memory. It is meant for tests and local development.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []*model.Event
+}
+
+// NewMemoryPublisher creates an empty in-memory publisher.
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+// Publish stores the event.
+func (p *MemoryPublisher) Publish(ctx context.Context, event *model.Event) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.events = append(p.events, event)
+	return nil
+}
+
+// Events returns the events published so far, in publishing order.
+func (p *MemoryPublisher) Events() []*model.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return append([]*model.Event(nil), p.events...)
+}
+
+// Close does nothing; published events stay available.
+func (p *MemoryPublisher) Close() error {
+	return nil
+}
@@ -0,0 +1,217 @@
+package events_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"subscription-service/internal/events"
+	"subscription-service/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testEvent() *model.Event {
+	return &model.Event{
+		ID:             42,
+		Type:           model.EventTypeCreated,
+		SubscriptionID: uuid.New(),
+		CreatedAt:      time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+		Data:           json.RawMessage(`{"subscription":{"service_name":"Netflix"}}`),
+	}
+}
+
+func TestMemoryPublisher(t *testing.T) {
+	publisher := events.NewMemoryPublisher()
+	first, second := testEvent(), testEvent()
+
+	require.NoError(t, publisher.Publish(context.Background(), first))
+	require.NoError(t, publisher.Publish(context.Background(), second))
+	assert.Equal(t, []*model.Event{first, second}, publisher.Events())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, publisher.Publish(ctx, testEvent()), context.Canceled)
+	assert.Len(t, publisher.Events(), 2)
+}
+
+// natsMessage is a message received by fakeNATS.
+type natsMessage struct {
+	Subject string
+	Header  string
+	Body    []byte
+}
+
+// fakeNATS is a minimal NATS server accepting one client connection at a time. It answers every publish
+// with reply, pinging the client first.
+func fakeNATS(t *testing.T, reply string) (string, <-chan natsMessage) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	messages := make(chan natsMessage, 10)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			serveNATS(conn, reply, messages)
+		}
+	}()
+
+	return "nats://token@" + listener.Addr().String(), messages
+}
+
+func serveNATS(conn net.Conn, reply string, messages chan<- natsMessage) {
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+
+	_, _ = fmt.Fprint(conn, "INFO {\"server_id\":\"test\",\"headers\":true}\r\n")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "CONNECT":
+			if !strings.Contains(line, `"auth_token":"token"`) || !strings.Contains(line, `"verbose":true`) {
+				_, _ = fmt.Fprint(conn, "-ERR 'Authorization Violation'\r\n")
+				return
+			}
+			_, _ = fmt.Fprint(conn, "+OK\r\n")
+		case "HPUB":
+			headerLen, _ := strconv.Atoi(fields[2])
+			totalLen, _ := strconv.Atoi(fields[3])
+			payload := make([]byte, totalLen+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			messages <- natsMessage{
+				Subject: fields[1],
+				Header:  string(payload[:headerLen]),
+				Body:    payload[headerLen:totalLen],
+			}
+
+			_, _ = fmt.Fprint(conn, "PING\r\n")
+			if pong, err := r.ReadString('\n'); err != nil || pong != "PONG\r\n" {
+				return
+			}
+			_, _ = fmt.Fprint(conn, reply+"\r\n")
+		}
+	}
+}
+
+func TestNATSPublisher(t *testing.T) {
+	t.Run("Acknowledged", func(t *testing.T) {
+		url, messages := fakeNATS(t, "+OK")
+		publisher, err := events.NewNATSPublisher(url, "subscriptions", time.Second)
+		require.NoError(t, err)
+		defer func() { _ = publisher.Close() }()
+
+		event := testEvent()
+		for range 2 {
+			require.NoError(t, publisher.Publish(context.Background(), event))
+
+			msg := <-messages
+			assert.Equal(t, "subscriptions.subscription.created", msg.Subject)
+			assert.Contains(t, msg.Header, "Nats-Msg-Id: 42\r\n")
+
+			var received model.Event
+			require.NoError(t, json.Unmarshal(msg.Body, &received))
+			assert.Equal(t, event.ID, received.ID)
+			assert.Equal(t, event.SubscriptionID, received.SubscriptionID)
+		}
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		url, messages := fakeNATS(t, "-ERR 'Permissions Violation for Publish'")
+		publisher, err := events.NewNATSPublisher(url, "subscriptions", time.Second)
+		require.NoError(t, err)
+		defer func() { _ = publisher.Close() }()
+
+		err = publisher.Publish(context.Background(), testEvent())
+		assert.EqualError(t, err, "nats publish: Permissions Violation for Publish")
+		<-messages
+
+		// The publisher reconnects after a failure.
+		err = publisher.Publish(context.Background(), testEvent())
+		assert.Error(t, err)
+		<-messages
+	})
+
+	t.Run("Invalid URL", func(t *testing.T) {
+		_, err := events.NewNATSPublisher("http://localhost:4222", "subscriptions", time.Second)
+		assert.Error(t, err)
+	})
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	event := testEvent()
+
+	t.Run("Produced", func(t *testing.T) {
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/topics/subscriptions", r.URL.Path)
+			assert.Equal(t, "application/vnd.kafka.json.v2+json", r.Header.Get("Content-Type"))
+
+			var req struct {
+				Records []struct {
+					Key   string      `json:"key"`
+					Value model.Event `json:"value"`
+				} `json:"records"`
+			}
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			require.Len(t, req.Records, 1)
+			assert.Equal(t, event.SubscriptionID.String(), req.Records[0].Key)
+			assert.Equal(t, event.ID, req.Records[0].Value.ID)
+
+			_, _ = fmt.Fprint(w, `{"offsets":[{"partition":0,"offset":7,"error_code":null,"error":null}]}`)
+		}))
+		defer proxy.Close()
+
+		publisher, err := events.NewKafkaPublisher(proxy.URL+"/", "subscriptions", time.Second)
+		require.NoError(t, err)
+		assert.NoError(t, publisher.Publish(context.Background(), event))
+	})
+
+	t.Run("Record Error", func(t *testing.T) {
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = fmt.Fprint(w, `{"offsets":[{"partition":null,"offset":null,"error_code":50003,"error":"Kafka error"}]}`)
+		}))
+		defer proxy.Close()
+
+		publisher, err := events.NewKafkaPublisher(proxy.URL, "subscriptions", time.Second)
+		require.NoError(t, err)
+		assert.EqualError(t, publisher.Publish(context.Background(), event), "kafka publish: error 50003: Kafka error")
+	})
+
+	t.Run("Unknown Topic", func(t *testing.T) {
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"error_code":40401,"message":"Topic not found."}`)
+		}))
+		defer proxy.Close()
+
+		publisher, err := events.NewKafkaPublisher(proxy.URL, "subscriptions", time.Second)
+		require.NoError(t, err)
+		assert.ErrorContains(t, publisher.Publish(context.Background(), event), "status 404")
+	})
+}
@@ -0,0 +1,106 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"subscription-service/internal/model"
+)
+
+// Content types of the Kafka REST Proxy v2 API.
+const (
+	kafkaJSONContentType = "application/vnd.kafka.json.v2+json"
+	kafkaAccept          = "application/vnd.kafka.v2+json"
+)
+
+type kafkaRecord struct {
+	Key   string       `json:"key"`
+	Value *model.Event `json:"value"`
+}
+
+type kafkaProduceRequest struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaProduceResponse struct {
+	Offsets []struct {
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+type kafkaPublisher struct {
+	endpoint string
+	client   *http.Client
+}
+
+// NewKafkaPublisher creates a publisher producing events to a Kafka topic through the REST Proxy v2 API at
+// baseURL, as served by the Confluent REST Proxy and Redpanda. Records are keyed by subscription ID, so the
+// events of a subscription land in the same partition in order. Requests time out after timeout.
+func NewKafkaPublisher(baseURL, topic string, timeout time.Duration) (EventPublisher, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("invalid Kafka REST Proxy url %q: expected http(s)://host[:port]", baseURL)
+	}
+
+	return &kafkaPublisher{
+		endpoint: strings.TrimRight(baseURL, "/") + "/topics/" + url.PathEscape(topic),
+		client:   &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Publish produces the event and checks that the proxy stored it.
+func (p *kafkaPublisher) Publish(ctx context.Context, event *model.Event) error {
+	body, err := json.Marshal(kafkaProduceRequest{
+		Records: []kafkaRecord{{Key: event.SubscriptionID.String(), Value: event}},
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaJSONContentType)
+	req.Header.Set("Accept", kafkaAccept)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("kafka publish: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("kafka publish: proxy responded with status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	var produced kafkaProduceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&produced); err != nil {
+		return fmt.Errorf("kafka publish: invalid proxy response: %w", err)
+	}
+	if len(produced.Offsets) != 1 {
+		return fmt.Errorf("kafka publish: expected 1 offset, got %d", len(produced.Offsets))
+	}
+	if o := produced.Offsets[0]; o.ErrorCode != nil {
+		return fmt.Errorf("kafka publish: error %d: %s", *o.ErrorCode, o.Error)
+	}
+
+	return nil
+}
+
+// Close releases idle connections to the proxy.
+func (p *kafkaPublisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
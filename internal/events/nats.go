@@ -0,0 +1,214 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"subscription-service/internal/model"
+)
+
+// natsDefaultPort is the client port of a NATS server when the URL does not name one.
+const natsDefaultPort = "4222"
+
+type natsPublisher struct {
+	addr    string
+	user    *url.Userinfo
+	prefix  string
+	timeout time.Duration
+
+	mu      sync.Mutex
+	conn    net.Conn
+	reader  *bufio.Reader
+	headers bool
+}
+
+// NewNATSPublisher creates a publisher speaking the NATS client protocol to the server at rawURL
+// (nats://[user:password@|token@]host[:port]). An event is published to the subject "<prefix>.<event type>"
+// and acknowledged by the server in verbose mode. When the server supports headers, the event ID is sent as
+// Nats-Msg-Id so that JetStream streams de-duplicate republished events. The connection is opened on the first
+// publish and reopened after a failure; every operation times out after timeout.
+func NewNATSPublisher(rawURL, prefix string, timeout time.Duration) (EventPublisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS url: %w", err)
+	}
+	if u.Scheme != "nats" || u.Hostname() == "" {
+		return nil, fmt.Errorf("invalid NATS url %q: expected nats://host[:port]", rawURL)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = natsDefaultPort
+	}
+
+	return &natsPublisher{
+		addr:    net.JoinHostPort(u.Hostname(), port),
+		user:    u.User,
+		prefix:  prefix,
+		timeout: timeout,
+	}, nil
+}
+
+// Publish sends the event and waits for the server to acknowledge it.
+func (p *natsPublisher) Publish(ctx context.Context, event *model.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.publish(ctx, event, body); err != nil {
+		p.disconnect()
+		return fmt.Errorf("nats publish: %w", err)
+	}
+	return nil
+}
+
+func (p *natsPublisher) publish(ctx context.Context, event *model.Event, body []byte) error {
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := p.conn.SetDeadline(p.deadline(ctx)); err != nil {
+		return err
+	}
+
+	subject := p.prefix + "." + event.Type
+
+	var msg []byte
+	if p.headers {
+		header := "NATS/1.0\r\nNats-Msg-Id: " + strconv.FormatInt(event.ID, 10) + "\r\n\r\n"
+		msg = fmt.Appendf(nil, "HPUB %s %d %d\r\n%s", subject, len(header), len(header)+len(body), header)
+	} else {
+		msg = fmt.Appendf(nil, "PUB %s %d\r\n", subject, len(body))
+	}
+	msg = append(append(msg, body...), "\r\n"...)
+
+	if _, err := p.conn.Write(msg); err != nil {
+		return err
+	}
+	return p.awaitOK()
+}
+
+// connect opens the connection, reads the server INFO and authenticates in verbose mode.
+func (p *natsPublisher) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: p.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return err
+	}
+	p.conn, p.reader = conn, bufio.NewReader(conn)
+
+	if err := conn.SetDeadline(p.deadline(ctx)); err != nil {
+		return err
+	}
+
+	line, err := p.readLine()
+	if err != nil {
+		return err
+	}
+	infoJSON, ok := strings.CutPrefix(line, "INFO ")
+	if !ok {
+		return fmt.Errorf("unexpected greeting %q", line)
+	}
+
+	var info struct {
+		Headers bool `json:"headers"`
+	}
+	if err := json.Unmarshal([]byte(infoJSON), &info); err != nil {
+		return fmt.Errorf("invalid server info: %w", err)
+	}
+	p.headers = info.Headers
+
+	options := map[string]any{
+		"verbose":  true,
+		"pedantic": false,
+		"headers":  info.Headers,
+		"name":     "subscription-service",
+		"lang":     "go",
+		"version":  "1.0.0",
+	}
+	if p.user != nil {
+		if password, ok := p.user.Password(); ok {
+			options["user"], options["pass"] = p.user.Username(), password
+		} else {
+			options["auth_token"] = p.user.Username()
+		}
+	}
+
+	connect, err := json.Marshal(options)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\n", connect); err != nil {
+		return err
+	}
+	return p.awaitOK()
+}
+
+// awaitOK reads server messages until the acknowledgement of the last command, answering keep-alive pings.
+func (p *natsPublisher) awaitOK() error {
+	for {
+		line, err := p.readLine()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case line == "+OK":
+			return nil
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New(strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")), "'"))
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (p *natsPublisher) readLine() (string, error) {
+	line, err := p.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// deadline returns the deadline of an operation: timeout from now, or earlier if ctx ends before.
+func (p *natsPublisher) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(p.timeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		return d
+	}
+	return deadline
+}
+
+func (p *natsPublisher) disconnect() {
+	if p.conn != nil {
+		_ = p.conn.Close()
+		p.conn, p.reader = nil, nil
+	}
+}
+
+// Close closes the connection to the server.
+func (p *natsPublisher) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.disconnect()
+	return nil
+}
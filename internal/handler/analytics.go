@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"net/http"
+
+	"subscription-service/internal/model"
+)
+
+// Categories godoc
+// @Summary Spend per category
+// @Description Calculate the spend per service catalog category for a period and the share of each category in the total spend (in percent). Subscriptions without a categorised catalog entry are reported under a null category
+// @Tags analytics
+// @Produce json
+// @Param from query string true "Start period" example("01-2026")
+// @Param to query string true "End period" example("12-2026")
+// @Param user_id query string false "User ID" format(uuid)
+// @Param currency query string false "Target ISO 4217 currency (required if subscriptions use several currencies)" example("RUB")
+// @Param amortize query bool false "Spread quarterly, yearly and weekly prices evenly over months"
+// @Success 200 {object} model.CategoryAnalyticsResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /analytics/categories [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) Categories(w http.ResponseWriter, r *http.Request) {
+	query, err := parseCostQuery(r.URL.Query())
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	analytics, err := h.service.CategorySpend(r.Context(), query)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, model.ToCategoryAnalyticsResponse(analytics))
+}
@@ -0,0 +1,114 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/service"
+)
+
+// APIKeyHandler manages HTTP communication for API key endpoints.
+type APIKeyHandler struct {
+	service service.APIKeyService
+}
+
+// NewAPIKeyHandler initializes a new handler with the provided API key service.
+func NewAPIKeyHandler(s service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: s}
+}
+
+// Create godoc
+// @Summary Mint API key
+// @Description Create an API key with the given scopes. The secret is only returned in this response.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param key body model.APIKeyRequest true "API key data"
+// @Success 201 {object} model.CreatedAPIKeyResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Router /api-keys [post]
+// @Security BearerAuth
+func (h *APIKeyHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := model.Validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	key, secret, err := h.service.Create(r.Context(), req.Name, req.Scopes)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, model.CreatedAPIKeyResponse{
+		APIKeyResponse: model.ToAPIKeyResponse(key),
+		Key:            secret,
+	})
+}
+
+// List godoc
+// @Summary List API keys
+// @Description List all API keys, including revoked ones, without their secrets
+// @Tags api-keys
+// @Produce json
+// @Success 200 {array} model.APIKeyResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Router /api-keys [get]
+// @Security BearerAuth
+func (h *APIKeyHandler) List(w http.ResponseWriter, r *http.Request) {
+	keys, err := h.service.List(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := make([]model.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		resp = append(resp, model.ToAPIKeyResponse(key))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Revoke godoc
+// @Summary Revoke API key
+// @Description Revoke an API key by ID; requests with it are rejected afterwards
+// @Tags api-keys
+// @Param id path string true "API key ID" format(uuid)
+// @Success 204
+// @Failure 400 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Router /api-keys/{id} [delete]
+// @Security BearerAuth
+func (h *APIKeyHandler) Revoke(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err := h.service.Revoke(r.Context(), id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,142 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/service"
+)
+
+// AuditHandler manages HTTP communication for the subscription audit log endpoints.
+type AuditHandler struct {
+	service service.AuditService
+}
+
+// NewAuditHandler initializes a new handler with the provided audit service.
+func NewAuditHandler(s service.AuditService) *AuditHandler {
+	return &AuditHandler{service: s}
+}
+
+// History godoc
+// @Summary Subscription history
+// @Description List the changes of a subscription with before/after snapshots, newest first
+// @Tags audit
+// @Produce json
+// @Param id path string true "Subscription ID" format(uuid) example("550e8400-e29b-41d4-a716-446655440000")
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} model.SubscriptionEventResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /subscriptions/{id}/history [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *AuditHandler) History(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+
+	events, err := h.service.History(r.Context(), id, limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeEvents(w, events)
+}
+
+// List godoc
+// @Summary Audit log
+// @Description List subscription changes filtered by subscription, user, actor, action and time range, newest first
+// @Tags audit
+// @Produce json
+// @Param subscription_id query string false "Subscription ID" format(uuid)
+// @Param user_id query string false "User ID" format(uuid)
+// @Param actor query string false "Actor who made the change"
+// @Param action query string false "Change kind" Enums(created, updated, deleted, restored, purged)
+// @Param from query string false "Start of the range, inclusive (RFC 3339)" example("2025-01-01T00:00:00Z")
+// @Param to query string false "End of the range, exclusive (RFC 3339)" example("2025-02-01T00:00:00Z")
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} model.SubscriptionEventResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /audit [get]
+// @Security BearerAuth
+func (h *AuditHandler) List(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+
+	var q model.AuditQuery
+
+	for name, target := range map[string]**uuid.UUID{
+		"subscription_id": &q.SubscriptionID,
+		"user_id":         &q.UserID,
+	} {
+		if v := params.Get(name); v != "" {
+			parsed, err := uuid.Parse(v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid "+name)
+				return
+			}
+			*target = &parsed
+		}
+	}
+
+	if actor := params.Get("actor"); actor != "" {
+		q.Actor = &actor
+	}
+
+	if action := params.Get("action"); action != "" {
+		a := model.EventAction(action)
+		q.Action = &a
+	}
+
+	for name, target := range map[string]**time.Time{
+		"from": &q.From,
+		"to":   &q.To,
+	} {
+		if v := params.Get(name); v != "" {
+			parsed, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid "+name+": expected RFC 3339 timestamp")
+				return
+			}
+			*target = &parsed
+		}
+	}
+
+	q.Limit, _ = strconv.Atoi(params.Get("limit"))
+	q.Offset, _ = strconv.Atoi(params.Get("offset"))
+
+	events, err := h.service.List(r.Context(), q)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeEvents(w, events)
+}
+
+// writeEvents sends audit events as a JSON array.
+func writeEvents(w http.ResponseWriter, events []*model.SubscriptionEvent) {
+	resp := make([]model.SubscriptionEventResponse, 0, len(events))
+	for _, e := range events {
+		resp = append(resp, model.ToEventResponse(e))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,379 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+
+	"subscription-service/internal/model"
+)
+
+// batchItemResult reports the outcome of a single batch item. Status is the status the item would have
+// received as a single request; the subscription is returned for successful creates and updates.
+type batchItemResult struct {
+	Index        int                         `json:"index" extensions:"x-order=1"`
+	Status       int                         `json:"status" extensions:"x-order=2"`
+	ID           *uuid.UUID                  `json:"id,omitempty" extensions:"x-order=3"`
+	Version      *int                        `json:"version,omitempty" extensions:"x-order=4"`
+	Subscription *model.SubscriptionResponse `json:"subscription,omitempty" extensions:"x-order=5"`
+	Error        *problemResponse            `json:"error,omitempty" extensions:"x-order=6"`
+}
+
+// batchResponse lists the outcome of every item of a batch request in request order.
+type batchResponse struct {
+	Results []batchItemResult `json:"results"`
+}
+
+// batch collects the outcome of the items of a batch request. Items rejected by the handler are not passed to
+// the service; indexes maps the position of each item passed to the service back to its request index.
+type batch struct {
+	atomic  bool
+	field   string
+	results []batchItemResult
+	indexes []int
+}
+
+// newBatch prepares the results of a batch of n items. field names the request array the items come from.
+func newBatch(atomic bool, field string, n int) *batch {
+	b := &batch{atomic: atomic, field: field, results: make([]batchItemResult, n)}
+	for i := range b.results {
+		b.results[i].Index = i
+	}
+	return b
+}
+
+// accept marks item i as passed to the service.
+func (b *batch) accept(i int) {
+	b.indexes = append(b.indexes, i)
+}
+
+// reject records the problem of item i.
+func (b *batch) reject(i int, p problemResponse) {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+	b.results[i].Status = p.Status
+	b.results[i].Error = &p
+}
+
+// pending reports whether the service has to be called: some items were accepted and, in atomic mode,
+// none was rejected.
+func (b *batch) pending() bool {
+	return len(b.indexes) > 0 && !(b.atomic && b.failed() > 0)
+}
+
+// apply records the per-item errors returned by the service for the accepted items. done fills in the result
+// of the j-th accepted item that succeeded.
+func (b *batch) apply(errs []error, done func(j int, result *batchItemResult)) {
+	for j, err := range errs {
+		i := b.indexes[j]
+		if err != nil {
+			b.reject(i, serviceProblem(err))
+			continue
+		}
+		done(j, &b.results[i])
+	}
+}
+
+// failed returns the number of rejected items.
+func (b *batch) failed() int {
+	n := 0
+	for _, result := range b.results {
+		if result.Error != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// write sends the outcome of the batch. A partial batch always answers 207 Multi-Status with every item's
+// result. An atomic batch answers status when it succeeded; otherwise nothing was written and a single
+// problem with the status of the first failed item lists the failures by item path (e.g. items[3].price).
+func (b *batch) write(w http.ResponseWriter, status int) {
+	if !b.atomic {
+		writeJSON(w, http.StatusMultiStatus, batchResponse{Results: b.results})
+		return
+	}
+
+	failed := b.failed()
+	if failed == 0 {
+		writeJSON(w, status, batchResponse{Results: b.results})
+		return
+	}
+
+	p := problemResponse{Detail: fmt.Sprintf("batch rejected: %d of %d items failed", failed, len(b.results))}
+	for _, result := range b.results {
+		if result.Error == nil {
+			continue
+		}
+		if p.Status == 0 {
+			p.Status = result.Status
+		}
+
+		path := b.field + "[" + strconv.Itoa(result.Index) + "]"
+		if len(result.Error.Errors) == 0 {
+			p.Errors = append(p.Errors, fieldProblem{Field: path, Message: result.Error.Detail})
+		}
+		for _, fe := range result.Error.Errors {
+			p.Errors = append(p.Errors, fieldProblem{Field: path + "." + fe.Field, Message: fe.Message})
+		}
+	}
+
+	writeProblem(w, p)
+}
+
+// parseAtomic reads the atomic query parameter, which defaults to true.
+func parseAtomic(r *http.Request) (bool, error) {
+	value := r.URL.Query().Get("atomic")
+	if value == "" {
+		return true, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// parseBatchItem validates a subscription of a batch request and converts it into the domain model.
+func parseBatchItem(req model.CreateSubscriptionRequest) (*model.Subscription, *problemResponse) {
+	if err := model.Validate.Struct(req); err != nil {
+		p := validationProblem(err)
+		return nil, &p
+	}
+
+	sub, err := model.ToDomain(req)
+	if err != nil {
+		return nil, &problemResponse{Status: http.StatusBadRequest, Detail: "invalid date format"}
+	}
+
+	return sub, nil
+}
+
+// CreateBatch godoc
+// @Summary Create subscriptions in bulk
+// @Description Create up to 100 subscriptions at once. With atomic=true (default) either all subscriptions are created (201) or none and a problem lists the failed items; with atomic=false the valid items are created and 207 reports the status of every item
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param atomic query bool false "Create all subscriptions or none" default(true)
+// @Param batch body model.BatchCreateRequest true "Subscriptions to create"
+// @Param allow_overlap query bool false "Allow overlapping other subscriptions to the same service" default(false)
+// @Success 201 {object} handler.batchResponse
+// @Success 207 {object} handler.batchResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 409 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /subscriptions/batch [post]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) CreateBatch(w http.ResponseWriter, r *http.Request) {
+	log.Printf("INFO: handler create subscriptions batch")
+
+	atomic, err := parseAtomic(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid atomic parameter")
+		return
+	}
+
+	allowOverlap, err := parseAllowOverlap(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid allow_overlap parameter")
+		return
+	}
+
+	var req model.BatchCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := model.Validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	b := newBatch(atomic, "items", len(req.Items))
+	subs := make([]*model.Subscription, 0, len(req.Items))
+
+	for i, item := range req.Items {
+		sub, p := parseBatchItem(item)
+		if p != nil {
+			b.reject(i, *p)
+			continue
+		}
+		sub.AllowOverlap = allowOverlap
+
+		subs = append(subs, sub)
+		b.accept(i)
+	}
+
+	if b.pending() {
+		errs, err := h.service.CreateBatch(r.Context(), subs, atomic)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+
+		b.apply(errs, func(j int, result *batchItemResult) {
+			resp := model.ToResponse(subs[j])
+			result.Status = http.StatusCreated
+			result.ID = &subs[j].ID
+			result.Version = &subs[j].Version
+			result.Subscription = &resp
+		})
+	}
+
+	b.write(w, http.StatusCreated)
+}
+
+// UpdateBatch godoc
+// @Summary Update subscriptions in bulk
+// @Description Replace up to 100 subscriptions at once; an optional version per item plays the role of If-Match. With atomic=true (default) either all subscriptions are updated (200) or none and a problem lists the failed items; with atomic=false 207 reports the status of every item
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param atomic query bool false "Update all subscriptions or none" default(true)
+// @Param batch body model.BatchUpdateRequest true "Subscriptions to update"
+// @Param allow_overlap query bool false "Allow overlapping other subscriptions to the same service; the stored setting of each subscription is kept when absent"
+// @Success 200 {object} handler.batchResponse
+// @Success 207 {object} handler.batchResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 409 {object} handler.problemResponse
+// @Failure 412 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /subscriptions/batch [put]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) UpdateBatch(w http.ResponseWriter, r *http.Request) {
+	log.Printf("INFO: handler update subscriptions batch")
+
+	atomic, err := parseAtomic(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid atomic parameter")
+		return
+	}
+
+	allowOverlap, err := parseAllowOverlap(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid allow_overlap parameter")
+		return
+	}
+
+	var req model.BatchUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := model.Validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	b := newBatch(atomic, "items", len(req.Items))
+	subs := make([]*model.Subscription, 0, len(req.Items))
+	versions := make([]*int, 0, len(req.Items))
+
+	for i, item := range req.Items {
+		if item.ID == uuid.Nil {
+			b.reject(i, validationProblem(&model.FieldError{Field: "id", Message: "is required"}))
+			continue
+		}
+
+		sub, p := parseBatchItem(item.CreateSubscriptionRequest)
+		if p != nil {
+			b.reject(i, *p)
+			continue
+		}
+		sub.ID = item.ID
+		sub.AllowOverlap = allowOverlap
+
+		subs = append(subs, sub)
+		versions = append(versions, item.Version)
+		b.accept(i)
+	}
+
+	if b.pending() {
+		errs, err := h.service.UpdateBatch(r.Context(), subs, versions, atomic)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+
+		b.apply(errs, func(j int, result *batchItemResult) {
+			resp := model.ToResponse(subs[j])
+			result.Status = http.StatusOK
+			result.ID = &subs[j].ID
+			result.Version = &subs[j].Version
+			result.Subscription = &resp
+		})
+	}
+
+	b.write(w, http.StatusOK)
+}
+
+// DeleteBatch godoc
+// @Summary Delete subscriptions in bulk
+// @Description Move up to 100 subscriptions to the trash at once. With atomic=true (default) either all subscriptions are deleted (200) or none and a problem lists the failed items; with atomic=false 207 reports the status of every item
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param atomic query bool false "Delete all subscriptions or none" default(true)
+// @Param batch body model.BatchDeleteRequest true "IDs of the subscriptions to delete"
+// @Success 200 {object} handler.batchResponse
+// @Success 207 {object} handler.batchResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /subscriptions/batch/delete [post]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) DeleteBatch(w http.ResponseWriter, r *http.Request) {
+	log.Printf("INFO: handler delete subscriptions batch")
+
+	atomic, err := parseAtomic(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid atomic parameter")
+		return
+	}
+
+	var req model.BatchDeleteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := model.Validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	b := newBatch(atomic, "ids", len(req.IDs))
+	for i := range req.IDs {
+		b.accept(i)
+	}
+
+	errs, err := h.service.DeleteBatch(r.Context(), req.IDs, atomic)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	b.apply(errs, func(j int, result *batchItemResult) {
+		result.Status = http.StatusNoContent
+		result.ID = &req.IDs[j]
+	})
+
+	b.write(w, http.StatusOK)
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/service"
+)
+
+// CatalogHandler manages HTTP communication for service catalog endpoints.
+type CatalogHandler struct {
+	service service.CatalogService
+}
+
+// NewCatalogHandler initializes a new handler with the provided catalog service.
+func NewCatalogHandler(s service.CatalogService) *CatalogHandler {
+	return &CatalogHandler{service: s}
+}
+
+// Create godoc
+// @Summary Create catalog entry
+// @Description Add a service to the catalog. Subscriptions created later with its name or one of its aliases are linked to it and stored under the canonical name
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param service body model.ServiceRequest true "Catalog entry"
+// @Success 201 {object} model.ServiceResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 409 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /services [post]
+// @Security BearerAuth
+func (h *CatalogHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.ServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := model.Validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	svc := model.ToService(req)
+	if err := h.service.Create(r.Context(), svc); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, model.ToServiceResponse(svc))
+}
+
+// Get godoc
+// @Summary Get catalog entry
+// @Description Get a service of the catalog by ID
+// @Tags services
+// @Produce json
+// @Param id path string true "Service ID" format(uuid)
+// @Success 200 {object} model.ServiceResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /services/{id} [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *CatalogHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	svc, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, model.ToServiceResponse(svc))
+}
+
+// List godoc
+// @Summary List catalog entries
+// @Description List the services of the catalog ordered by name
+// @Tags services
+// @Produce json
+// @Param category query string false "Category" example("video")
+// @Success 200 {array} model.ServiceResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /services [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *CatalogHandler) List(w http.ResponseWriter, r *http.Request) {
+	var category *string
+	if c := r.URL.Query().Get("category"); c != "" {
+		category = &c
+	}
+
+	services, err := h.service.List(r.Context(), category)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := make([]model.ServiceResponse, 0, len(services))
+	for _, svc := range services {
+		resp = append(resp, model.ToServiceResponse(svc))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Update godoc
+// @Summary Update catalog entry
+// @Description Replace the name, aliases, category and default price of a service. Linked subscriptions keep their stored service name
+// @Tags services
+// @Accept json
+// @Produce json
+// @Param id path string true "Service ID" format(uuid)
+// @Param service body model.ServiceRequest true "Catalog entry"
+// @Success 200 {object} model.ServiceResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 409 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /services/{id} [put]
+// @Security BearerAuth
+func (h *CatalogHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var req model.ServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := model.Validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	svc := model.ToService(req)
+	svc.ID = id
+	if err := h.service.Update(r.Context(), svc); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, model.ToServiceResponse(svc))
+}
+
+// Delete godoc
+// @Summary Delete catalog entry
+// @Description Delete a service from the catalog. Linked subscriptions keep their service name and are unlinked
+// @Tags services
+// @Param id path string true "Service ID" format(uuid)
+// @Success 204
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /services/{id} [delete]
+// @Security BearerAuth
+func (h *CatalogHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/service"
+)
+
+// CurrencyRateHandler manages HTTP communication for currency rate endpoints.
+type CurrencyRateHandler struct {
+	service service.CurrencyRateService
+}
+
+// NewCurrencyRateHandler initializes a new handler with the provided currency rate service.
+func NewCurrencyRateHandler(s service.CurrencyRateService) *CurrencyRateHandler {
+	return &CurrencyRateHandler{service: s}
+}
+
+// Set godoc
+// @Summary Set currency rate
+// @Description Create or replace the rate of a currency pair effective from the given month
+// @Tags currency-rates
+// @Accept json
+// @Produce json
+// @Param rate body model.CurrencyRateRequest true "Currency rate data"
+// @Success 201 {object} model.CurrencyRateResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /currency-rates [post]
+// @Security BearerAuth
+func (h *CurrencyRateHandler) Set(w http.ResponseWriter, r *http.Request) {
+	var req model.CurrencyRateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := model.Validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	rate, err := model.ToCurrencyRate(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid date format")
+		return
+	}
+
+	if err := h.service.Set(r.Context(), rate); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, model.ToCurrencyRateResponse(rate))
+}
+
+// List godoc
+// @Summary List currency rates
+// @Description List currency rates with optional currency pair filters
+// @Tags currency-rates
+// @Produce json
+// @Param base query string false "Base currency" example("USD")
+// @Param quote query string false "Quote currency" example("RUB")
+// @Success 200 {array} model.CurrencyRateResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /currency-rates [get]
+// @Security BearerAuth
+func (h *CurrencyRateHandler) List(w http.ResponseWriter, r *http.Request) {
+	var base, quote *string
+
+	if b := r.URL.Query().Get("base"); b != "" {
+		base = &b
+	}
+
+	if q := r.URL.Query().Get("quote"); q != "" {
+		quote = &q
+	}
+
+	rates, err := h.service.List(r.Context(), base, quote)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := make([]model.CurrencyRateResponse, 0, len(rates))
+	for _, rate := range rates {
+		resp = append(resp, model.ToCurrencyRateResponse(rate))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Delete godoc
+// @Summary Delete currency rate
+// @Description Delete currency rate by ID
+// @Tags currency-rates
+// @Param id path string true "Currency rate ID" format(uuid)
+// @Success 204
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /currency-rates/{id} [delete]
+// @Security BearerAuth
+func (h *CurrencyRateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
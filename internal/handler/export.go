@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+
+	"subscription-service/internal/model"
+)
+
+// exportColumns are the columns of CSV and XLSX exports.
+var exportColumns = []string{
+	"id", "user_id", "service_name", "price", "currency", "billing_period", "start_date", "end_date", "monthly_cost",
+}
+
+// exportValues returns the values of a row in exportColumns order; a missing end date is empty.
+func exportValues(row model.ExportRow) []any {
+	endDate := ""
+	if row.EndDate != nil {
+		endDate = *row.EndDate
+	}
+
+	return []any{
+		row.ID.String(),
+		row.UserID.String(),
+		row.ServiceName,
+		row.Price,
+		row.Currency,
+		row.BillingPeriod,
+		row.StartDate,
+		endDate,
+		row.MonthlyCost,
+	}
+}
+
+// exportWriter encodes export rows into a response body. Close finishes the document.
+type exportWriter interface {
+	Write(row model.ExportRow) error
+	Close() error
+}
+
+// exportContentTypes maps export formats to their media types.
+var exportContentTypes = map[string]string{
+	model.ExportCSV:   "text/csv; charset=utf-8",
+	model.ExportJSONL: "application/x-ndjson",
+	model.ExportXLSX:  "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// newExportWriter sets the export headers and returns the writer for the format.
+func newExportWriter(w http.ResponseWriter, format string) (exportWriter, error) {
+	filename := "subscriptions-" + time.Now().UTC().Format("20060102") + "." + format
+
+	w.Header().Set("Content-Type", exportContentTypes[format])
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": filename}))
+
+	// The constructors return typed pointers, which must not be converted to a non-nil interface on failure.
+	switch format {
+	case model.ExportJSONL:
+		return &jsonlExport{enc: json.NewEncoder(w)}, nil
+	case model.ExportXLSX:
+		out, err := newXLSXExport(w)
+		if err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+	out, err := newCSVExport(w)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// csvExport writes rows as CSV with a header line.
+type csvExport struct {
+	w *csv.Writer
+}
+
+func newCSVExport(w io.Writer) (*csvExport, error) {
+	e := &csvExport{w: csv.NewWriter(w)}
+	if err := e.w.Write(exportColumns); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *csvExport) Write(row model.ExportRow) error {
+	values := exportValues(row)
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case int:
+			record[i] = strconv.Itoa(v)
+		case string:
+			record[i] = v
+		}
+	}
+	return e.w.Write(record)
+}
+
+func (e *csvExport) Close() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// jsonlExport writes every row as a JSON object on its own line.
+type jsonlExport struct {
+	enc *json.Encoder
+}
+
+func (e *jsonlExport) Write(row model.ExportRow) error {
+	return e.enc.Encode(row)
+}
+
+func (e *jsonlExport) Close() error {
+	return nil
+}
+
+// xlsxExport writes rows into a single worksheet with a stream writer, which keeps only a bounded number of
+// rows in memory. The workbook is sent when it is closed, as the XLSX container cannot be written incrementally.
+type xlsxExport struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newXLSXExport(w io.Writer) (*xlsxExport, error) {
+	file := excelize.NewFile()
+
+	stream, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	e := &xlsxExport{w: w, file: file, stream: stream}
+
+	header := make([]any, len(exportColumns))
+	for i, column := range exportColumns {
+		header[i] = column
+	}
+	if err := e.append(header); err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+func (e *xlsxExport) append(values []any) error {
+	e.row++
+	cell, err := excelize.CoordinatesToCellName(1, e.row)
+	if err != nil {
+		return err
+	}
+	return e.stream.SetRow(cell, values)
+}
+
+func (e *xlsxExport) Write(row model.ExportRow) error {
+	return e.append(exportValues(row))
+}
+
+func (e *xlsxExport) Close() error {
+	defer func() { _ = e.file.Close() }()
+
+	if err := e.stream.Flush(); err != nil {
+		return err
+	}
+	return e.file.Write(e.w)
+}
+
+// Export godoc
+// @Summary Export subscriptions
+// @Description Download all subscriptions matching the List filters and sort order as CSV, JSON Lines or XLSX, with the price spread over months in monthly_cost. Rows are streamed from the database
+// @Tags subscriptions
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Param format query string false "Export format" Enums(csv, jsonl, xlsx) default(csv)
+// @Param user_id query []string false "Filter by user IDs (repeated or comma-separated)" collectionFormat(csv)
+// @Param service_name query string false "Filter by exact service name"
+// @Param service_name_prefix query string false "Filter by service name prefix"
+// @Param service_id query string false "Filter by service catalog entry ID" format(uuid)
+// @Param category query string false "Filter by category of the service catalog entry"
+// @Param min_price query int false "Minimum price"
+// @Param max_price query int false "Maximum price"
+// @Param active_at query string false "Active in month (MM-YYYY)"
+// @Param deleted query bool false "Export the trash instead of live subscriptions"
+// @Param sort query string false "Sort field, prefixed with - for descending order"
+// @Success 200 {file} file
+// @Failure 400 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /subscriptions/export [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) Export(w http.ResponseWriter, r *http.Request) {
+	log.Printf("INFO: handler export subscriptions")
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = model.ExportCSV
+	}
+	if _, ok := exportContentTypes[format]; !ok {
+		writeValidationError(w, &model.FieldError{Field: "format", Message: "must be one of csv, jsonl, xlsx"})
+		return
+	}
+
+	filter, err := parseSubscriptionFilter(r.URL.Query())
+	if err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	// The response is started with the first row, so that errors found before any row is read
+	// can still be reported as a problem.
+	var out exportWriter
+	start := func() error {
+		if out != nil {
+			return nil
+		}
+		out, err = newExportWriter(w, format)
+		return err
+	}
+
+	err = h.service.Export(r.Context(), filter, func(sub *model.Subscription) error {
+		if err := start(); err != nil {
+			return err
+		}
+		return out.Write(model.ToExportRow(sub))
+	})
+	if err == nil {
+		err = start()
+	}
+
+	switch {
+	case err != nil && out == nil:
+		writeServiceError(w, err)
+	case err != nil:
+		log.Printf("ERROR: export aborted: %v", err)
+	default:
+		if err := out.Close(); err != nil {
+			log.Printf("ERROR: failed to write export: %v", err)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"subscription-service/internal/model"
+)
+
+// parseSubscriptionFilter builds the list filter from query parameters. User IDs may be repeated or
+// comma-separated; months use the "MM-YYYY" format. Invalid parameters are reported as *model.FieldError.
+func parseSubscriptionFilter(q url.Values) (model.SubscriptionFilter, error) {
+	var f model.SubscriptionFilter
+
+	for _, param := range q["user_id"] {
+		for _, raw := range strings.Split(param, ",") {
+			if raw = strings.TrimSpace(raw); raw == "" {
+				continue
+			}
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				return f, &model.FieldError{Field: "user_id", Message: "must be a UUID"}
+			}
+			f.UserIDs = append(f.UserIDs, id)
+		}
+	}
+
+	if v := q.Get("service_name"); v != "" {
+		f.ServiceName = &v
+	}
+
+	if v := q.Get("service_name_prefix"); v != "" {
+		f.ServiceNamePrefix = &v
+	}
+
+	if v := q.Get("service_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return f, &model.FieldError{Field: "service_id", Message: "must be a UUID"}
+		}
+		f.ServiceID = &id
+	}
+
+	if v := q.Get("category"); v != "" {
+		f.Category = &v
+	}
+
+	for name, target := range map[string]**int{
+		"min_price": &f.MinPrice,
+		"max_price": &f.MaxPrice,
+	} {
+		if v := q.Get(name); v != "" {
+			parsed, err := strconv.Atoi(v)
+			if err != nil {
+				return f, &model.FieldError{Field: name, Message: "must be an integer"}
+			}
+			*target = &parsed
+		}
+	}
+
+	for name, target := range map[string]**time.Time{
+		"active_at":  &f.ActiveAt,
+		"start_from": &f.StartFrom,
+		"start_to":   &f.StartTo,
+		"end_from":   &f.EndFrom,
+		"end_to":     &f.EndTo,
+	} {
+		if v := q.Get(name); v != "" {
+			parsed, err := time.Parse("01-2006", v)
+			if err != nil {
+				return f, &model.FieldError{Field: name, Message: "must be a month in MM-YYYY format"}
+			}
+			*target = &parsed
+		}
+	}
+
+	if v := q.Get("deleted"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, &model.FieldError{Field: "deleted", Message: "must be a boolean"}
+		}
+		f.Deleted = parsed
+	}
+
+	if v := q.Get("has_end_date"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, &model.FieldError{Field: "has_end_date", Message: "must be a boolean"}
+		}
+		f.HasEndDate = &parsed
+	}
+
+	sort, err := model.ParseSort(q.Get("sort"))
+	if err != nil {
+		return f, &model.FieldError{Field: "sort", Message: strings.TrimPrefix(err.Error(), "invalid sort: ")}
+	}
+	f.Sort = sort
+
+	return f, nil
+}
+
+// parseCostQuery builds a cost aggregation query without grouping keys from query parameters: the required
+// from and to months ("MM-YYYY"), the optional user, service, catalog entry and category filters, the target
+// currency and amortize. Invalid parameters are reported as errors with a message for the client.
+func parseCostQuery(q url.Values) (model.CostQuery, error) {
+	var query model.CostQuery
+
+	fromStr, toStr := q.Get("from"), q.Get("to")
+	if fromStr == "" || toStr == "" {
+		return query, errors.New("from and to are required")
+	}
+
+	from, err := time.Parse("01-2006", fromStr)
+	if err != nil {
+		return query, errors.New("invalid from date")
+	}
+
+	to, err := time.Parse("01-2006", toStr)
+	if err != nil {
+		return query, errors.New("invalid to date")
+	}
+
+	query.From, query.To, query.Currency = from, to, q.Get("currency")
+
+	if uid := q.Get("user_id"); uid != "" {
+		parsed, err := uuid.Parse(uid)
+		if err != nil {
+			return query, errors.New("invalid user_id")
+		}
+		query.UserID = &parsed
+	}
+
+	if sn := q.Get("service_name"); sn != "" {
+		query.ServiceName = &sn
+	}
+
+	if sid := q.Get("service_id"); sid != "" {
+		parsed, err := uuid.Parse(sid)
+		if err != nil {
+			return query, errors.New("invalid service_id")
+		}
+		query.ServiceID = &parsed
+	}
+
+	if c := q.Get("category"); c != "" {
+		query.Category = &c
+	}
+
+	if a := q.Get("amortize"); a != "" {
+		parsed, err := strconv.ParseBool(a)
+		if err != nil {
+			return query, errors.New("invalid amortize")
+		}
+		query.Amortize = parsed
+	}
+
+	return query, nil
+}
@@ -2,10 +2,11 @@ package handler
 
 import (
 	"encoding/json"
+	"io"
 	"log"
 	"net/http"
 	"strconv"
-	"time"
+	"strings"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -31,13 +32,25 @@ func NewSubscriptionHandler(s service.SubscriptionService) *SubscriptionHandler
 // @Accept json
 // @Produce json
 // @Param subscription body model.CreateSubscriptionRequest true "Subscription data"
+// @Param allow_overlap query bool false "Allow overlapping another subscription to the same service" default(false)
 // @Success 201 {object} model.SubscriptionResponse
-// @Failure 400 {object} handler.errorResponse
-// @Failure 500 {object} handler.errorResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 409 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
 // @Router /subscriptions [post]
+// @Security BearerAuth
+// @Security ApiKeyAuth
 func (h *SubscriptionHandler) Create(w http.ResponseWriter, r *http.Request) {
 	log.Printf("INFO: handler create subscription")
 
+	allowOverlap, err := parseAllowOverlap(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid allow_overlap parameter")
+		return
+	}
+
 	var req model.CreateSubscriptionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
@@ -45,7 +58,7 @@ func (h *SubscriptionHandler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := model.Validate.Struct(req); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeValidationError(w, err)
 		return
 	}
 
@@ -54,12 +67,14 @@ func (h *SubscriptionHandler) Create(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid date format")
 		return
 	}
+	sub.AllowOverlap = allowOverlap
 
 	if err := h.service.Create(r.Context(), sub); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
+	w.Header().Set("ETag", formatETag(sub.Version))
 	writeJSON(w, http.StatusCreated, model.ToResponse(sub))
 }
 
@@ -70,9 +85,14 @@ func (h *SubscriptionHandler) Create(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param id path string true "Subscription ID" format(uuid) example("550e8400-e29b-41d4-a716-446655440000")
 // @Success 200 {object} model.SubscriptionResponse
-// @Failure 400 {object} handler.errorResponse
-// @Failure 404 {object} handler.errorResponse
+// @Header 200 {string} ETag "Current version of the subscription"
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
 // @Router /subscriptions/{id} [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
 func (h *SubscriptionHandler) Get(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 
@@ -84,10 +104,11 @@ func (h *SubscriptionHandler) Get(w http.ResponseWriter, r *http.Request) {
 
 	sub, err := h.service.Get(r.Context(), id)
 	if err != nil {
-		writeError(w, http.StatusNotFound, err.Error())
+		writeServiceError(w, err)
 		return
 	}
 
+	w.Header().Set("ETag", formatETag(sub.Version))
 	writeJSON(w, http.StatusOK, model.ToResponse(sub))
 }
 
@@ -98,11 +119,21 @@ func (h *SubscriptionHandler) Get(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Subscription ID" format(uuid) example("550e8400-e29b-41d4-a716-446655440000")
+// @Param If-Match header string false "ETag of the version being replaced"
 // @Param subscription body model.CreateSubscriptionRequest true "Updated subscription data"
+// @Param allow_overlap query bool false "Allow overlapping another subscription to the same service; the stored setting is kept when absent"
 // @Success 200 {object} model.SubscriptionResponse
-// @Failure 400 {object} handler.errorResponse
-// @Failure 500 {object} handler.errorResponse
+// @Header 200 {string} ETag "New version of the subscription"
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 409 {object} handler.problemResponse
+// @Failure 412 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
 // @Router /subscriptions/{id} [put]
+// @Security BearerAuth
+// @Security ApiKeyAuth
 func (h *SubscriptionHandler) Update(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
@@ -111,6 +142,18 @@ func (h *SubscriptionHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	expectedVersion, ok := parseIfMatch(r)
+	if !ok {
+		writeError(w, http.StatusPreconditionFailed, "invalid If-Match header")
+		return
+	}
+
+	allowOverlap, err := parseAllowOverlap(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid allow_overlap parameter")
+		return
+	}
+
 	var req model.CreateSubscriptionRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		writeError(w, http.StatusBadRequest, "invalid request body")
@@ -118,7 +161,7 @@ func (h *SubscriptionHandler) Update(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := model.Validate.Struct(req); err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+		writeValidationError(w, err)
 		return
 	}
 
@@ -128,24 +171,98 @@ func (h *SubscriptionHandler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	sub.ID = id
+	sub.AllowOverlap = allowOverlap
 
-	if err := h.service.Update(r.Context(), sub); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+	if err := h.service.Update(r.Context(), sub, expectedVersion); err != nil {
+		writeServiceError(w, err)
 		return
 	}
 
+	w.Header().Set("ETag", formatETag(sub.Version))
+	writeJSON(w, http.StatusOK, model.ToResponse(sub))
+}
+
+// Patch godoc
+// @Summary Patch subscription
+// @Description Partially update subscription by ID using JSON Merge Patch (RFC 7386); "end_date": null clears the end date
+// @Tags subscriptions
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Param id path string true "Subscription ID" format(uuid) example("550e8400-e29b-41d4-a716-446655440000")
+// @Param If-Match header string false "ETag of the version being patched"
+// @Param patch body object true "Fields to change" example({"price": 1200, "end_date": null})
+// @Param allow_overlap query bool false "Allow overlapping another subscription to the same service; the stored setting is kept when absent"
+// @Success 200 {object} model.SubscriptionResponse
+// @Header 200 {string} ETag "New version of the subscription"
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 409 {object} handler.problemResponse
+// @Failure 412 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /subscriptions/{id} [patch]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	expectedVersion, ok := parseIfMatch(r)
+	if !ok {
+		writeError(w, http.StatusPreconditionFailed, "invalid If-Match header")
+		return
+	}
+
+	allowOverlap, err := parseAllowOverlap(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid allow_overlap parameter")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	patch, err := model.ParseSubscriptionPatch(body)
+	if err != nil {
+		writeValidationError(w, err)
+		return
+	}
+	patch.AllowOverlap = allowOverlap
+
+	sub, err := h.service.Patch(r.Context(), id, patch, expectedVersion)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", formatETag(sub.Version))
 	writeJSON(w, http.StatusOK, model.ToResponse(sub))
 }
 
 // Delete godoc
 // @Summary Delete subscription
-// @Description Delete subscription by ID
+// @Description Move subscription to the trash (soft delete) by ID
 // @Tags subscriptions
 // @Param id path string true "Subscription ID" format(uuid) example("550e8400-e29b-41d4-a716-446655440000")
+// @Param If-Match header string false "ETag of the version being deleted"
 // @Success 204
-// @Failure 400 {object} handler.errorResponse
-// @Failure 500 {object} handler.errorResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 412 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
 // @Router /subscriptions/{id} [delete]
+// @Security BearerAuth
+// @Security ApiKeyAuth
 func (h *SubscriptionHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := uuid.Parse(idStr)
@@ -154,63 +271,239 @@ func (h *SubscriptionHandler) Delete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := h.service.Delete(r.Context(), id); err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+	expectedVersion, ok := parseIfMatch(r)
+	if !ok {
+		writeError(w, http.StatusPreconditionFailed, "invalid If-Match header")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id, expectedVersion); err != nil {
+		writeServiceError(w, err)
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// Restore godoc
+// @Summary Restore subscription
+// @Description Restore a soft-deleted subscription from the trash
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "Subscription ID" format(uuid) example("550e8400-e29b-41d4-a716-446655440000")
+// @Success 200 {object} model.SubscriptionResponse
+// @Header 200 {string} ETag "New version of the subscription"
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 409 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /subscriptions/{id}/restore [post]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) Restore(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	sub, err := h.service.Restore(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("ETag", formatETag(sub.Version))
+	writeJSON(w, http.StatusOK, model.ToResponse(sub))
+}
+
+// Purge godoc
+// @Summary Purge trash
+// @Description Permanently remove subscriptions soft-deleted more than N days ago
+// @Tags subscriptions
+// @Produce json
+// @Param older_than_days query int true "Retention in days" example(30)
+// @Success 200 {object} model.PurgeResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /subscriptions/purge [post]
+// @Security BearerAuth
+func (h *SubscriptionHandler) Purge(w http.ResponseWriter, r *http.Request) {
+	days, err := strconv.Atoi(r.URL.Query().Get("older_than_days"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "older_than_days is required")
+		return
+	}
+
+	purged, err := h.service.Purge(r.Context(), days)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, model.PurgeResponse{Purged: purged})
+}
+
+// SchedulePrice godoc
+// @Summary Schedule price change
+// @Description Set the subscription price in force from the given month onwards; earlier months keep their price
+// @Tags subscriptions
+// @Accept json
+// @Produce json
+// @Param id path string true "Subscription ID" format(uuid) example("550e8400-e29b-41d4-a716-446655440000")
+// @Param change body model.PriceChangeRequest true "Price change"
+// @Success 201 {object} model.PriceChangeResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /subscriptions/{id}/prices [post]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) SchedulePrice(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var req model.PriceChangeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := model.Validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	change, err := model.ToPriceChange(req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid date format")
+		return
+	}
+	change.SubscriptionID = id
+
+	if err := h.service.SchedulePrice(r.Context(), change); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, model.ToPriceChangeResponse(change))
+}
+
+// Prices godoc
+// @Summary Price history
+// @Description List the prices of a subscription by the month they take effect, including scheduled changes
+// @Tags subscriptions
+// @Produce json
+// @Param id path string true "Subscription ID" format(uuid) example("550e8400-e29b-41d4-a716-446655440000")
+// @Success 200 {array} model.PriceChangeResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /subscriptions/{id}/prices [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) Prices(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	prices, err := h.service.ListPrices(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := make([]model.PriceChangeResponse, 0, len(prices))
+	for _, change := range prices {
+		resp = append(resp, model.ToPriceChangeResponse(change))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
 // List godoc
 // @Summary List subscriptions
-// @Description List subscriptions with optional filters
+// @Description List subscriptions with optional filters and sorting (newest first by default). Without the cursor
+// @Description parameter the page is selected by limit/offset and returned as an array; with it, keyset pagination
+// @Description is used and the page is wrapped in an envelope with next_cursor.
 // @Tags subscriptions
 // @Produce json
-// @Param user_id query string false "User ID" format(uuid)
-// @Param service_name query string false "Service name"
+// @Param user_id query []string false "User IDs (repeated or comma-separated)" collectionFormat(csv)
+// @Param service_name query string false "Service name (exact match)"
+// @Param service_name_prefix query string false "Service name prefix (case-insensitive)"
+// @Param service_id query string false "Service catalog entry ID" format(uuid)
+// @Param category query string false "Category of the service catalog entry" example("video")
+// @Param min_price query int false "Minimum current price in minor units"
+// @Param max_price query int false "Maximum current price in minor units"
+// @Param active_at query string false "Active in month" example("03-2025")
+// @Param start_from query string false "Start date from (inclusive)" example("01-2025")
+// @Param start_to query string false "Start date to (inclusive)" example("12-2025")
+// @Param end_from query string false "End date from (inclusive)" example("01-2025")
+// @Param end_to query string false "End date to (inclusive)" example("12-2025")
+// @Param has_end_date query bool false "Only subscriptions with (true) or without (false) an end date"
+// @Param sort query string false "Sort field, - for descending" Enums(created_at, -created_at, price, -price, start_date, -start_date, service_name, -service_name)
+// @Param deleted query bool false "List soft-deleted subscriptions (trash) instead of live ones"
 // @Param limit query int false "Limit"
-// @Param offset query int false "Offset"
-// @Success 200 {array} model.SubscriptionResponse
-// @Failure 400 {object} handler.errorResponse
-// @Failure 500 {object} handler.errorResponse
+// @Param offset query int false "Offset (ignored in cursor mode)"
+// @Param cursor query string false "Cursor mode: next_cursor of the previous page, empty for the first page"
+// @Success 200 {array} model.SubscriptionResponse "Offset mode; model.SubscriptionListResponse in cursor mode"
+// @Header 200 {integer} X-Total-Count "Number of subscriptions matching the filters"
+// @Failure 400 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
 // @Router /subscriptions [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
 func (h *SubscriptionHandler) List(w http.ResponseWriter, r *http.Request) {
-	var (
-		userID      *uuid.UUID
-		serviceName *string
-	)
+	filter, err := parseSubscriptionFilter(r.URL.Query())
+	if err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	var page model.Page
+	page.Limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	page.Offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
 
-	if uid := r.URL.Query().Get("user_id"); uid != "" {
-		parsed, err := uuid.Parse(uid)
+	cursorMode := r.URL.Query().Has("cursor")
+	if c := r.URL.Query().Get("cursor"); c != "" {
+		after, err := model.DecodeCursor(c)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid user_id")
+			writeError(w, http.StatusBadRequest, err.Error())
 			return
 		}
-		userID = &parsed
+		page.After = after
 	}
 
-	if sn := r.URL.Query().Get("service_name"); sn != "" {
-		serviceName = &sn
+	result, err := h.service.List(r.Context(), filter, page)
+	if err != nil {
+		writeServiceError(w, err)
+		return
 	}
 
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	w.Header().Set("X-Total-Count", strconv.Itoa(result.Total))
 
-	subs, err := h.service.List(
-		r.Context(),
-		userID,
-		serviceName,
-		limit,
-		offset,
-	)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, err.Error())
+	if cursorMode {
+		writeJSON(w, http.StatusOK, model.ToListResponse(result))
 		return
 	}
 
-	resp := make([]model.SubscriptionResponse, 0, len(subs))
-	for _, s := range subs {
+	resp := make([]model.SubscriptionResponse, 0, len(result.Items))
+	for _, s := range result.Items {
 		resp = append(resp, model.ToResponse(s))
 	}
 
@@ -218,65 +511,52 @@ func (h *SubscriptionHandler) List(w http.ResponseWriter, r *http.Request) {
 }
 
 // @Summary Aggregate subscriptions cost
-// @Description Calculate total cost of subscriptions for a period
+// @Description Calculate total cost of subscriptions for a period in minor currency units (each billing period is charged per active month in the window)
 // @Tags subscriptions
 // @Produce json
 // @Param from query string true "Start period" example("01-2026")
 // @Param to query string true "End period" example("12-2026")
 // @Param user_id query string false "User ID" format(uuid)
 // @Param service_name query string false "Service name" example("Netflix")
-// @Success 200 {object} map[string]int "Example: {"total": 1500}"
-// @Failure 400 {object} handler.errorResponse
+// @Param service_id query string false "Service catalog entry ID" format(uuid)
+// @Param category query string false "Category of the service catalog entry" example("video")
+// @Param currency query string false "Target ISO 4217 currency (required if subscriptions use several currencies)" example("RUB")
+// @Param amortize query bool false "Spread quarterly, yearly and weekly prices evenly over months"
+// @Param group_by query string false "Comma-separated breakdown keys: month, service, user, category" example("month,service")
+// @Success 200 {object} model.TotalResponse "Returned without group_by"
+// @Success 200 {object} model.SummaryResponse "Returned when group_by is set"
+// @Failure 400 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
 // @Router /subscriptions/summary [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
 func (h *SubscriptionHandler) Summary(w http.ResponseWriter, r *http.Request) {
-	fromStr := r.URL.Query().Get("from")
-	toStr := r.URL.Query().Get("to")
-
-	if fromStr == "" || toStr == "" {
-		writeError(w, http.StatusBadRequest, "from and to are required")
-		return
-	}
-
-	from, err := time.Parse("01-2006", fromStr)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid from date")
-		return
-	}
-
-	to, err := time.Parse("01-2006", toStr)
+	query, err := parseCostQuery(r.URL.Query())
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid to date")
+		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	var userID *uuid.UUID
-	if uid := r.URL.Query().Get("user_id"); uid != "" {
-		parsed, err := uuid.Parse(uid)
-		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid user_id")
-			return
+	if groupBy := r.URL.Query().Get("group_by"); groupBy != "" {
+		for _, key := range strings.Split(groupBy, ",") {
+			query.GroupBy = append(query.GroupBy, strings.TrimSpace(key))
 		}
-		userID = &parsed
 	}
 
-	var serviceName *string
-	if sn := r.URL.Query().Get("service_name"); sn != "" {
-		serviceName = &sn
+	summary, err := h.service.Aggregate(r.Context(), query)
+	if err != nil {
+		writeServiceError(w, err)
+		return
 	}
 
-	total, err := h.service.Aggregate(
-		r.Context(),
-		userID,
-		serviceName,
-		from,
-		to,
-	)
-	if err != nil {
-		writeError(w, http.StatusBadRequest, err.Error())
+	if len(query.GroupBy) == 0 {
+		writeJSON(w, http.StatusOK, model.TotalResponse{
+			Total:    summary.Total,
+			Currency: summary.Currency,
+		})
 		return
 	}
 
-	writeJSON(w, http.StatusOK, map[string]int{
-		"total": total,
-	})
+	writeJSON(w, http.StatusOK, model.ToSummaryResponse(summary))
 }
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"subscription-service/internal/model"
+)
+
+// maxImportSize limits the size of an uploaded CSV file.
+const maxImportSize = 1 << 20
+
+// importColumns lists the CSV columns understood by the import, named after the CreateSubscriptionRequest
+// JSON fields. Columns marked true are required in the header.
+var importColumns = map[string]bool{
+	"service_name":   true,
+	"price":          true,
+	"currency":       false,
+	"billing_period": false,
+	"user_id":        true,
+	"start_date":     true,
+	"end_date":       false,
+}
+
+// importRowResult reports the outcome of a single CSV row. Line is the line number in the file,
+// the header being line 1.
+type importRowResult struct {
+	Line   int              `json:"line" extensions:"x-order=1"`
+	Status int              `json:"status" extensions:"x-order=2"`
+	ID     *uuid.UUID       `json:"id,omitempty" extensions:"x-order=3"`
+	Error  *problemResponse `json:"error,omitempty" extensions:"x-order=4"`
+}
+
+// importResponse summarizes a CSV import: how many rows were (or in dry-run mode would be) imported,
+// how many were rejected, and the result of every row.
+type importResponse struct {
+	DryRun   bool              `json:"dry_run" extensions:"x-order=1"`
+	Imported int               `json:"imported" extensions:"x-order=2"`
+	Failed   int               `json:"failed" extensions:"x-order=3"`
+	Rows     []importRowResult `json:"rows" extensions:"x-order=4"`
+}
+
+// readImportRow converts a CSV record into a creation request. Values that cannot be converted to the
+// field types are reported as *model.FieldError.
+func readImportRow(header map[string]int, record []string) (model.CreateSubscriptionRequest, error) {
+	var req model.CreateSubscriptionRequest
+
+	value := func(column string) string {
+		if i, ok := header[column]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	req.ServiceName = value("service_name")
+	req.Currency = value("currency")
+	req.BillingPeriod = value("billing_period")
+	req.StartDate = value("start_date")
+
+	if v := value("end_date"); v != "" {
+		req.EndDate = &v
+	}
+
+	if v := value("price"); v != "" {
+		price, err := strconv.Atoi(v)
+		if err != nil {
+			return req, &model.FieldError{Field: "price", Message: "must be an integer"}
+		}
+		req.Price = price
+	}
+
+	if v := value("user_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return req, &model.FieldError{Field: "user_id", Message: "must be a UUID"}
+		}
+		req.UserID = id
+	}
+
+	return req, nil
+}
+
+// readImportHeader maps column names to their positions and checks that all required columns are present.
+func readImportHeader(record []string) (map[string]int, error) {
+	header := make(map[string]int, len(record))
+	for i, name := range record {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, ok := importColumns[name]; !ok {
+			return nil, &model.FieldError{Field: name, Message: "is not a known column"}
+		}
+		header[name] = i
+	}
+
+	for name, required := range importColumns {
+		if _, ok := header[name]; required && !ok {
+			return nil, &model.FieldError{Field: name, Message: "column is required"}
+		}
+	}
+
+	return header, nil
+}
+
+// Import godoc
+// @Summary Import subscriptions from CSV
+// @Description Create subscriptions from a CSV file whose header names CreateSubscriptionRequest fields (service_name, price, user_id and start_date are required). Every row is validated separately; rows duplicating an existing subscription or an earlier row (same user_id, service_name and start_date) or overlapping one are skipped with 409. With dry_run=true nothing is written
+// @Tags subscriptions
+// @Accept text/csv
+// @Produce json
+// @Param dry_run query bool false "Only validate the file" default(false)
+// @Param allow_overlap query bool false "Import rows overlapping other subscriptions to the same service" default(false)
+// @Param file body string true "CSV file"
+// @Success 200 {object} handler.importResponse
+// @Success 201 {object} handler.importResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 413 {object} handler.problemResponse
+// @Failure 415 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /subscriptions/import [post]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) Import(w http.ResponseWriter, r *http.Request) {
+	log.Printf("INFO: handler import subscriptions")
+
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "text/csv" {
+		writeError(w, http.StatusUnsupportedMediaType, "content type must be text/csv")
+		return
+	}
+
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid dry_run parameter")
+			return
+		}
+	}
+
+	allowOverlap, err := parseAllowOverlap(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid allow_overlap parameter")
+		return
+	}
+
+	reader := csv.NewReader(http.MaxBytesReader(w, r.Body, maxImportSize))
+
+	record, err := reader.Read()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid CSV header")
+		return
+	}
+
+	header, err := readImportHeader(record)
+	if err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	b := newBatch(false, "rows", 0)
+	var (
+		lines []int
+		subs  []*model.Subscription
+	)
+
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeError(w, http.StatusRequestEntityTooLarge, "CSV file is too large")
+			return
+		}
+
+		var parseErr *csv.ParseError
+		if err != nil && !(errors.As(err, &parseErr) && errors.Is(parseErr.Err, csv.ErrFieldCount)) {
+			writeError(w, http.StatusBadRequest, "invalid CSV: "+err.Error())
+			return
+		}
+
+		if len(lines) == model.MaxImportRows {
+			writeError(w, http.StatusBadRequest, "too many rows: the limit is "+strconv.Itoa(model.MaxImportRows))
+			return
+		}
+
+		line, _ := reader.FieldPos(0)
+		i := len(lines)
+		lines = append(lines, line)
+		b.results = append(b.results, batchItemResult{Index: i})
+
+		if err != nil {
+			b.reject(i, problemResponse{Status: http.StatusBadRequest, Detail: "wrong number of fields"})
+			continue
+		}
+
+		req, err := readImportRow(header, record)
+		if err != nil {
+			b.reject(i, validationProblem(err))
+			continue
+		}
+
+		sub, p := parseBatchItem(req)
+		if p != nil {
+			b.reject(i, *p)
+			continue
+		}
+		sub.AllowOverlap = allowOverlap
+
+		subs = append(subs, sub)
+		b.accept(i)
+	}
+
+	if b.pending() {
+		errs, err := h.service.Import(r.Context(), subs, dryRun)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+
+		b.apply(errs, func(j int, result *batchItemResult) {
+			result.Status = http.StatusCreated
+			if !dryRun {
+				result.ID = &subs[j].ID
+			}
+		})
+	}
+
+	resp := importResponse{DryRun: dryRun, Rows: make([]importRowResult, len(b.results))}
+	for i, result := range b.results {
+		resp.Rows[i] = importRowResult{Line: lines[i], Status: result.Status, ID: result.ID, Error: result.Error}
+		if result.Error != nil {
+			resp.Failed++
+		} else {
+			resp.Imported++
+		}
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	writeJSON(w, status, resp)
+}
@@ -3,7 +3,14 @@ package handler
 import (
 	"log"
 	"net/http"
+	"strings"
 	"time"
+
+	"subscription-service/internal/auth"
+	"subscription-service/internal/model"
+	"subscription-service/internal/service"
+
+	"github.com/google/uuid"
 )
 
 // LoggingMiddleware records the details of incoming HTTP requests, including the method,
@@ -28,3 +35,102 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		)
 	})
 }
+
+// Headers identifying the caller and the request for the audit log.
+const (
+	HeaderActor     = "X-Actor"
+	HeaderRequestID = "X-Request-ID"
+)
+
+// AuditContextMiddleware stores the actor and request ID in the request context so that changes are
+// attributed in the audit log. The actor is the authenticated subject, or the X-Actor header when
+// authentication is disabled. A request ID is generated when the client does
+// not send one and is echoed back in the X-Request-ID response header.
+func AuditContextMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get(HeaderRequestID)
+		if requestID == "" {
+			requestID = uuid.NewString()
+		}
+		w.Header().Set(HeaderRequestID, requestID)
+
+		actor := r.Header.Get(HeaderActor)
+		if id, ok := model.IdentityFrom(r.Context()); ok {
+			actor = id.Subject
+		}
+
+		ctx := model.WithAuditInfo(r.Context(), model.AuditInfo{
+			Actor:     actor,
+			RequestID: requestID,
+		})
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// HeaderAPIKey carries the secret of an API key used by services instead of a bearer token.
+const HeaderAPIKey = "X-API-Key"
+
+// AuthMiddleware authenticates requests with an X-API-Key header or an "Authorization: Bearer <JWT>" header,
+// rejects requests without valid credentials with 401 and stores the caller identity in the request context.
+func AuthMiddleware(verifier *auth.Verifier, keys service.APIKeyService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if secret := r.Header.Get(HeaderAPIKey); secret != "" {
+				key, err := keys.Authenticate(r.Context(), secret)
+				if err != nil {
+					writeError(w, http.StatusUnauthorized, service.ErrInvalidAPIKey.Error())
+					return
+				}
+
+				id := model.Identity{Subject: "api-key:" + key.Name, APIKey: key}
+				next.ServeHTTP(w, r.WithContext(model.WithIdentity(r.Context(), id)))
+				return
+			}
+
+			header := r.Header.Get("Authorization")
+			token, found := strings.CutPrefix(header, "Bearer ")
+			if !found || token == "" {
+				w.Header().Set("WWW-Authenticate", `Bearer`)
+				writeError(w, http.StatusUnauthorized, "missing bearer token or api key")
+				return
+			}
+
+			id, err := verifier.Verify(token)
+			if err != nil {
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				writeError(w, http.StatusUnauthorized, err.Error())
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(model.WithIdentity(r.Context(), id)))
+		})
+	}
+}
+
+// RequireScope rejects requests made with an API key that does not grant the scope with 403.
+// Callers authenticated with a bearer token are not affected.
+func RequireScope(scope model.Scope) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if id, ok := model.IdentityFrom(r.Context()); ok && id.APIKey != nil && !id.APIKey.HasScope(scope) {
+				writeError(w, http.StatusForbidden, "api key lacks scope "+string(scope))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// DenyAPIKeys rejects requests made with an API key with 403. It guards routes that no scope covers.
+func DenyAPIKeys(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if id, ok := model.IdentityFrom(r.Context()); ok && id.APIKey != nil {
+			writeError(w, http.StatusForbidden, "route is not available to api keys")
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
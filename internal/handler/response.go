@@ -2,15 +2,39 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/service"
 )
 
-// errorResponse represents the standard JSON structure for returning API errors.
-type errorResponse struct {
-	Error string `json:"error"`
+// problemResponse is an RFC 7807 problem details document returned for every API error.
+// Errors lists the offending input fields of validation problems; ConflictingID names the subscription
+// an overlapping subscription conflicts with.
+type problemResponse struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Errors        []fieldProblem `json:"errors,omitempty"`
+	ConflictingID *uuid.UUID     `json:"conflicting_id,omitempty"`
+}
+
+// fieldProblem describes why a single input field was rejected.
+type fieldProblem struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
+// problemContentType is the media type of problem details documents.
+const problemContentType = "application/problem+json"
+
 // writeJSON sends a JSON response with a specific HTTP status code and marshals the provided payload.
 func writeJSON(w http.ResponseWriter, status int, payload any) {
 	w.Header().Set("Content-Type", "application/json")
@@ -23,8 +47,141 @@ func writeJSON(w http.ResponseWriter, status int, payload any) {
 	}
 }
 
-// writeError logs the error message and sends a standardized JSON error response to the client.
+// writeProblem logs the problem and sends it as an application/problem+json response.
+func writeProblem(w http.ResponseWriter, p problemResponse) {
+	log.Printf("ERROR: %d %s", p.Status, p.Detail)
+
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+	if p.Title == "" {
+		p.Title = http.StatusText(p.Status)
+	}
+
+	w.Header().Set("Content-Type", problemContentType)
+	w.WriteHeader(p.Status)
+
+	if err := json.NewEncoder(w).Encode(p); err != nil {
+		log.Printf("ERROR: failed to write response: %v", err)
+	}
+}
+
+// writeError sends a problem with the given status and detail message.
 func writeError(w http.ResponseWriter, status int, msg string) {
-	log.Printf("ERROR: %s", msg)
-	writeJSON(w, status, errorResponse{Error: msg})
+	writeProblem(w, problemResponse{Status: status, Detail: msg})
+}
+
+// writeValidationError sends a 400 problem for a request that failed input validation.
+func writeValidationError(w http.ResponseWriter, err error) {
+	writeProblem(w, validationProblem(err))
+}
+
+// validationProblem describes an input validation failure as a 400 problem, listing the offending fields
+// of validator errors and model.FieldError.
+func validationProblem(err error) problemResponse {
+	p := problemResponse{Status: http.StatusBadRequest, Detail: "request validation failed"}
+
+	var fe *model.FieldError
+	if errors.As(err, &fe) {
+		p.Detail = fe.Error()
+		p.Errors = []fieldProblem{{Field: fe.Field, Message: fe.Message}}
+	}
+
+	for _, fe := range model.FieldErrors(err) {
+		p.Errors = append(p.Errors, fieldProblem{Field: fe.Field, Message: fe.Message})
+	}
+
+	return p
+}
+
+// kindStatus maps domain error kinds to HTTP status codes.
+var kindStatus = map[service.Kind]int{
+	service.KindValidation:   http.StatusBadRequest,
+	service.KindNotFound:     http.StatusNotFound,
+	service.KindConflict:     http.StatusConflict,
+	service.KindForbidden:    http.StatusForbidden,
+	service.KindPrecondition: http.StatusPreconditionFailed,
+}
+
+// writeServiceError sends the problem matching a service error.
+func writeServiceError(w http.ResponseWriter, err error) {
+	writeProblem(w, serviceProblem(err))
+}
+
+// serviceProblem describes a service error as a problem. Typed domain errors are mapped by kind;
+// any other error is logged and reported as 500 without exposing its details to the client.
+func serviceProblem(err error) problemResponse {
+	var derr *service.Error
+	if !errors.As(err, &derr) {
+		log.Printf("ERROR: internal error: %v", err)
+		return problemResponse{Status: http.StatusInternalServerError, Detail: "internal server error"}
+	}
+
+	status, ok := kindStatus[derr.Kind]
+	if !ok {
+		status = http.StatusInternalServerError
+	}
+
+	p := problemResponse{
+		Status: status,
+		Detail: err.Error(),
+	}
+
+	var fe *model.FieldError
+	switch {
+	case errors.As(err, &fe):
+		p.Errors = []fieldProblem{{Field: fe.Field, Message: fe.Message}}
+	case derr.Field != "":
+		p.Errors = []fieldProblem{{Field: derr.Field, Message: derr.Error()}}
+	}
+
+	var overlap *service.OverlapError
+	if errors.As(err, &overlap) && overlap.ConflictingID != uuid.Nil {
+		p.ConflictingID = &overlap.ConflictingID
+	}
+
+	return p
+}
+
+// formatETag returns the strong entity tag for a subscription version.
+func formatETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// parseIfMatch extracts the expected version from the If-Match header.
+// It returns a nil version when the header is absent or "*", and ok=false when the header
+// cannot be satisfied by a single version (malformed, weak or listing several entity tags).
+// If-Match uses strong comparison (RFC 9110, section 13.1.1), so a weak tag never matches.
+func parseIfMatch(r *http.Request) (version *int, ok bool) {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return nil, true
+	}
+
+	tag := header
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return nil, false
+	}
+
+	v, err := strconv.Atoi(tag[1 : len(tag)-1])
+	if err != nil {
+		return nil, false
+	}
+
+	return &v, true
+}
+
+// parseAllowOverlap reads the allow_overlap query parameter, which lets a write create or keep a subscription
+// overlapping another one to the same service. It returns nil when the parameter is absent.
+func parseAllowOverlap(r *http.Request) (*bool, error) {
+	value := r.URL.Query().Get("allow_overlap")
+	if value == "" {
+		return nil, nil
+	}
+
+	allow, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return &allow, nil
 }
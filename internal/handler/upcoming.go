@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"subscription-service/internal/model"
+)
+
+// defaultUpcomingDays is the window of the upcoming charges endpoint when within is not given.
+const defaultUpcomingDays = 30
+
+// parseWithin parses a look-ahead window given in days, either as "30d" or as a bare number.
+// An empty value yields defaultUpcomingDays.
+func parseWithin(v string) (int, error) {
+	if v == "" {
+		return defaultUpcomingDays, nil
+	}
+
+	days, err := strconv.Atoi(strings.TrimSuffix(v, "d"))
+	if err != nil {
+		return 0, errors.New("invalid within: expected a number of days such as 30d")
+	}
+
+	return days, nil
+}
+
+// Upcoming godoc
+// @Summary Upcoming charges
+// @Description List the charges of a user's subscriptions from today until the end of the window, ordered by date. Charge dates follow the billing period from the start date and stop after the end month; amounts use the price in force in the month of the charge, including scheduled price changes
+// @Tags subscriptions
+// @Produce json
+// @Param user_id path string true "User ID" format(uuid)
+// @Param within query string false "Window in days, at most 366d (default 30d)" example("30d")
+// @Success 200 {object} model.UpcomingResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /users/{user_id}/upcoming [get]
+// @Security BearerAuth
+// @Security ApiKeyAuth
+func (h *SubscriptionHandler) Upcoming(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid user_id")
+		return
+	}
+
+	days, err := parseWithin(r.URL.Query().Get("within"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	upcoming, err := h.service.Upcoming(r.Context(), userID, days)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, model.ToUpcomingResponse(upcoming))
+}
@@ -0,0 +1,274 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/service"
+)
+
+// WebhookHandler manages HTTP communication for webhook endpoints.
+type WebhookHandler struct {
+	service service.WebhookService
+}
+
+// NewWebhookHandler initializes a new handler with the provided webhook service.
+func NewWebhookHandler(s service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: s}
+}
+
+// Create godoc
+// @Summary Register webhook
+// @Description Register an endpoint notified of subscription lifecycle events (all of them when events is empty). Every delivery is a signed JSON POST; the signing secret is generated unless given and is only returned in this response
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body model.WebhookRequest true "Webhook data"
+// @Success 201 {object} model.CreatedWebhookResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Router /webhooks [post]
+// @Security BearerAuth
+func (h *WebhookHandler) Create(w http.ResponseWriter, r *http.Request) {
+	var req model.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := model.Validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	hook := model.ToWebhook(req)
+	if err := h.service.Create(r.Context(), hook); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, model.CreatedWebhookResponse{
+		WebhookResponse: model.ToWebhookResponse(hook),
+		Secret:          hook.Secret,
+	})
+}
+
+// Get godoc
+// @Summary Get webhook
+// @Description Get a webhook by ID, without its secret
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID" format(uuid)
+// @Success 200 {object} model.WebhookResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /webhooks/{id} [get]
+// @Security BearerAuth
+func (h *WebhookHandler) Get(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	hook, err := h.service.Get(r.Context(), id)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, model.ToWebhookResponse(hook))
+}
+
+// List godoc
+// @Summary List webhooks
+// @Description List all webhooks without their secrets
+// @Tags webhooks
+// @Produce json
+// @Success 200 {array} model.WebhookResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Router /webhooks [get]
+// @Security BearerAuth
+func (h *WebhookHandler) List(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.service.List(r.Context())
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := make([]model.WebhookResponse, 0, len(hooks))
+	for _, hook := range hooks {
+		resp = append(resp, model.ToWebhookResponse(hook))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Update godoc
+// @Summary Update webhook
+// @Description Replace the URL, event filter and state of a webhook. An empty secret keeps the current one
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path string true "Webhook ID" format(uuid)
+// @Param webhook body model.WebhookRequest true "Webhook data"
+// @Success 200 {object} model.WebhookResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /webhooks/{id} [put]
+// @Security BearerAuth
+func (h *WebhookHandler) Update(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	var req model.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := model.Validate.Struct(req); err != nil {
+		writeValidationError(w, err)
+		return
+	}
+
+	hook := model.ToWebhook(req)
+	hook.ID = id
+	if err := h.service.Update(r.Context(), hook); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, model.ToWebhookResponse(hook))
+}
+
+// Delete godoc
+// @Summary Delete webhook
+// @Description Delete a webhook together with its deliveries
+// @Tags webhooks
+// @Param id path string true "Webhook ID" format(uuid)
+// @Success 204
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /webhooks/{id} [delete]
+// @Security BearerAuth
+func (h *WebhookHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	if err := h.service.Delete(r.Context(), id); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Deliveries godoc
+// @Summary List webhook deliveries
+// @Description List the deliveries of a webhook, newest first. Deliveries that ran out of attempts have the status dead (dead-letter queue)
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID" format(uuid)
+// @Param status query string false "Delivery status" Enums(pending, delivered, dead)
+// @Param limit query int false "Limit"
+// @Param offset query int false "Offset"
+// @Success 200 {array} model.WebhookDeliveryResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /webhooks/{id}/deliveries [get]
+// @Security BearerAuth
+func (h *WebhookHandler) Deliveries(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	params := r.URL.Query()
+
+	var status *string
+	if v := params.Get("status"); v != "" {
+		status = &v
+	}
+
+	limit, _ := strconv.Atoi(params.Get("limit"))
+	offset, _ := strconv.Atoi(params.Get("offset"))
+
+	deliveries, err := h.service.ListDeliveries(r.Context(), id, status, limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	resp := make([]model.WebhookDeliveryResponse, 0, len(deliveries))
+	for _, d := range deliveries {
+		resp = append(resp, model.ToWebhookDeliveryResponse(d))
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Redeliver godoc
+// @Summary Redeliver webhook event
+// @Description Schedule a delivery, typically a dead-lettered one, for a new series of attempts starting right away
+// @Tags webhooks
+// @Produce json
+// @Param id path string true "Webhook ID" format(uuid)
+// @Param delivery_id path string true "Delivery ID" format(uuid)
+// @Success 202 {object} model.WebhookDeliveryResponse
+// @Failure 400 {object} handler.problemResponse
+// @Failure 404 {object} handler.problemResponse
+// @Failure 500 {object} handler.problemResponse
+// @Failure 401 {object} handler.problemResponse
+// @Failure 403 {object} handler.problemResponse
+// @Router /webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+// @Security BearerAuth
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid id")
+		return
+	}
+
+	deliveryID, err := uuid.Parse(chi.URLParam(r, "delivery_id"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid delivery_id")
+		return
+	}
+
+	delivery, err := h.service.Redeliver(r.Context(), id, deliveryID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeJSON(w, http.StatusAccepted, model.ToWebhookDeliveryResponse(delivery))
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"subscription-service/internal/repository"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// businessQueryTimeout bounds the queries run on a scrape, well below the usual scrape timeout.
+const businessQueryTimeout = 5 * time.Second
+
+// businessCollector exports figures about the subscriptions themselves.
+type businessCollector struct {
+	stats repository.StatsRepository
+
+	activeSubscriptions *prometheus.Desc
+	activeUsers         *prometheus.Desc
+}
+
+// NewBusinessCollector creates a collector querying stats on every scrape. When the query fails, the error is
+// logged and the business metrics are left out of the scrape.
+func NewBusinessCollector(stats repository.StatsRepository) prometheus.Collector {
+	return &businessCollector{
+		stats: stats,
+
+		activeSubscriptions: prometheus.NewDesc("subscriptions_active",
+			"Number of subscriptions in effect in the current month.", nil, nil),
+		activeUsers: prometheus.NewDesc("subscription_users_active",
+			"Number of users with a subscription in effect in the current month.", nil, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *businessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.activeSubscriptions
+	ch <- c.activeUsers
+}
+
+// Collect implements prometheus.Collector.
+func (c *businessCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), businessQueryTimeout)
+	defer cancel()
+
+	stats, err := c.stats.Active(ctx, time.Now())
+	if err != nil {
+		log.Printf("ERROR: failed to collect business metrics: %v", err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.activeSubscriptions, prometheus.GaugeValue, float64(stats.Subscriptions))
+	ch <- prometheus.MustNewConstMetric(c.activeUsers, prometheus.GaugeValue, float64(stats.Users))
+}
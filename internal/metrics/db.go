@@ -0,0 +1,155 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"subscription-service/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// poolCollector exports the statistics of a pgx connection pool.
+type poolCollector struct {
+	pool *pgxpool.Pool
+
+	acquiredConns *prometheus.Desc
+	idleConns     *prometheus.Desc
+	totalConns    *prometheus.Desc
+	maxConns      *prometheus.Desc
+	acquires      *prometheus.Desc
+	emptyAcquires *prometheus.Desc
+	acquireWait   *prometheus.Desc
+}
+
+// NewPoolCollector creates a collector reading the statistics of pool on every scrape.
+func NewPoolCollector(pool *pgxpool.Pool) prometheus.Collector {
+	return &poolCollector{
+		pool: pool,
+
+		acquiredConns: prometheus.NewDesc("db_pool_acquired_conns",
+			"Number of connections currently acquired from the pool.", nil, nil),
+		idleConns: prometheus.NewDesc("db_pool_idle_conns",
+			"Number of idle connections in the pool.", nil, nil),
+		totalConns: prometheus.NewDesc("db_pool_total_conns",
+			"Number of connections in the pool, acquired, idle and being established.", nil, nil),
+		maxConns: prometheus.NewDesc("db_pool_max_conns",
+			"Maximum size of the pool.", nil, nil),
+		acquires: prometheus.NewDesc("db_pool_acquires_total",
+			"Number of successful connection acquisitions.", nil, nil),
+		emptyAcquires: prometheus.NewDesc("db_pool_empty_acquires_total",
+			"Number of acquisitions that waited for a connection because the pool was empty.", nil, nil),
+		acquireWait: prometheus.NewDesc("db_pool_acquire_wait_seconds_total",
+			"Time spent waiting for a connection by acquisitions from an empty pool.", nil, nil),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.acquiredConns
+	ch <- c.idleConns
+	ch <- c.totalConns
+	ch <- c.maxConns
+	ch <- c.acquires
+	ch <- c.emptyAcquires
+	ch <- c.acquireWait
+}
+
+// Collect implements prometheus.Collector.
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	stat := c.pool.Stat()
+
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(stat.AcquiredConns()))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(stat.IdleConns()))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(stat.TotalConns()))
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(stat.MaxConns()))
+	ch <- prometheus.MustNewConstMetric(c.acquires, prometheus.CounterValue, float64(stat.AcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquires, prometheus.CounterValue, float64(stat.EmptyAcquireCount()))
+	ch <- prometheus.MustNewConstMetric(c.acquireWait, prometheus.CounterValue, stat.EmptyAcquireWaitTime().Seconds())
+}
+
+// otherMethod labels queries not issued by a repository method.
+const otherMethod = "other"
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	method string
+	at     time.Time
+}
+
+// QueryTracer is a pgx tracer observing the duration of database queries by the repository method that issued
+// them, such as "subscriptionRepo.Create". Batches and copies are observed as one query.
+type QueryTracer struct {
+	duration *prometheus.HistogramVec
+}
+
+// NewQueryTracer creates a query tracer to be set on the connection configuration of the pool.
+func NewQueryTracer() *QueryTracer {
+	return &QueryTracer{
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "db_query_duration_seconds",
+			Help:    "Duration of database queries by repository method.",
+			Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"method"}),
+	}
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return t.start(ctx)
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryEndData) {
+	t.end(ctx)
+}
+
+// TraceBatchStart implements pgx.BatchTracer.
+func (t *QueryTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchStartData) context.Context {
+	return t.start(ctx)
+}
+
+// TraceBatchQuery implements pgx.BatchTracer.
+func (t *QueryTracer) TraceBatchQuery(context.Context, *pgx.Conn, pgx.TraceBatchQueryData) {}
+
+// TraceBatchEnd implements pgx.BatchTracer.
+func (t *QueryTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, _ pgx.TraceBatchEndData) {
+	t.end(ctx)
+}
+
+// TraceCopyFromStart implements pgx.CopyFromTracer.
+func (t *QueryTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceCopyFromStartData) context.Context {
+	return t.start(ctx)
+}
+
+// TraceCopyFromEnd implements pgx.CopyFromTracer.
+func (t *QueryTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, _ pgx.TraceCopyFromEndData) {
+	t.end(ctx)
+}
+
+func (t *QueryTracer) start(ctx context.Context) context.Context {
+	method := repository.QueryName(ctx)
+	if method == "" {
+		method = otherMethod
+	}
+	return context.WithValue(ctx, queryStartKey{}, queryStart{method: method, at: time.Now()})
+}
+
+func (t *QueryTracer) end(ctx context.Context) {
+	if start, ok := ctx.Value(queryStartKey{}).(queryStart); ok {
+		t.duration.WithLabelValues(start.method).Observe(time.Since(start.at).Seconds())
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (t *QueryTracer) Describe(ch chan<- *prometheus.Desc) {
+	t.duration.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (t *QueryTracer) Collect(ch chan<- prometheus.Metric) {
+	t.duration.Collect(ch)
+}
@@ -0,0 +1,122 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"subscription-service/internal/metrics"
+	"subscription-service/internal/model"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPMetrics(t *testing.T) {
+	httpMetrics := metrics.NewHTTPMetrics()
+
+	r := chi.NewRouter()
+	r.Use(httpMetrics.Middleware)
+	r.Get("/subscriptions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if chi.URLParam(r, "id") == "missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("{}"))
+	})
+
+	for _, path := range []string{"/subscriptions/1", "/subscriptions/2", "/subscriptions/missing", "/unknown"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	expected := `
+		# HELP http_requests_total Number of HTTP requests handled.
+		# TYPE http_requests_total counter
+		http_requests_total{method="GET",route="/subscriptions/{id}",status="200"} 2
+		http_requests_total{method="GET",route="/subscriptions/{id}",status="404"} 1
+		http_requests_total{method="GET",route="unmatched",status="404"} 1
+	`
+	assert.NoError(t, testutil.CollectAndCompare(httpMetrics, strings.NewReader(expected), "http_requests_total"))
+	assert.Equal(t, 3, testutil.CollectAndCount(httpMetrics, "http_request_duration_seconds"))
+}
+
+func TestPoolCollector(t *testing.T) {
+	// The pool connects lazily, so its statistics are available without a server.
+	pool, err := pgxpool.New(context.Background(), "postgres://user@127.0.0.1:1/db?pool_max_conns=4")
+	require.NoError(t, err)
+	defer pool.Close()
+
+	collector := metrics.NewPoolCollector(pool)
+
+	expected := `
+		# HELP db_pool_max_conns Maximum size of the pool.
+		# TYPE db_pool_max_conns gauge
+		db_pool_max_conns 4
+	`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected), "db_pool_max_conns"))
+	assert.Equal(t, 7, testutil.CollectAndCount(collector))
+
+	problems, err := testutil.CollectAndLint(collector)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
+
+func TestQueryTracer(t *testing.T) {
+	tracer := metrics.NewQueryTracer()
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	ctx = tracer.TraceBatchStart(context.Background(), nil, pgx.TraceBatchStartData{})
+	tracer.TraceBatchEnd(ctx, nil, pgx.TraceBatchEndData{})
+
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(tracer))
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	// Queries issued outside the repository package are labelled "other".
+	require.Len(t, families, 1)
+	require.Len(t, families[0].GetMetric(), 1)
+	series := families[0].GetMetric()[0]
+	assert.Equal(t, "other", series.GetLabel()[0].GetValue())
+	assert.Equal(t, uint64(2), series.GetHistogram().GetSampleCount())
+
+	problems, err := testutil.CollectAndLint(tracer)
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+}
+
+type fakeStats struct {
+	stats *model.ActiveStats
+	err   error
+}
+
+func (f fakeStats) Active(context.Context, time.Time) (*model.ActiveStats, error) {
+	return f.stats, f.err
+}
+
+func TestBusinessCollector(t *testing.T) {
+	collector := metrics.NewBusinessCollector(fakeStats{stats: &model.ActiveStats{Subscriptions: 12, Users: 5}})
+
+	expected := `
+		# HELP subscriptions_active Number of subscriptions in effect in the current month.
+		# TYPE subscriptions_active gauge
+		subscriptions_active 12
+		# HELP subscription_users_active Number of users with a subscription in effect in the current month.
+		# TYPE subscription_users_active gauge
+		subscription_users_active 5
+	`
+	assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+
+	failing := metrics.NewBusinessCollector(fakeStats{err: errors.New("database is down")})
+	assert.Zero(t, testutil.CollectAndCount(failing))
+}
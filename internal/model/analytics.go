@@ -0,0 +1,60 @@
+package model
+
+// CategorySpend is the cost (in minor units of the analytics currency) of the subscriptions of one catalog
+// category and its share of the total spend in percent. Category is empty for subscriptions that are not linked
+// to a categorised catalog entry.
+type CategorySpend struct {
+	Category string
+	Total    int
+	Share    float64
+}
+
+// CategoryAnalytics holds the total spend of an aggregation window in Currency and its breakdown by category,
+// most expensive category first.
+type CategoryAnalytics struct {
+	Total      int
+	Currency   string
+	Categories []CategorySpend
+}
+
+// CategorySpendResponse represents the spend of a single category returned to API clients.
+// Category is null for uncategorised subscriptions; Share is a percentage of the total rounded to 2 decimals.
+type CategorySpendResponse struct {
+	Category *string `json:"category" extensions:"x-order=1"`
+	Total    int     `json:"total" extensions:"x-order=2"`
+	Share    float64 `json:"share" extensions:"x-order=3"`
+}
+
+// CategoryAnalyticsResponse represents the spend per category returned to API clients.
+type CategoryAnalyticsResponse struct {
+	Total      int                     `json:"total" extensions:"x-order=1"`
+	Currency   string                  `json:"currency,omitempty" extensions:"x-order=2"`
+	Categories []CategorySpendResponse `json:"categories" extensions:"x-order=3"`
+}
+
+// ToCategoryAnalyticsResponse converts CategoryAnalytics into a CategoryAnalyticsResponse DTO.
+func ToCategoryAnalyticsResponse(analytics *CategoryAnalytics) CategoryAnalyticsResponse {
+	resp := CategoryAnalyticsResponse{
+		Total:      analytics.Total,
+		Currency:   analytics.Currency,
+		Categories: make([]CategorySpendResponse, 0, len(analytics.Categories)),
+	}
+
+	for _, c := range analytics.Categories {
+		spend := CategorySpendResponse{Total: c.Total, Share: c.Share}
+		if c.Category != "" {
+			category := c.Category
+			spend.Category = &category
+		}
+		resp.Categories = append(resp.Categories, spend)
+	}
+
+	return resp
+}
+
+// ActiveStats counts the subscriptions in effect in a month (not deleted, started and not ended before it)
+// and the users holding them.
+type ActiveStats struct {
+	Subscriptions int64
+	Users         int64
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Scope is a permission granted to an API key. Each API route requires one scope.
+type Scope string
+
+// Scopes that can be granted to API keys.
+const (
+	ScopeSubscriptionsRead  Scope = "subscriptions:read"
+	ScopeSubscriptionsWrite Scope = "subscriptions:write"
+	ScopeSummaryRead        Scope = "summary:read"
+)
+
+// IsValid reports whether the scope is one of the known scopes.
+func (s Scope) IsValid() bool {
+	switch s {
+	case ScopeSubscriptionsRead, ScopeSubscriptionsWrite, ScopeSummaryRead:
+		return true
+	}
+	return false
+}
+
+// APIKeyPrefixLength is the number of leading characters of a key kept in clear text to identify it.
+const APIKeyPrefixLength = 8
+
+// APIKey is a credential for service-to-service access. Only the SHA-256 hash of the secret is stored;
+// Prefix holds its first characters so that keys can be told apart in listings.
+type APIKey struct {
+	ID         uuid.UUID
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     []Scope
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// HasScope reports whether the key grants the scope.
+func (k *APIKey) HasScope(scope Scope) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// APIKeyRequest defines the schema for minting an API key.
+type APIKeyRequest struct {
+	Name   string  `json:"name" validate:"required,min=2" extensions:"x-order=1"`
+	Scopes []Scope `json:"scopes" validate:"required,min=1,dive,oneof=subscriptions:read subscriptions:write summary:read" extensions:"x-order=2"`
+}
+
+// APIKeyResponse represents an API key returned to API clients. The secret itself is never included.
+type APIKeyResponse struct {
+	ID         uuid.UUID  `json:"id" extensions:"x-order=0"`
+	Name       string     `json:"name" extensions:"x-order=1"`
+	Prefix     string     `json:"prefix" extensions:"x-order=2"`
+	Scopes     []Scope    `json:"scopes" extensions:"x-order=3"`
+	CreatedAt  time.Time  `json:"created_at" extensions:"x-order=4"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty" extensions:"x-order=5"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" extensions:"x-order=6"`
+}
+
+// CreatedAPIKeyResponse is returned once when a key is minted and is the only response carrying the secret.
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key" extensions:"x-order=7"`
+}
+
+// ToAPIKeyResponse converts an APIKey domain model into an APIKeyResponse DTO.
+func ToAPIKeyResponse(key *APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		CreatedAt:  key.CreatedAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
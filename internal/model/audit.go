@@ -0,0 +1,112 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EventAction names the kind of change recorded in the audit log.
+type EventAction string
+
+// Audited subscription changes.
+const (
+	EventCreated  EventAction = "created"
+	EventUpdated  EventAction = "updated"
+	EventDeleted  EventAction = "deleted"
+	EventRestored EventAction = "restored"
+	EventPurged   EventAction = "purged"
+)
+
+// IsValid reports whether the action is one of the audited changes.
+func (a EventAction) IsValid() bool {
+	switch a {
+	case EventCreated, EventUpdated, EventDeleted, EventRestored, EventPurged:
+		return true
+	}
+	return false
+}
+
+// AnonymousActor is recorded when a change is made without an identified caller.
+const AnonymousActor = "anonymous"
+
+// SubscriptionEvent is an audit log entry describing a single change of a subscription.
+// Before and After hold the subscription as returned by the API (SubscriptionResponse) before and
+// after the change; Before is empty for created events and After is empty for purged events.
+type SubscriptionEvent struct {
+	ID             int64
+	SubscriptionID uuid.UUID
+	UserID         uuid.UUID
+	Action         EventAction
+	Actor          string
+	RequestID      string
+	Before         json.RawMessage
+	After          json.RawMessage
+	CreatedAt      time.Time
+}
+
+// AuditQuery describes a filtered, paginated read of the audit log. Nil filters are not applied;
+// From is inclusive and To is exclusive.
+type AuditQuery struct {
+	SubscriptionID *uuid.UUID
+	UserID         *uuid.UUID
+	Actor          *string
+	Action         *EventAction
+	From           *time.Time
+	To             *time.Time
+	Limit          int
+	Offset         int
+}
+
+// SubscriptionEventResponse represents an audit log entry returned to API clients.
+type SubscriptionEventResponse struct {
+	ID             int64           `json:"id" extensions:"x-order=1"`
+	SubscriptionID uuid.UUID       `json:"subscription_id" extensions:"x-order=2"`
+	UserID         uuid.UUID       `json:"user_id" extensions:"x-order=3"`
+	Action         string          `json:"action" example:"updated" extensions:"x-order=4"`
+	Actor          string          `json:"actor" example:"support@example.com" extensions:"x-order=5"`
+	RequestID      string          `json:"request_id,omitempty" extensions:"x-order=6"`
+	Before         json.RawMessage `json:"before,omitempty" swaggertype:"object" extensions:"x-order=7"`
+	After          json.RawMessage `json:"after,omitempty" swaggertype:"object" extensions:"x-order=8"`
+	CreatedAt      time.Time       `json:"created_at" extensions:"x-order=9"`
+}
+
+// ToEventResponse converts a SubscriptionEvent domain model into a SubscriptionEventResponse DTO.
+func ToEventResponse(e *SubscriptionEvent) SubscriptionEventResponse {
+	return SubscriptionEventResponse{
+		ID:             e.ID,
+		SubscriptionID: e.SubscriptionID,
+		UserID:         e.UserID,
+		Action:         string(e.Action),
+		Actor:          e.Actor,
+		RequestID:      e.RequestID,
+		Before:         e.Before,
+		After:          e.After,
+		CreatedAt:      e.CreatedAt,
+	}
+}
+
+// auditInfoKey is the context key under which AuditInfo is stored.
+type auditInfoKey struct{}
+
+// AuditInfo identifies who made a change and within which request.
+type AuditInfo struct {
+	Actor     string
+	RequestID string
+}
+
+// WithAuditInfo returns a copy of ctx carrying the actor and request ID recorded in the audit log.
+func WithAuditInfo(ctx context.Context, info AuditInfo) context.Context {
+	return context.WithValue(ctx, auditInfoKey{}, info)
+}
+
+// AuditInfoFrom returns the audit information stored in ctx. The actor defaults to AnonymousActor.
+func AuditInfoFrom(ctx context.Context) AuditInfo {
+	info, _ := ctx.Value(auditInfoKey{}).(AuditInfo)
+	if info.Actor == "" {
+		info.Actor = AnonymousActor
+	}
+	return info
+}
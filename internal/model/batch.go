@@ -0,0 +1,30 @@
+package model
+
+import "github.com/google/uuid"
+
+// MaxBatchSize is the maximum number of items accepted by a single batch request.
+const MaxBatchSize = 100
+
+// BatchCreateRequest defines the schema for creating several subscriptions at once.
+// Items are validated one by one so that each failure can be reported against its index.
+type BatchCreateRequest struct {
+	Items []CreateSubscriptionRequest `json:"items" validate:"required,min=1,max=100"`
+}
+
+// BatchUpdateItem replaces the subscription with the given ID. Version is the optional expected version,
+// the batch counterpart of the If-Match header.
+type BatchUpdateItem struct {
+	ID      uuid.UUID `json:"id" validate:"required" extensions:"x-order=0"`
+	Version *int      `json:"version,omitempty" extensions:"x-order=8"`
+	CreateSubscriptionRequest
+}
+
+// BatchUpdateRequest defines the schema for replacing several subscriptions at once.
+type BatchUpdateRequest struct {
+	Items []BatchUpdateItem `json:"items" validate:"required,min=1,max=100"`
+}
+
+// BatchDeleteRequest defines the schema for moving several subscriptions to the trash at once.
+type BatchDeleteRequest struct {
+	IDs []uuid.UUID `json:"ids" validate:"required,min=1,max=100"`
+}
@@ -0,0 +1,87 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Service is an entry of the service catalog. Subscriptions whose service name matches the canonical Name or one
+// of the Aliases (compared with NormalizeServiceName) are linked to it and stored under the canonical name.
+// Aliases are kept normalized. DefaultPrice, if set, is the usual price in minor units of Currency.
+type Service struct {
+	ID           uuid.UUID
+	Name         string
+	Aliases      []string
+	Category     string
+	DefaultPrice *int
+	Currency     string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// Names returns the normalized canonical name followed by the aliases that differ from it, without repetitions.
+func (s *Service) Names() []string {
+	names := []string{NormalizeServiceName(s.Name)}
+	for _, alias := range s.Aliases {
+		if alias = NormalizeServiceName(alias); alias != "" && !slices.Contains(names, alias) {
+			names = append(names, alias)
+		}
+	}
+	return names
+}
+
+// NormalizeCategory returns the form under which categories are stored and compared: trimmed and lower-cased.
+func NormalizeCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+// ServiceRequest defines the schema for creating or replacing a catalog entry.
+// DefaultPrice is given in minor units of Currency; Currency is optional and defaults to "RUB".
+type ServiceRequest struct {
+	Name         string   `json:"name" validate:"required,min=2" extensions:"x-order=1"`
+	Aliases      []string `json:"aliases,omitempty" validate:"omitempty,dive,min=2" extensions:"x-order=2"`
+	Category     string   `json:"category,omitempty" extensions:"x-order=3"`
+	DefaultPrice *int     `json:"default_price,omitempty" validate:"omitempty,min=0" extensions:"x-order=4"`
+	Currency     string   `json:"currency,omitempty" validate:"omitempty,iso4217" extensions:"x-order=5"`
+}
+
+// ServiceResponse represents a catalog entry returned to API clients.
+type ServiceResponse struct {
+	ID           uuid.UUID `json:"id" extensions:"x-order=0"`
+	Name         string    `json:"name" extensions:"x-order=1"`
+	Aliases      []string  `json:"aliases" extensions:"x-order=2"`
+	Category     string    `json:"category,omitempty" extensions:"x-order=3"`
+	DefaultPrice *int      `json:"default_price,omitempty" extensions:"x-order=4"`
+	Currency     string    `json:"currency" extensions:"x-order=5"`
+}
+
+// ToService transforms a ServiceRequest into a Service domain model.
+func ToService(req ServiceRequest) *Service {
+	return &Service{
+		Name:         req.Name,
+		Aliases:      req.Aliases,
+		Category:     req.Category,
+		DefaultPrice: req.DefaultPrice,
+		Currency:     req.Currency,
+	}
+}
+
+// ToServiceResponse converts a Service domain model into a ServiceResponse DTO.
+func ToServiceResponse(svc *Service) ServiceResponse {
+	aliases := svc.Aliases
+	if aliases == nil {
+		aliases = []string{}
+	}
+
+	return ServiceResponse{
+		ID:           svc.ID,
+		Name:         svc.Name,
+		Aliases:      aliases,
+		Category:     svc.Category,
+		DefaultPrice: svc.DefaultPrice,
+		Currency:     svc.Currency,
+	}
+}
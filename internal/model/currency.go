@@ -0,0 +1,54 @@
+package model
+
+import (
+	"math"
+	"time"
+)
+
+// currencyExponents lists ISO 4217 currencies whose minor unit is not 1/100 of the major unit.
+var currencyExponents = map[string]int{
+	"BIF": 0, "CLP": 0, "DJF": 0, "GNF": 0, "ISK": 0, "JPY": 0, "KMF": 0, "KRW": 0,
+	"PYG": 0, "RWF": 0, "UGX": 0, "UYI": 0, "VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+	"BHD": 3, "IQD": 3, "JOD": 3, "KWD": 3, "LYD": 3, "OMR": 3, "TND": 3,
+}
+
+// CurrencyExponent returns the number of decimal digits of the currency's minor unit (2 for most currencies).
+func CurrencyExponent(code string) int {
+	if exp, ok := currencyExponents[code]; ok {
+		return exp
+	}
+	return 2
+}
+
+// ConvertMinor converts an amount in minor units of one currency into minor units of another
+// using a rate expressed in major units (1 from = rate to). The result is not rounded.
+func ConvertMinor(amount float64, from, to string, rate float64) float64 {
+	return amount * rate * math.Pow10(CurrencyExponent(to)-CurrencyExponent(from))
+}
+
+// ToCurrencyRate transforms a CurrencyRateRequest into a CurrencyRate domain model.
+// It parses effective_from from the "MM-YYYY" format.
+func ToCurrencyRate(req CurrencyRateRequest) (*CurrencyRate, error) {
+	effectiveFrom, err := time.Parse("01-2006", req.EffectiveFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	return &CurrencyRate{
+		BaseCurrency:  req.BaseCurrency,
+		QuoteCurrency: req.QuoteCurrency,
+		Rate:          req.Rate,
+		EffectiveFrom: effectiveFrom,
+	}, nil
+}
+
+// ToCurrencyRateResponse converts a CurrencyRate domain model into a CurrencyRateResponse DTO.
+func ToCurrencyRateResponse(rate *CurrencyRate) CurrencyRateResponse {
+	return CurrencyRateResponse{
+		ID:            rate.ID,
+		BaseCurrency:  rate.BaseCurrency,
+		QuoteCurrency: rate.QuoteCurrency,
+		Rate:          rate.Rate,
+		EffectiveFrom: rate.EffectiveFrom.Format("01-2006"),
+	}
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Subscription lifecycle event types, delivered to webhooks and published to the message broker. Every audited
+// change has an event type ("subscription." followed by the audit action); expiry is emitted once a
+// subscription's end month is over.
+const (
+	EventTypeCreated  = "subscription.created"
+	EventTypeUpdated  = "subscription.updated"
+	EventTypeDeleted  = "subscription.deleted"
+	EventTypeRestored = "subscription.restored"
+	EventTypePurged   = "subscription.purged"
+	EventTypeExpired  = "subscription.expired"
+)
+
+// EventTypeOf returns the event type of an audited change.
+func EventTypeOf(action EventAction) string {
+	return "subscription." + string(action)
+}
+
+// Event is a subscription lifecycle event of the event outbox. ID identifies the event across deliveries,
+// redeliveries and republishing so that consumers can ignore duplicates; Data is an EventData.
+type Event struct {
+	ID             int64           `json:"id" extensions:"x-order=1"`
+	Type           string          `json:"type" extensions:"x-order=2"`
+	SubscriptionID uuid.UUID       `json:"subscription_id" extensions:"x-order=3"`
+	CreatedAt      time.Time       `json:"created_at" extensions:"x-order=4"`
+	Data           json.RawMessage `json:"data" swaggertype:"object" extensions:"x-order=5"`
+}
+
+// EventData holds the subscription an event is about as returned by the API; Previous is the state
+// before an update.
+type EventData struct {
+	Subscription SubscriptionResponse  `json:"subscription" extensions:"x-order=1"`
+	Previous     *SubscriptionResponse `json:"previous,omitempty" extensions:"x-order=2"`
+}
@@ -0,0 +1,36 @@
+package model
+
+import "math"
+
+// Export formats accepted by the export endpoint.
+const (
+	ExportCSV   = "csv"
+	ExportJSONL = "jsonl"
+	ExportXLSX  = "xlsx"
+)
+
+// ExportRow is a subscription as written to an export. MonthlyCost is the price spread evenly over
+// months (in minor units of Currency), so that subscriptions billed in different periods can be compared.
+type ExportRow struct {
+	SubscriptionResponse
+	MonthlyCost int `json:"monthly_cost" extensions:"x-order=10"`
+}
+
+// MonthlyCost returns the subscription price spread evenly over months according to its billing period,
+// rounded to the nearest minor unit, the same way the amortised cost summary spreads it.
+func (s *Subscription) MonthlyCost() int {
+	switch s.BillingPeriod {
+	case BillingQuarterly:
+		return int(math.Round(float64(s.Price) / 3))
+	case BillingYearly:
+		return int(math.Round(float64(s.Price) / 12))
+	case BillingWeekly:
+		return int(math.Round(float64(s.Price) * 52 / 12))
+	}
+	return s.Price
+}
+
+// ToExportRow converts a Subscription domain model into an ExportRow.
+func ToExportRow(sub *Subscription) ExportRow {
+	return ExportRow{SubscriptionResponse: ToResponse(sub), MonthlyCost: sub.MonthlyCost()}
+}
@@ -0,0 +1,80 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sortable subscription list fields.
+const (
+	SortCreatedAt   = "created_at"
+	SortPrice       = "price"
+	SortStartDate   = "start_date"
+	SortServiceName = "service_name"
+)
+
+// ErrInvalidSort is returned when the sort parameter names a field that cannot be sorted by.
+var ErrInvalidSort = errors.New("invalid sort: allowed fields are created_at, price, start_date, service_name, optionally prefixed with -")
+
+// Sort orders a subscription list by a single field; ties are broken by ID in the same direction.
+type Sort struct {
+	Field string
+	Desc  bool
+}
+
+// DefaultSort lists the newest subscriptions first.
+var DefaultSort = Sort{Field: SortCreatedAt, Desc: true}
+
+// ParseSort parses "field" (ascending) or "-field" (descending). An empty string yields DefaultSort.
+func ParseSort(s string) (Sort, error) {
+	if s == "" {
+		return DefaultSort, nil
+	}
+
+	sort := Sort{Field: strings.TrimPrefix(s, "-"), Desc: strings.HasPrefix(s, "-")}
+	switch sort.Field {
+	case SortCreatedAt, SortPrice, SortStartDate, SortServiceName:
+		return sort, nil
+	}
+	return Sort{}, ErrInvalidSort
+}
+
+// String returns the sort in the form accepted by ParseSort.
+func (s Sort) String() string {
+	if s.Desc {
+		return "-" + s.Field
+	}
+	return s.Field
+}
+
+// SubscriptionFilter selects the subscriptions returned by List. Nil and empty fields are not applied.
+// Month bounds are inclusive and compare the first days of months ("MM-YYYY").
+type SubscriptionFilter struct {
+	// UserIDs matches subscriptions of any of the users.
+	UserIDs []uuid.UUID
+	// ServiceName matches the service name exactly.
+	ServiceName *string
+	// ServiceNamePrefix matches service names starting with the prefix, ignoring case.
+	ServiceNamePrefix *string
+	// ServiceID matches subscriptions linked to the catalog entry.
+	ServiceID *uuid.UUID
+	// Category matches subscriptions linked to a catalog entry of the category.
+	Category *string
+	// MinPrice and MaxPrice bound the current price in minor units.
+	MinPrice *int
+	MaxPrice *int
+	// ActiveAt matches subscriptions active in the month.
+	ActiveAt  *time.Time
+	StartFrom *time.Time
+	StartTo   *time.Time
+	EndFrom   *time.Time
+	EndTo     *time.Time
+	// HasEndDate matches subscriptions with (true) or without (false) an end date.
+	HasEndDate *bool
+	// Deleted lists the trash (soft-deleted subscriptions) instead of live ones.
+	Deleted bool
+	Sort    Sort
+}
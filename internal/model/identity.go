@@ -0,0 +1,33 @@
+package model
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// Identity is the authenticated caller of a request. Subject is the token subject; for regular users it is
+// their user ID, which limits them to their own subscriptions. Admins may act on behalf of any user.
+// APIKey is set for services authenticated with an API key: they act on behalf of any user,
+// but only on the routes covered by the key's scopes.
+type Identity struct {
+	Subject string
+	UserID  uuid.UUID
+	Admin   bool
+	APIKey  *APIKey
+}
+
+// identityKey is the context key under which the Identity is stored.
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying the authenticated caller.
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFrom returns the authenticated caller stored in ctx. ok is false when the request was not
+// authenticated, i.e. authentication is disabled or the call is internal.
+func IdentityFrom(ctx context.Context) (id Identity, ok bool) {
+	id, ok = ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
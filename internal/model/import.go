@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxImportRows is the maximum number of data rows accepted by a single CSV import.
+const MaxImportRows = 1000
+
+// SubscriptionKey identifies a subscription for de-duplication: the same service for the same user
+// starting in the same month is considered the same subscription.
+type SubscriptionKey struct {
+	UserID      uuid.UUID
+	ServiceName string
+	StartDate   time.Time
+}
+
+// KeyOf returns the de-duplication key of a subscription. The start date is truncated to the day in UTC
+// so that keys read from the database and parsed from requests compare equal.
+func KeyOf(sub *Subscription) SubscriptionKey {
+	y, m, d := sub.StartDate.Date()
+	return SubscriptionKey{
+		UserID:      sub.UserID,
+		ServiceName: sub.ServiceName,
+		StartDate:   time.Date(y, m, d, 0, 0, 0, 0, time.UTC),
+	}
+}
@@ -6,35 +6,202 @@ import (
 	"github.com/google/uuid"
 )
 
+// BillingPeriod describes how often the subscription price is charged.
+type BillingPeriod string
+
+// Supported billing periods. Price is always the amount charged once per period.
+const (
+	BillingMonthly   BillingPeriod = "monthly"
+	BillingQuarterly BillingPeriod = "quarterly"
+	BillingYearly    BillingPeriod = "yearly"
+	BillingWeekly    BillingPeriod = "weekly"
+)
+
+// IsValid reports whether the billing period is one of the supported values.
+func (p BillingPeriod) IsValid() bool {
+	switch p {
+	case BillingMonthly, BillingQuarterly, BillingYearly, BillingWeekly:
+		return true
+	}
+	return false
+}
+
+// DefaultCurrency is the ISO 4217 code assumed for subscriptions created without a currency.
+const DefaultCurrency = "RUB"
+
 // Subscription represents the core domain model for a user's service subscription.
+// Price is stored in minor units (kopecks, cents) of Currency and is the price in force in the current month;
+// earlier and scheduled prices are kept as PriceChange entries.
+// Version is incremented on every update and is used for optimistic concurrency control.
+// DeletedAt is set while the subscription is soft-deleted (in the trash).
+// AllowOverlap exempts the subscription from the rule that subscriptions of a user to the same service
+// may not overlap; it is always set on stored subscriptions, and left unset on an update to keep the stored
+// setting. ServiceID links the subscription to its service catalog entry, if its name is catalogued.
 type Subscription struct {
-	ID          uuid.UUID
-	UserID      uuid.UUID
-	ServiceName string
-	Price       int
-	StartDate   time.Time
-	EndDate     *time.Time
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	ServiceName   string
+	ServiceID     *uuid.UUID
+	Price         int
+	Currency      string
+	BillingPeriod BillingPeriod
+	StartDate     time.Time
+	EndDate       *time.Time
+	Version       int
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	DeletedAt     *time.Time
+	AllowOverlap  *bool
 }
 
 // CreateSubscriptionRequest defines the schema for incoming subscription creation or update data.
 // It includes validation tags for business rules like minimum price and date formats.
+// Price is given in minor units of Currency; Currency is optional and defaults to "RUB",
+// BillingPeriod is optional and defaults to "monthly".
 type CreateSubscriptionRequest struct {
-	ServiceName string    `json:"service_name" validate:"required,min=2" extensions:"x-order=1"`
-	Price       int       `json:"price" validate:"required,min=0" extensions:"x-order=2"`
-	UserID      uuid.UUID `json:"user_id" validate:"required" extensions:"x-order=3"`
-	StartDate   string    `json:"start_date" validate:"required,mmYYYY" extensions:"x-order=4"`
-	EndDate     *string   `json:"end_date,omitempty" validate:"omitempty,mmYYYY" extensions:"x-order=5"`
+	ServiceName   string    `json:"service_name" validate:"required,min=2" extensions:"x-order=1"`
+	Price         int       `json:"price" validate:"required,min=0" extensions:"x-order=2"`
+	Currency      string    `json:"currency,omitempty" validate:"omitempty,iso4217" extensions:"x-order=3"`
+	BillingPeriod string    `json:"billing_period,omitempty" validate:"omitempty,oneof=monthly quarterly yearly weekly" extensions:"x-order=4"`
+	UserID        uuid.UUID `json:"user_id" validate:"required" extensions:"x-order=5"`
+	StartDate     string    `json:"start_date" validate:"required,mmYYYY" extensions:"x-order=6"`
+	EndDate       *string   `json:"end_date,omitempty" validate:"omitempty,mmYYYY" extensions:"x-order=7"`
 }
 
 // SubscriptionResponse represents the data structure returned to API clients.
 // It uses strings for dates to ensure consistent formatting across different platforms.
 type SubscriptionResponse struct {
-	ID          uuid.UUID `json:"id" extensions:"x-order=1"`
-	ServiceName string    `json:"service_name" extensions:"x-order=2"`
-	Price       int       `json:"price" extensions:"x-order=3"`
-	UserID      uuid.UUID `json:"user_id" extensions:"x-order=4"`
-	StartDate   string    `json:"start_date" extensions:"x-order=5"`
-	EndDate     *string   `json:"end_date,omitempty" extensions:"x-order=6"`
+	ID            uuid.UUID  `json:"id" extensions:"x-order=1"`
+	ServiceName   string     `json:"service_name" extensions:"x-order=2"`
+	Price         int        `json:"price" extensions:"x-order=3"`
+	Currency      string     `json:"currency" extensions:"x-order=4"`
+	BillingPeriod string     `json:"billing_period" extensions:"x-order=5"`
+	UserID        uuid.UUID  `json:"user_id" extensions:"x-order=6"`
+	StartDate     string     `json:"start_date" extensions:"x-order=7"`
+	EndDate       *string    `json:"end_date,omitempty" extensions:"x-order=8"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty" extensions:"x-order=9"`
+	ServiceID     *uuid.UUID `json:"service_id,omitempty" extensions:"x-order=10"`
+}
+
+// Summary grouping keys accepted by the summary endpoint.
+const (
+	GroupByMonth    = "month"
+	GroupByService  = "service"
+	GroupByUser     = "user"
+	GroupByCategory = "category"
+)
+
+// CostQuery describes a cost aggregation request: optional filters (Category matches subscriptions linked to
+// a catalog entry of the category), the inclusive month window,
+// whether non-monthly prices are amortised, the target currency and the breakdown keys.
+type CostQuery struct {
+	UserID      *uuid.UUID
+	ServiceName *string
+	ServiceID   *uuid.UUID
+	Category    *string
+	From        time.Time
+	To          time.Time
+	Amortize    bool
+	Currency    string
+	GroupBy     []string
+}
+
+// CostBucket represents the aggregated cost (in minor units of Currency) for a single combination
+// of grouping keys. Only the fields corresponding to the requested keys are set. Category is the category
+// of the linked catalog entry and empty for subscriptions without one.
+type CostBucket struct {
+	Period      *time.Time
+	ServiceName *string
+	UserID      *uuid.UUID
+	Category    *string
+	Currency    string
+	Total       int
+}
+
+// CostSummary holds the grand total of an aggregation in Currency together with its optional breakdown.
+type CostSummary struct {
+	Total    int
+	Currency string
+	GroupBy  []string
+	Buckets  []CostBucket
+}
+
+// TotalResponse represents the ungrouped cost summary returned to API clients.
+type TotalResponse struct {
+	Total    int    `json:"total" extensions:"x-order=1"`
+	Currency string `json:"currency,omitempty" extensions:"x-order=2"`
+}
+
+// SummaryBucketResponse represents a single breakdown bucket returned to API clients.
+type SummaryBucketResponse struct {
+	Period      *string    `json:"period,omitempty" extensions:"x-order=1"`
+	ServiceName *string    `json:"service_name,omitempty" extensions:"x-order=2"`
+	UserID      *uuid.UUID `json:"user_id,omitempty" extensions:"x-order=3"`
+	Category    *string    `json:"category,omitempty" extensions:"x-order=4"`
+	Total       int        `json:"total" extensions:"x-order=5"`
+}
+
+// SummaryResponse represents the grouped cost summary returned to API clients.
+type SummaryResponse struct {
+	Total    int                     `json:"total" extensions:"x-order=1"`
+	Currency string                  `json:"currency,omitempty" extensions:"x-order=2"`
+	GroupBy  []string                `json:"group_by" extensions:"x-order=3"`
+	Buckets  []SummaryBucketResponse `json:"buckets" extensions:"x-order=4"`
+}
+
+// CurrencyRate is the conversion rate from BaseCurrency to QuoteCurrency in major units
+// (1 BaseCurrency = Rate QuoteCurrency) effective from the first day of EffectiveFrom's month
+// until the next rate for the same pair.
+type CurrencyRate struct {
+	ID            uuid.UUID
+	BaseCurrency  string
+	QuoteCurrency string
+	Rate          float64
+	EffectiveFrom time.Time
+	CreatedAt     time.Time
+}
+
+// CurrencyRateRequest defines the schema for creating or replacing a currency rate.
+type CurrencyRateRequest struct {
+	BaseCurrency  string  `json:"base_currency" validate:"required,iso4217" extensions:"x-order=1"`
+	QuoteCurrency string  `json:"quote_currency" validate:"required,iso4217,nefield=BaseCurrency" extensions:"x-order=2"`
+	Rate          float64 `json:"rate" validate:"required,gt=0" extensions:"x-order=3"`
+	EffectiveFrom string  `json:"effective_from" validate:"required,mmYYYY" extensions:"x-order=4"`
+}
+
+// CurrencyRateResponse represents a currency rate returned to API clients.
+type CurrencyRateResponse struct {
+	ID            uuid.UUID `json:"id" extensions:"x-order=1"`
+	BaseCurrency  string    `json:"base_currency" extensions:"x-order=2"`
+	QuoteCurrency string    `json:"quote_currency" extensions:"x-order=3"`
+	Rate          float64   `json:"rate" extensions:"x-order=4"`
+	EffectiveFrom string    `json:"effective_from" extensions:"x-order=5"`
+}
+
+// PriceChange is a subscription price (in minor units of the subscription currency) in force from the first
+// day of EffectiveFrom's month until the next price change of the same subscription.
+type PriceChange struct {
+	ID             uuid.UUID
+	SubscriptionID uuid.UUID
+	Price          int
+	EffectiveFrom  time.Time
+	CreatedAt      time.Time
+}
+
+// PriceChangeRequest defines the schema for scheduling a subscription price change.
+type PriceChangeRequest struct {
+	Price         int    `json:"price" validate:"required,min=0" extensions:"x-order=1"`
+	EffectiveFrom string `json:"effective_from" validate:"required,mmYYYY" extensions:"x-order=2"`
+}
+
+// PriceChangeResponse represents an entry of the subscription price history returned to API clients.
+type PriceChangeResponse struct {
+	ID            uuid.UUID `json:"id" extensions:"x-order=1"`
+	Price         int       `json:"price" extensions:"x-order=2"`
+	EffectiveFrom string    `json:"effective_from" extensions:"x-order=3"`
+}
+
+// PurgeResponse reports how many soft-deleted subscriptions were permanently removed.
+type PurgeResponse struct {
+	Purged int64 `json:"purged"`
 }
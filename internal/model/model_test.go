@@ -1,6 +1,7 @@
 package model_test
 
 import (
+	"context"
 	"subscription-service/internal/model"
 	"testing"
 	"time"
@@ -82,6 +83,39 @@ func TestValidateMonthYear(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Success - Yearly billing period",
+			request: model.CreateSubscriptionRequest{
+				ServiceName:   "iCloud",
+				Price:         1200,
+				BillingPeriod: "yearly",
+				UserID:        uuid.New(),
+				StartDate:     "01-2025",
+			},
+			wantErr: false,
+		},
+		{
+			name: "Fail - Unknown billing period",
+			request: model.CreateSubscriptionRequest{
+				ServiceName:   "iCloud",
+				Price:         1200,
+				BillingPeriod: "daily",
+				UserID:        uuid.New(),
+				StartDate:     "01-2025",
+			},
+			wantErr: true,
+		},
+		{
+			name: "Fail - Unknown currency",
+			request: model.CreateSubscriptionRequest{
+				ServiceName: "Netflix",
+				Price:       1000,
+				Currency:    "ABC",
+				UserID:      uuid.New(),
+				StartDate:   "01-2025",
+			},
+			wantErr: true,
+		},
 		{
 			name: "Fail - Invalid EndDate",
 			request: model.CreateSubscriptionRequest{
@@ -129,7 +163,25 @@ func TestToDomain(t *testing.T) {
 		assert.Equal(t, time.October, domain.StartDate.Month())
 		assert.Nil(t, domain.EndDate)
 		assert.True(t, domain.StartDate.Day() == 1)
+		assert.Equal(t, model.BillingMonthly, domain.BillingPeriod)
+		assert.Equal(t, model.DefaultCurrency, domain.Currency)
+
+	})
+
+	t.Run("Keeps explicit billing period", func(t *testing.T) {
+		req := model.CreateSubscriptionRequest{
+			ServiceName:   "iCloud",
+			Price:         1200,
+			BillingPeriod: "quarterly",
+			UserID:        uid,
+			StartDate:     "10-2024",
+		}
+
+		domain, err := model.ToDomain(req)
 
+		assert.NoError(t, err)
+		assert.Equal(t, model.BillingQuarterly, domain.BillingPeriod)
+		assert.Equal(t, "quarterly", model.ToResponse(domain).BillingPeriod)
 	})
 
 	t.Run("Fail on invalid date parsing", func(t *testing.T) {
@@ -141,7 +193,334 @@ func TestToDomain(t *testing.T) {
 	})
 }
 
+// TestConvertMinor verifies conversion between minor units of currencies
+// with different numbers of decimal digits.
+func TestConvertMinor(t *testing.T) {
+	tests := []struct {
+		name     string
+		amount   float64
+		from, to string
+		rate     float64
+		want     float64
+	}{
+		{name: "USD to RUB", amount: 1000, from: "USD", to: "RUB", rate: 90, want: 90000},
+		{name: "RUB to JPY", amount: 10000, from: "RUB", to: "JPY", rate: 1.5, want: 150},
+		{name: "JPY to USD", amount: 1500, from: "JPY", to: "USD", rate: 0.0066, want: 990},
+		{name: "KWD to USD", amount: 1000, from: "KWD", to: "USD", rate: 3.25, want: 325},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.InDelta(t, tt.want, model.ConvertMinor(tt.amount, tt.from, tt.to, tt.rate), 1e-6)
+		})
+	}
+}
+
+// TestParseSubscriptionPatch verifies JSON Merge Patch parsing: absent fields are left unchanged,
+// "end_date": null clears the end date and invalid or immutable fields are rejected.
+func TestParseSubscriptionPatch(t *testing.T) {
+	t.Run("Apply partial patch", func(t *testing.T) {
+		end := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+		sub := &model.Subscription{ServiceName: "Netflix", Price: 100, EndDate: &end}
+
+		patch, err := model.ParseSubscriptionPatch([]byte(`{"price": 250, "end_date": null}`))
+		assert.NoError(t, err)
+		assert.NoError(t, patch.Apply(sub))
+
+		assert.Equal(t, "Netflix", sub.ServiceName)
+		assert.Equal(t, 250, sub.Price)
+		assert.Nil(t, sub.EndDate)
+	})
+
+	t.Run("Set end date", func(t *testing.T) {
+		sub := &model.Subscription{ServiceName: "Netflix"}
+
+		patch, err := model.ParseSubscriptionPatch([]byte(`{"end_date": "03-2026"}`))
+		assert.NoError(t, err)
+		assert.NoError(t, patch.Apply(sub))
+
+		assert.Equal(t, time.March, sub.EndDate.Month())
+		assert.Equal(t, 2026, sub.EndDate.Year())
+	})
+
+	t.Run("Overlap override", func(t *testing.T) {
+		allow := true
+		sub := &model.Subscription{ServiceName: "Netflix", AllowOverlap: &allow}
+
+		patch, err := model.ParseSubscriptionPatch([]byte(`{"price": 300}`))
+		assert.NoError(t, err)
+		assert.NoError(t, patch.Apply(sub))
+		assert.True(t, sub.OverlapAllowed(), "an absent override keeps the stored one")
+
+		deny := false
+		patch.AllowOverlap = &deny
+		assert.NoError(t, patch.Apply(sub))
+		assert.False(t, sub.OverlapAllowed())
+	})
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "Not an object", body: `[1, 2]`},
+		{name: "Immutable user_id", body: `{"user_id": "60601fee-2bf1-4721-ae6f-7636e79a0cba"}`},
+		{name: "Unknown field", body: `{"discount": 10}`},
+		{name: "Null price", body: `{"price": null}`},
+		{name: "Negative price", body: `{"price": -1}`},
+		{name: "Wrong type", body: `{"price": "100"}`},
+		{name: "Invalid date", body: `{"start_date": "2025-01"}`},
+		{name: "Invalid billing period", body: `{"billing_period": "daily"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := model.ParseSubscriptionPatch([]byte(tt.body))
+			assert.Error(t, err)
+		})
+	}
+}
+
+// TestAuditInfo checks that audit information round-trips through the context
+// and that changes without an identified caller are attributed to the anonymous actor.
+func TestAuditInfo(t *testing.T) {
+	info := model.AuditInfoFrom(context.Background())
+	assert.Equal(t, model.AnonymousActor, info.Actor)
+	assert.Empty(t, info.RequestID)
+
+	ctx := model.WithAuditInfo(context.Background(), model.AuditInfo{Actor: "support", RequestID: "req-1"})
+	info = model.AuditInfoFrom(ctx)
+	assert.Equal(t, "support", info.Actor)
+	assert.Equal(t, "req-1", info.RequestID)
+}
+
+// TestCursor checks that cursors survive an encode/decode round trip
+// and that tampered or foreign cursors are rejected.
+func TestCursor(t *testing.T) {
+	c := model.Cursor{Sort: "-created_at", Value: "2025-03-14T15:09:26.535897", ID: uuid.New()}
+
+	decoded, err := model.DecodeCursor(c.Encode())
+	assert.NoError(t, err)
+	assert.Equal(t, c, *decoded)
+
+	for _, bad := range []string{"not-base64!", "e30", "W10"} {
+		_, err := model.DecodeCursor(bad)
+		assert.ErrorIs(t, err, model.ErrInvalidCursor, bad)
+	}
+}
+
+// TestParseSort checks the whitelist of sortable fields and the "-" prefix for descending order.
+func TestParseSort(t *testing.T) {
+	sort, err := model.ParseSort("")
+	assert.NoError(t, err)
+	assert.Equal(t, model.DefaultSort, sort)
+
+	sort, err = model.ParseSort("-start_date")
+	assert.NoError(t, err)
+	assert.Equal(t, model.Sort{Field: model.SortStartDate, Desc: true}, sort)
+	assert.Equal(t, "-start_date", sort.String())
+
+	sort, err = model.ParseSort("price")
+	assert.NoError(t, err)
+	assert.False(t, sort.Desc)
+
+	for _, bad := range []string{"user_id", "--price", "price;DROP TABLE subscriptions"} {
+		_, err := model.ParseSort(bad)
+		assert.ErrorIs(t, err, model.ErrInvalidSort, bad)
+	}
+}
+
 // Helper for passing a string pointer
 func stringPtr(s string) *string {
 	return &s
 }
+
+// TestAPIKeyRequest checks that only known scopes can be requested and that at least one is required.
+func TestAPIKeyRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		request model.APIKeyRequest
+		wantErr bool
+	}{
+		{"Valid", model.APIKeyRequest{Name: "batch", Scopes: []model.Scope{model.ScopeSubscriptionsRead, model.ScopeSummaryRead}}, false},
+		{"No Scopes", model.APIKeyRequest{Name: "batch"}, true},
+		{"Unknown Scope", model.APIKeyRequest{Name: "batch", Scopes: []model.Scope{"subscriptions:delete"}}, true},
+		{"Missing Name", model.APIKeyRequest{Scopes: []model.Scope{model.ScopeSummaryRead}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := model.Validate.Struct(tt.request)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	key := &model.APIKey{Scopes: []model.Scope{model.ScopeSubscriptionsRead}}
+	assert.True(t, key.HasScope(model.ScopeSubscriptionsRead))
+	assert.False(t, key.HasScope(model.ScopeSubscriptionsWrite))
+}
+
+// TestFieldErrors checks that validation failures are reported per JSON field with readable messages.
+func TestFieldErrors(t *testing.T) {
+	err := model.Validate.Struct(model.CreateSubscriptionRequest{
+		ServiceName: "N",
+		Price:       100,
+		UserID:      uuid.New(),
+		StartDate:   "2025-01",
+	})
+
+	assert.ElementsMatch(t, []model.FieldError{
+		{Field: "service_name", Message: "must be at least 2"},
+		{Field: "start_date", Message: "must be a month in MM-YYYY format"},
+	}, model.FieldErrors(err))
+
+	assert.Nil(t, model.FieldErrors(assert.AnError))
+
+	_, err = model.ParseSubscriptionPatch([]byte(`{"currency": "rubles"}`))
+	var fe *model.FieldError
+	assert.ErrorAs(t, err, &fe)
+	assert.Equal(t, "currency", fe.Field)
+	assert.Equal(t, "must be an ISO 4217 currency code", fe.Message)
+}
+
+// TestMonthlyCost checks that prices are spread over months according to the billing period.
+func TestMonthlyCost(t *testing.T) {
+	tests := []struct {
+		period   model.BillingPeriod
+		price    int
+		expected int
+	}{
+		{model.BillingMonthly, 500, 500},
+		{model.BillingQuarterly, 1000, 333},
+		{model.BillingYearly, 1200, 100},
+		{model.BillingWeekly, 300, 1300},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.period), func(t *testing.T) {
+			sub := &model.Subscription{Price: tt.price, BillingPeriod: tt.period}
+			assert.Equal(t, tt.expected, sub.MonthlyCost())
+			assert.Equal(t, tt.expected, model.ToExportRow(sub).MonthlyCost)
+		})
+	}
+}
+
+// TestOverlaps checks that only periods of the same user and service sharing a month overlap.
+func TestOverlaps(t *testing.T) {
+	userID := uuid.New()
+	month := func(m time.Month) *time.Time {
+		d := time.Date(2025, m, 1, 0, 0, 0, 0, time.UTC)
+		return &d
+	}
+	sub := &model.Subscription{UserID: userID, ServiceName: "Netflix", StartDate: *month(3), EndDate: month(5)}
+
+	tests := []struct {
+		name     string
+		other    *model.Subscription
+		expected bool
+	}{
+		{"Shared End Month", &model.Subscription{UserID: userID, ServiceName: " netflix ", StartDate: *month(5)}, true},
+		{"Open Ended Before", &model.Subscription{UserID: userID, ServiceName: "Netflix", StartDate: *month(1)}, true},
+		{"Ends Before", &model.Subscription{UserID: userID, ServiceName: "Netflix", StartDate: *month(1), EndDate: month(2)}, false},
+		{"Starts After", &model.Subscription{UserID: userID, ServiceName: "Netflix", StartDate: *month(6)}, false},
+		{"Other Service", &model.Subscription{UserID: userID, ServiceName: "Spotify", StartDate: *month(4)}, false},
+		{"Other User", &model.Subscription{UserID: uuid.New(), ServiceName: "Netflix", StartDate: *month(4)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, sub.Overlaps(tt.other))
+			assert.Equal(t, tt.expected, tt.other.Overlaps(sub))
+		})
+	}
+}
+
+// TestServiceNames checks that the names of a catalog entry are normalized and not repeated.
+func TestServiceNames(t *testing.T) {
+	svc := &model.Service{Name: " Netflix ", Aliases: []string{"NETFLIX", "Нетфликс ", "netflix.com", "нетфликс"}}
+
+	assert.Equal(t, []string{"netflix", "нетфликс", "netflix.com"}, svc.Names())
+	assert.Equal(t, "video", model.NormalizeCategory(" Video"))
+}
+
+// TestChargeDates checks that charges follow the billing period from the start date and stop after the end month.
+func TestChargeDates(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	end := day(2025, 6, 1)
+
+	tests := []struct {
+		name     string
+		sub      *model.Subscription
+		from, to time.Time
+		expected []time.Time
+	}{
+		{
+			name:     "Monthly",
+			sub:      &model.Subscription{BillingPeriod: model.BillingMonthly, StartDate: day(2024, 11, 1)},
+			from:     day(2025, 1, 15),
+			to:       day(2025, 3, 1),
+			expected: []time.Time{day(2025, 2, 1), day(2025, 3, 1)},
+		},
+		{
+			name:     "Quarterly",
+			sub:      &model.Subscription{BillingPeriod: model.BillingQuarterly, StartDate: day(2024, 11, 1)},
+			from:     day(2025, 1, 1),
+			to:       day(2025, 12, 31),
+			expected: []time.Time{day(2025, 2, 1), day(2025, 5, 1), day(2025, 8, 1), day(2025, 11, 1)},
+		},
+		{
+			name:     "Yearly",
+			sub:      &model.Subscription{BillingPeriod: model.BillingYearly, StartDate: day(2023, 3, 1)},
+			from:     day(2025, 1, 1),
+			to:       day(2026, 12, 31),
+			expected: []time.Time{day(2025, 3, 1), day(2026, 3, 1)},
+		},
+		{
+			name:     "Weekly",
+			sub:      &model.Subscription{BillingPeriod: model.BillingWeekly, StartDate: day(2025, 1, 1)},
+			from:     day(2025, 2, 1),
+			to:       day(2025, 2, 14),
+			expected: []time.Time{day(2025, 2, 5), day(2025, 2, 12)},
+		},
+		{
+			name:     "Starts In Window",
+			sub:      &model.Subscription{BillingPeriod: model.BillingMonthly, StartDate: day(2025, 2, 1)},
+			from:     day(2025, 1, 20),
+			to:       day(2025, 2, 10),
+			expected: []time.Time{day(2025, 2, 1)},
+		},
+		{
+			name:     "Stops After End Month",
+			sub:      &model.Subscription{BillingPeriod: model.BillingWeekly, StartDate: day(2025, 5, 1), EndDate: &end},
+			from:     day(2025, 6, 20),
+			to:       day(2025, 7, 10),
+			expected: []time.Time{day(2025, 6, 26)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.sub.ChargeDates(tt.from, tt.to))
+		})
+	}
+
+	sub := &model.Subscription{StartDate: day(2025, 5, 1), EndDate: &end}
+	assert.Equal(t, day(2025, 7, 1), *sub.EndsAt())
+}
+
+// TestPriceAt checks that the price in force in a month is taken from the price history.
+func TestPriceAt(t *testing.T) {
+	month := func(m time.Month) time.Time { return time.Date(2025, m, 1, 0, 0, 0, 0, time.UTC) }
+	prices := []*model.PriceChange{
+		{Price: 500, EffectiveFrom: month(3)},
+		{Price: 700, EffectiveFrom: month(6)},
+	}
+
+	assert.Equal(t, 500, model.PriceAt(prices, month(1), 100), "months before the history use the earliest price")
+	assert.Equal(t, 500, model.PriceAt(prices, month(5).AddDate(0, 0, 20), 100))
+	assert.Equal(t, 700, model.PriceAt(prices, month(6).AddDate(0, 0, 14), 100))
+	assert.Equal(t, 100, model.PriceAt(nil, month(6), 100))
+}
@@ -0,0 +1,28 @@
+package model
+
+import "strings"
+
+// NormalizeServiceName returns the form of a service name under which subscriptions are compared for overlaps:
+// trimmed and lower-cased, like in the subscriptions_no_overlap constraint.
+func NormalizeServiceName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// Overlaps reports whether two subscriptions of the same user to the same service are active in a common month.
+// End dates are inclusive and a missing end date means the subscription is open-ended.
+func (s *Subscription) Overlaps(other *Subscription) bool {
+	if s.UserID != other.UserID || NormalizeServiceName(s.ServiceName) != NormalizeServiceName(other.ServiceName) {
+		return false
+	}
+
+	startsBeforeOtherEnds := other.EndDate == nil || !s.StartDate.After(*other.EndDate)
+	otherStartsBeforeEnd := s.EndDate == nil || !other.StartDate.After(*s.EndDate)
+
+	return startsBeforeOtherEnds && otherStartsBeforeEnd
+}
+
+// OverlapAllowed reports whether the subscription is explicitly exempt from the overlap rule.
+// An unset AllowOverlap is false here; on an update it keeps the stored setting instead.
+func (s *Subscription) OverlapAllowed() bool {
+	return s.AllowOverlap != nil && *s.AllowOverlap
+}
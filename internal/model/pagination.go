@@ -0,0 +1,82 @@
+package model
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor identifies the last subscription of a page: the value of the sort field and the ID that breaks ties.
+// Sort records the ordering the cursor was issued for, since a cursor is meaningless under another ordering.
+type Cursor struct {
+	Sort  string    `json:"s"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"i"`
+}
+
+// Encode returns the opaque representation of the cursor handed out to API clients.
+func (c Cursor) Encode() string {
+	// Marshalling a struct of strings and a UUID cannot fail.
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodeCursor parses a cursor previously produced by Cursor.Encode.
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(raw, &c); err != nil || c.ID == uuid.Nil || c.Sort == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// Page selects a slice of a list. When After is set, the page starts right after the cursor (keyset
+// pagination) and Offset is ignored; otherwise the first Offset items are skipped.
+type Page struct {
+	Limit  int
+	Offset int
+	After  *Cursor
+}
+
+// SubscriptionPage is a page of subscriptions together with the number of subscriptions matching the
+// filters across all pages. NextCursor is set when more subscriptions follow the page.
+type SubscriptionPage struct {
+	Items      []*Subscription
+	Total      int
+	NextCursor *Cursor
+}
+
+// SubscriptionListResponse is the envelope returned by the list endpoint in cursor mode.
+type SubscriptionListResponse struct {
+	Items      []SubscriptionResponse `json:"items" extensions:"x-order=1"`
+	NextCursor *string                `json:"next_cursor,omitempty" extensions:"x-order=2"`
+}
+
+// ToListResponse converts a SubscriptionPage into a SubscriptionListResponse DTO.
+func ToListResponse(page *SubscriptionPage) SubscriptionListResponse {
+	resp := SubscriptionListResponse{
+		Items: make([]SubscriptionResponse, 0, len(page.Items)),
+	}
+
+	for _, sub := range page.Items {
+		resp.Items = append(resp.Items, ToResponse(sub))
+	}
+
+	if page.NextCursor != nil {
+		next := page.NextCursor.Encode()
+		resp.NextCursor = &next
+	}
+
+	return resp
+}
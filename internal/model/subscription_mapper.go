@@ -3,7 +3,8 @@ package model
 import "time"
 
 // ToDomain transforms a CreateSubscriptionRequest into a Subscription domain model.
-// It parses date strings from the "MM-YYYY" format into time.Time objects.
+// It parses date strings from the "MM-YYYY" format into time.Time objects
+// and defaults an empty currency to RUB and an empty billing period to monthly.
 func ToDomain(req CreateSubscriptionRequest) (*Subscription, error) {
 	startDate, err := time.Parse("01-2006", req.StartDate)
 	if err != nil {
@@ -19,12 +20,24 @@ func ToDomain(req CreateSubscriptionRequest) (*Subscription, error) {
 		endDate = &parsed
 	}
 
+	billingPeriod := BillingMonthly
+	if req.BillingPeriod != "" {
+		billingPeriod = BillingPeriod(req.BillingPeriod)
+	}
+
+	currency := DefaultCurrency
+	if req.Currency != "" {
+		currency = req.Currency
+	}
+
 	return &Subscription{
-		UserID:      req.UserID,
-		ServiceName: req.ServiceName,
-		Price:       req.Price,
-		StartDate:   startDate,
-		EndDate:     endDate,
+		UserID:        req.UserID,
+		ServiceName:   req.ServiceName,
+		Price:         req.Price,
+		Currency:      currency,
+		BillingPeriod: billingPeriod,
+		StartDate:     startDate,
+		EndDate:       endDate,
 	}, nil
 }
 
@@ -32,11 +45,15 @@ func ToDomain(req CreateSubscriptionRequest) (*Subscription, error) {
 // It formats time.Time objects back into "MM-YYYY" strings for API consumers.
 func ToResponse(sub *Subscription) SubscriptionResponse {
 	resp := SubscriptionResponse{
-		ID:          sub.ID,
-		ServiceName: sub.ServiceName,
-		Price:       sub.Price,
-		UserID:      sub.UserID,
-		StartDate:   sub.StartDate.Format("01-2006"),
+		ID:            sub.ID,
+		ServiceName:   sub.ServiceName,
+		Price:         sub.Price,
+		Currency:      sub.Currency,
+		BillingPeriod: string(sub.BillingPeriod),
+		UserID:        sub.UserID,
+		StartDate:     sub.StartDate.Format("01-2006"),
+		DeletedAt:     sub.DeletedAt,
+		ServiceID:     sub.ServiceID,
 	}
 
 	if sub.EndDate != nil {
@@ -46,3 +63,55 @@ func ToResponse(sub *Subscription) SubscriptionResponse {
 
 	return resp
 }
+
+// ToSummaryResponse converts a CostSummary into a SummaryResponse DTO.
+// Bucket periods are formatted as "MM-YYYY" strings.
+func ToSummaryResponse(summary *CostSummary) SummaryResponse {
+	resp := SummaryResponse{
+		Total:    summary.Total,
+		Currency: summary.Currency,
+		GroupBy:  summary.GroupBy,
+		Buckets:  make([]SummaryBucketResponse, 0, len(summary.Buckets)),
+	}
+
+	for _, b := range summary.Buckets {
+		bucket := SummaryBucketResponse{
+			ServiceName: b.ServiceName,
+			UserID:      b.UserID,
+			Category:    b.Category,
+			Total:       b.Total,
+		}
+
+		if b.Period != nil {
+			period := b.Period.Format("01-2006")
+			bucket.Period = &period
+		}
+
+		resp.Buckets = append(resp.Buckets, bucket)
+	}
+
+	return resp
+}
+
+// ToPriceChange transforms a PriceChangeRequest into a PriceChange domain model.
+// It parses effective_from from the "MM-YYYY" format.
+func ToPriceChange(req PriceChangeRequest) (*PriceChange, error) {
+	effectiveFrom, err := time.Parse("01-2006", req.EffectiveFrom)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PriceChange{
+		Price:         req.Price,
+		EffectiveFrom: effectiveFrom,
+	}, nil
+}
+
+// ToPriceChangeResponse converts a PriceChange domain model into a PriceChangeResponse DTO.
+func ToPriceChangeResponse(change *PriceChange) PriceChangeResponse {
+	return PriceChangeResponse{
+		ID:            change.ID,
+		Price:         change.Price,
+		EffectiveFrom: change.EffectiveFrom.Format("01-2006"),
+	}
+}
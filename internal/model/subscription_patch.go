@@ -0,0 +1,146 @@
+package model
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SubscriptionPatch holds a JSON Merge Patch (RFC 7386) for a subscription.
+// Nil fields are left unchanged. EndDate can be cleared explicitly: ClearEndDate is set
+// when the patch contains "end_date": null. AllowOverlap is not part of the document; it comes from the
+// allow_overlap parameter and, like any absent member, leaves the stored overlap override unchanged when nil.
+type SubscriptionPatch struct {
+	ServiceName   *string
+	Price         *int
+	Currency      *string
+	BillingPeriod *string
+	StartDate     *string
+	EndDate       *string
+	ClearEndDate  bool
+	AllowOverlap  *bool
+}
+
+// patchRules lists the fields accepted in a subscription patch with the validation tags
+// of the corresponding CreateSubscriptionRequest fields.
+var patchRules = map[string]string{
+	"service_name":   "required,min=2",
+	"price":          "min=0",
+	"currency":       "iso4217",
+	"billing_period": "oneof=monthly quarterly yearly weekly",
+	"start_date":     "mmYYYY",
+	"end_date":       "mmYYYY",
+}
+
+// ParseSubscriptionPatch decodes and validates a JSON Merge Patch document.
+// Unknown or immutable fields (such as user_id) are rejected, and null is only allowed for end_date.
+func ParseSubscriptionPatch(data []byte) (*SubscriptionPatch, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("patch must be a JSON object")
+	}
+
+	var patch SubscriptionPatch
+
+	for name, raw := range fields {
+		rule, ok := patchRules[name]
+		if !ok {
+			return nil, &FieldError{Field: name, Message: "cannot be patched"}
+		}
+
+		if bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			if name != "end_date" {
+				return nil, &FieldError{Field: name, Message: "cannot be null"}
+			}
+			patch.ClearEndDate = true
+			continue
+		}
+
+		var value any
+		switch name {
+		case "price":
+			var price int
+			if err := json.Unmarshal(raw, &price); err != nil {
+				return nil, &FieldError{Field: name, Message: "must be an integer"}
+			}
+			patch.Price = &price
+			value = price
+		default:
+			var str string
+			if err := json.Unmarshal(raw, &str); err != nil {
+				return nil, &FieldError{Field: name, Message: "must be a string"}
+			}
+			value = str
+
+			switch name {
+			case "service_name":
+				patch.ServiceName = &str
+			case "currency":
+				patch.Currency = &str
+			case "billing_period":
+				patch.BillingPeriod = &str
+			case "start_date":
+				patch.StartDate = &str
+			case "end_date":
+				patch.EndDate = &str
+			}
+		}
+
+		if err := Validate.Var(value, rule); err != nil {
+			fe := FieldError{Field: name, Message: "is invalid"}
+			if details := FieldErrors(err); len(details) > 0 {
+				fe.Message = details[0].Message
+			}
+			return nil, &fe
+		}
+	}
+
+	return &patch, nil
+}
+
+// Apply merges the patch into the subscription, parsing "MM-YYYY" dates.
+// The subscription is left unchanged if a date cannot be parsed.
+func (p *SubscriptionPatch) Apply(sub *Subscription) error {
+	var startDate, endDate time.Time
+	var err error
+
+	if p.StartDate != nil {
+		if startDate, err = time.Parse("01-2006", *p.StartDate); err != nil {
+			return err
+		}
+	}
+
+	if p.EndDate != nil {
+		if endDate, err = time.Parse("01-2006", *p.EndDate); err != nil {
+			return err
+		}
+	}
+
+	if p.ServiceName != nil {
+		sub.ServiceName = *p.ServiceName
+	}
+	if p.Price != nil {
+		sub.Price = *p.Price
+	}
+	if p.Currency != nil {
+		sub.Currency = *p.Currency
+	}
+	if p.BillingPeriod != nil {
+		sub.BillingPeriod = BillingPeriod(*p.BillingPeriod)
+	}
+	if p.StartDate != nil {
+		sub.StartDate = startDate
+	}
+	if p.EndDate != nil {
+		sub.EndDate = &endDate
+	}
+	if p.ClearEndDate {
+		sub.EndDate = nil
+	}
+	if p.AllowOverlap != nil {
+		sub.AllowOverlap = p.AllowOverlap
+	}
+
+	return nil
+}
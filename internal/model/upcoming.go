@@ -0,0 +1,188 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reminder kinds.
+const (
+	// ReminderCharge announces an upcoming charge of a subscription.
+	ReminderCharge = "charge"
+	// ReminderEnd announces that a subscription is about to end.
+	ReminderEnd = "end"
+)
+
+// UpcomingCharge is a future charge of a subscription: Amount (in minor units of the subscription currency)
+// is billed on Date according to the price in force in Date's month.
+type UpcomingCharge struct {
+	Subscription *Subscription
+	Date         time.Time
+	Amount       int
+}
+
+// Upcoming holds the charges of a user dated within [From, To], ordered by date.
+type Upcoming struct {
+	From    time.Time
+	To      time.Time
+	Charges []UpcomingCharge
+}
+
+// Reminder is emitted DaysLeft days before a subscription is charged (Kind "charge") or ends (Kind "end").
+// Date is the charge date, or the first day the subscription is no longer active; Amount is only set
+// for charge reminders.
+type Reminder struct {
+	Kind         string
+	Subscription *Subscription
+	Date         time.Time
+	Amount       int
+	DaysLeft     int
+}
+
+// step returns the number of months and days between two charges of the billing period.
+func (p BillingPeriod) step() (months, days int) {
+	switch p {
+	case BillingQuarterly:
+		return 3, 0
+	case BillingYearly:
+		return 12, 0
+	case BillingWeekly:
+		return 0, 7
+	}
+	return 1, 0
+}
+
+// EndsAt returns the first day the subscription is no longer active, i.e. the first day of the month after
+// its end month, or nil if it has no end date.
+func (s *Subscription) EndsAt() *time.Time {
+	if s.EndDate == nil {
+		return nil
+	}
+	end := time.Date(s.EndDate.Year(), s.EndDate.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	return &end
+}
+
+// ChargeDates returns the dates within [from, to] on which the subscription is charged, in order: its start date
+// and every billing period after it (every month, quarter, year or 7 days) as long as it is still active.
+// This matches the charging rules of the cost summary.
+func (s *Subscription) ChargeDates(from, to time.Time) []time.Time {
+	months, days := s.BillingPeriod.step()
+	end := s.EndsAt()
+
+	// Skip the periods before the window; the estimate may fall one period short, never past from.
+	k := 0
+	if from.After(s.StartDate) {
+		if days > 0 {
+			k = int(from.Sub(s.StartDate).Hours()/24)/days - 1
+		} else {
+			k = ((from.Year()-s.StartDate.Year())*12+int(from.Month())-int(s.StartDate.Month()))/months - 1
+		}
+		k = max(k, 0)
+	}
+
+	var dates []time.Time
+	for ; ; k++ {
+		date := s.StartDate.AddDate(0, k*months, k*days)
+		if date.After(to) || (end != nil && !date.Before(*end)) {
+			break
+		}
+		if !date.Before(from) {
+			dates = append(dates, date)
+		}
+	}
+
+	return dates
+}
+
+// PriceAt returns the price in force in date's month according to the price history ordered by EffectiveFrom:
+// the latest change effective in or before the month, the earliest change for months before the history
+// and fallback if the history is empty.
+func PriceAt(prices []*PriceChange, date time.Time, fallback int) int {
+	if len(prices) == 0 {
+		return fallback
+	}
+
+	month := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, time.UTC)
+	price := prices[0].Price
+	for _, change := range prices {
+		if change.EffectiveFrom.After(month) {
+			break
+		}
+		price = change.Price
+	}
+
+	return price
+}
+
+// UpcomingChargeResponse represents an upcoming charge returned to API clients; Date is formatted as "YYYY-MM-DD".
+type UpcomingChargeResponse struct {
+	SubscriptionID uuid.UUID `json:"subscription_id" extensions:"x-order=1"`
+	ServiceName    string    `json:"service_name" extensions:"x-order=2"`
+	Date           string    `json:"date" extensions:"x-order=3"`
+	Amount         int       `json:"amount" extensions:"x-order=4"`
+	Currency       string    `json:"currency" extensions:"x-order=5"`
+	BillingPeriod  string    `json:"billing_period" extensions:"x-order=6"`
+}
+
+// UpcomingResponse lists the charges of a user between From and To ("YYYY-MM-DD", inclusive) by date.
+type UpcomingResponse struct {
+	From    string                   `json:"from" extensions:"x-order=1"`
+	To      string                   `json:"to" extensions:"x-order=2"`
+	Charges []UpcomingChargeResponse `json:"charges" extensions:"x-order=3"`
+}
+
+// ReminderResponse is the payload of a reminder delivered by notifiers. Date is formatted as "YYYY-MM-DD";
+// Amount is omitted for end reminders.
+type ReminderResponse struct {
+	Kind           string    `json:"kind" extensions:"x-order=1"`
+	SubscriptionID uuid.UUID `json:"subscription_id" extensions:"x-order=2"`
+	UserID         uuid.UUID `json:"user_id" extensions:"x-order=3"`
+	ServiceName    string    `json:"service_name" extensions:"x-order=4"`
+	Date           string    `json:"date" extensions:"x-order=5"`
+	DaysLeft       int       `json:"days_left" extensions:"x-order=6"`
+	Amount         *int      `json:"amount,omitempty" extensions:"x-order=7"`
+	Currency       string    `json:"currency" extensions:"x-order=8"`
+}
+
+// ToUpcomingResponse converts Upcoming charges into an UpcomingResponse DTO.
+func ToUpcomingResponse(upcoming *Upcoming) UpcomingResponse {
+	resp := UpcomingResponse{
+		From:    upcoming.From.Format("2006-01-02"),
+		To:      upcoming.To.Format("2006-01-02"),
+		Charges: make([]UpcomingChargeResponse, 0, len(upcoming.Charges)),
+	}
+
+	for _, c := range upcoming.Charges {
+		resp.Charges = append(resp.Charges, UpcomingChargeResponse{
+			SubscriptionID: c.Subscription.ID,
+			ServiceName:    c.Subscription.ServiceName,
+			Date:           c.Date.Format("2006-01-02"),
+			Amount:         c.Amount,
+			Currency:       c.Subscription.Currency,
+			BillingPeriod:  string(c.Subscription.BillingPeriod),
+		})
+	}
+
+	return resp
+}
+
+// ToReminderResponse converts a Reminder into a ReminderResponse DTO.
+func ToReminderResponse(r *Reminder) ReminderResponse {
+	resp := ReminderResponse{
+		Kind:           r.Kind,
+		SubscriptionID: r.Subscription.ID,
+		UserID:         r.Subscription.UserID,
+		ServiceName:    r.Subscription.ServiceName,
+		Date:           r.Date.Format("2006-01-02"),
+		DaysLeft:       r.DaysLeft,
+		Currency:       r.Subscription.Currency,
+	}
+
+	if r.Kind == ReminderCharge {
+		amount := r.Amount
+		resp.Amount = &amount
+	}
+
+	return resp
+}
@@ -1,6 +1,9 @@
 package model
 
 import (
+	"errors"
+	"reflect"
+	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -13,6 +16,66 @@ var Validate *validator.Validate
 func init() {
 	Validate = validator.New()
 	_ = Validate.RegisterValidation("mmYYYY", validateMonthYear)
+
+	// Report JSON field names so that validation errors refer to the request fields clients send.
+	Validate.RegisterTagNameFunc(func(f reflect.StructField) string {
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		return name
+	})
+}
+
+// FieldError describes why the value of a single input field was rejected.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error returns a sentence naming the field and the violated rule.
+func (e *FieldError) Error() string {
+	return "field " + e.Field + " " + e.Message
+}
+
+// FieldErrors converts an error returned by Validate into field errors. It returns nil if err
+// is not a validation error.
+func FieldErrors(err error) []FieldError {
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return nil
+	}
+
+	result := make([]FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		result = append(result, FieldError{Field: fe.Field(), Message: ruleMessage(fe.Tag(), fe.Param())})
+	}
+	return result
+}
+
+// ruleMessage describes a violated validation rule in words.
+func ruleMessage(tag, param string) string {
+	switch tag {
+	case "required":
+		return "is required"
+	case "min":
+		return "must be at least " + param
+	case "max":
+		return "must be at most " + param
+	case "gt":
+		return "must be greater than " + param
+	case "oneof":
+		return "must be one of " + strings.ReplaceAll(param, " ", ", ")
+	case "nefield":
+		return "must differ from " + param
+	case "mmYYYY":
+		return "must be a month in MM-YYYY format"
+	case "iso4217":
+		return "must be an ISO 4217 currency code"
+	case "http_url":
+		return "must be an http or https URL"
+	}
+	return "failed the " + tag + " rule"
 }
 
 // validateMonthYear is a custom validation function that ensures a string field
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook delivery statuses. Deliveries are pending until they succeed or run out of attempts,
+// in which case they are dead-lettered until redelivered.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryDead      = "dead"
+)
+
+// Webhook is an endpoint notified of subscription lifecycle events. Events lists the event types it receives;
+// an empty list subscribes it to all of them. Payloads are signed with Secret (HMAC-SHA256).
+type Webhook struct {
+	ID        uuid.UUID
+	URL       string
+	Secret    string
+	Events    []string
+	Active    bool
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// WebhookDelivery is the delivery of an event to a webhook. URL and Secret are those of the webhook
+// when the delivery is attempted. LastStatus is the HTTP status of the last attempt, if a response was received.
+type WebhookDelivery struct {
+	ID            uuid.UUID
+	WebhookID     uuid.UUID
+	Event         Event
+	Status        string
+	Attempts      int
+	NextAttemptAt time.Time
+	LastStatus    *int
+	LastError     string
+	CreatedAt     time.Time
+	DeliveredAt   *time.Time
+	URL           string
+	Secret        string
+}
+
+// WebhookRequest defines the schema for registering or replacing a webhook. An empty secret is generated
+// on creation and keeps the current one on update; Active defaults to true.
+type WebhookRequest struct {
+	URL    string   `json:"url" validate:"required,http_url" extensions:"x-order=1"`
+	Secret string   `json:"secret,omitempty" validate:"omitempty,min=16" extensions:"x-order=2"`
+	Events []string `json:"events,omitempty" validate:"dive,oneof=subscription.created subscription.updated subscription.deleted subscription.restored subscription.purged subscription.expired" extensions:"x-order=3"`
+	Active *bool    `json:"active,omitempty" extensions:"x-order=4"`
+}
+
+// WebhookResponse represents a webhook returned to API clients. The secret is never included.
+type WebhookResponse struct {
+	ID        uuid.UUID `json:"id" extensions:"x-order=0"`
+	URL       string    `json:"url" extensions:"x-order=1"`
+	Events    []string  `json:"events" extensions:"x-order=2"`
+	Active    bool      `json:"active" extensions:"x-order=3"`
+	CreatedAt time.Time `json:"created_at" extensions:"x-order=4"`
+	UpdatedAt time.Time `json:"updated_at" extensions:"x-order=5"`
+}
+
+// CreatedWebhookResponse is returned once when a webhook is registered and is the only response carrying
+// its signing secret.
+type CreatedWebhookResponse struct {
+	WebhookResponse
+	Secret string `json:"secret" extensions:"x-order=6"`
+}
+
+// WebhookDeliveryResponse represents a webhook delivery returned to API clients.
+type WebhookDeliveryResponse struct {
+	ID            uuid.UUID  `json:"id" extensions:"x-order=0"`
+	EventID       int64      `json:"event_id" extensions:"x-order=1"`
+	EventType     string     `json:"event_type" extensions:"x-order=2"`
+	Status        string     `json:"status" extensions:"x-order=3"`
+	Attempts      int        `json:"attempts" extensions:"x-order=4"`
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty" extensions:"x-order=5"`
+	LastStatus    *int       `json:"last_status,omitempty" extensions:"x-order=6"`
+	LastError     string     `json:"last_error,omitempty" extensions:"x-order=7"`
+	CreatedAt     time.Time  `json:"created_at" extensions:"x-order=8"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty" extensions:"x-order=9"`
+}
+
+// ToWebhook converts a WebhookRequest DTO into a Webhook domain model.
+func ToWebhook(req WebhookRequest) *Webhook {
+	hook := &Webhook{URL: req.URL, Secret: req.Secret, Events: req.Events, Active: true}
+	if req.Active != nil {
+		hook.Active = *req.Active
+	}
+	return hook
+}
+
+// ToWebhookResponse converts a Webhook domain model into a WebhookResponse DTO.
+func ToWebhookResponse(hook *Webhook) WebhookResponse {
+	events := hook.Events
+	if events == nil {
+		events = []string{}
+	}
+
+	return WebhookResponse{
+		ID:        hook.ID,
+		URL:       hook.URL,
+		Events:    events,
+		Active:    hook.Active,
+		CreatedAt: hook.CreatedAt,
+		UpdatedAt: hook.UpdatedAt,
+	}
+}
+
+// ToWebhookDeliveryResponse converts a WebhookDelivery into a WebhookDeliveryResponse DTO.
+// The next attempt is only reported for pending deliveries.
+func ToWebhookDeliveryResponse(d *WebhookDelivery) WebhookDeliveryResponse {
+	resp := WebhookDeliveryResponse{
+		ID:          d.ID,
+		EventID:     d.Event.ID,
+		EventType:   d.Event.Type,
+		Status:      d.Status,
+		Attempts:    d.Attempts,
+		LastStatus:  d.LastStatus,
+		LastError:   d.LastError,
+		CreatedAt:   d.CreatedAt,
+		DeliveredAt: d.DeliveredAt,
+	}
+
+	if d.Status == DeliveryPending {
+		next := d.NextAttemptAt
+		resp.NextAttemptAt = &next
+	}
+
+	return resp
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"subscription-service/internal/model"
+)
+
+// Notifier delivers renewal and expiry reminders to users or downstream systems.
+type Notifier interface {
+	Notify(ctx context.Context, reminder *model.Reminder) error
+}
+
+type logNotifier struct{}
+
+// NewLogNotifier creates a notifier that writes reminders to the application log.
+func NewLogNotifier() Notifier {
+	return logNotifier{}
+}
+
+// Notify logs the reminder.
+func (logNotifier) Notify(_ context.Context, reminder *model.Reminder) error {
+	sub := reminder.Subscription
+
+	if reminder.Kind == model.ReminderCharge {
+		log.Printf(
+			"INFO: reminder: subscription %s of user %s to %q is charged %d %s on %s (in %d days)",
+			sub.ID, sub.UserID, sub.ServiceName, reminder.Amount, sub.Currency,
+			reminder.Date.Format("2006-01-02"), reminder.DaysLeft,
+		)
+		return nil
+	}
+
+	log.Printf(
+		"INFO: reminder: subscription %s of user %s to %q ends on %s (in %d days)",
+		sub.ID, sub.UserID, sub.ServiceName, reminder.Date.Format("2006-01-02"), reminder.DaysLeft,
+	)
+	return nil
+}
+
+type webhookNotifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewWebhookNotifier creates a notifier that POSTs every reminder as a JSON model.ReminderResponse to url.
+// Requests time out after timeout; responses other than 2xx are reported as errors.
+func NewWebhookNotifier(url string, timeout time.Duration) Notifier {
+	return &webhookNotifier{url: url, client: &http.Client{Timeout: timeout}}
+}
+
+// Notify delivers the reminder to the webhook.
+func (n *webhookNotifier) Notify(ctx context.Context, reminder *model.Reminder) error {
+	body, err := json.Marshal(model.ToReminderResponse(reminder))
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("deliver reminder: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("deliver reminder: webhook responded with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package notify_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/notify"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWebhookNotifier checks that reminders are posted as JSON and that failed deliveries are reported.
+func TestWebhookNotifier(t *testing.T) {
+	sub := &model.Subscription{ID: uuid.New(), UserID: uuid.New(), ServiceName: "Netflix", Currency: "RUB"}
+	reminder := &model.Reminder{
+		Kind:         model.ReminderCharge,
+		Subscription: sub,
+		Date:         time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		Amount:       59900,
+		DaysLeft:     3,
+	}
+
+	t.Run("Delivered", func(t *testing.T) {
+		var received model.ReminderResponse
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, http.MethodPost, r.Method)
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+
+		err := notify.NewWebhookNotifier(server.URL, time.Second).Notify(context.Background(), reminder)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.ReminderCharge, received.Kind)
+		assert.Equal(t, sub.ID, received.SubscriptionID)
+		assert.Equal(t, sub.UserID, received.UserID)
+		assert.Equal(t, "2026-03-01", received.Date)
+		assert.Equal(t, 3, received.DaysLeft)
+		require.NotNil(t, received.Amount)
+		assert.Equal(t, 59900, *received.Amount)
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		err := notify.NewWebhookNotifier(server.URL, time.Second).Notify(context.Background(), reminder)
+
+		assert.ErrorContains(t, err, "503")
+	})
+
+	t.Run("End Reminder Has No Amount", func(t *testing.T) {
+		resp := model.ToReminderResponse(&model.Reminder{Kind: model.ReminderEnd, Subscription: sub, Date: reminder.Date})
+
+		assert.Nil(t, resp.Amount)
+	})
+}
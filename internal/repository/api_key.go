@@ -0,0 +1,164 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"subscription-service/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// APIKeyRepository defines the interface for storing API keys.
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *model.APIKey) error
+	List(ctx context.Context) ([]*model.APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	MarkUsed(ctx context.Context, keyHash string) (*model.APIKey, error)
+}
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+// apiKeyColumns lists the columns read into model.APIKey by scanAPIKey.
+const apiKeyColumns = `id, name, prefix, key_hash, scopes, created_at, last_used_at, revoked_at`
+
+type apiKeyRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewAPIKeyRepository creates a new instance of the API key repository using a pgx connection pool.
+func NewAPIKeyRepository(pool *pgxpool.Pool) APIKeyRepository {
+	return &apiKeyRepo{pool: pool}
+}
+
+// Create stores a new API key and populates its ID and creation timestamp.
+func (r *apiKeyRepo) Create(ctx context.Context, key *model.APIKey) error {
+	ctx = withQueryName(ctx, "apiKeyRepo.Create")
+
+	log.Printf("INFO: creating api key %q", key.Name)
+
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+
+	err := conn(ctx, r.pool).QueryRow(ctx, query, key.Name, key.Prefix, key.KeyHash, scopeStrings(key.Scopes)).
+		Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		log.Printf("ERROR: failed to create api key: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// List returns all API keys, including revoked ones, newest first.
+func (r *apiKeyRepo) List(ctx context.Context) ([]*model.APIKey, error) {
+	ctx = withQueryName(ctx, "apiKeyRepo.List")
+
+	log.Printf("INFO: listing api keys")
+
+	rows, err := conn(ctx, r.pool).Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at DESC, id`)
+	if err != nil {
+		log.Printf("ERROR: list api keys failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.APIKey
+
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, key)
+	}
+
+	return result, rows.Err()
+}
+
+// Revoke marks an active API key as revoked. Returns ErrAPIKeyNotFound if no active key with the ID exists.
+func (r *apiKeyRepo) Revoke(ctx context.Context, id uuid.UUID) error {
+	ctx = withQueryName(ctx, "apiKeyRepo.Revoke")
+
+	log.Printf("INFO: revoking api key %s", id)
+
+	cmd, err := conn(ctx, r.pool).Exec(ctx, `UPDATE api_keys SET revoked_at = now() WHERE id = $1 AND revoked_at IS NULL`, id)
+	if err != nil {
+		log.Printf("ERROR: failed to revoke api key %s: %v", id, err)
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		log.Printf("WARN: api key %s not found for revoke", id)
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// MarkUsed looks up an active API key by the hash of its secret and records the time of use.
+// Returns ErrAPIKeyNotFound if the hash is unknown or the key was revoked.
+func (r *apiKeyRepo) MarkUsed(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	ctx = withQueryName(ctx, "apiKeyRepo.MarkUsed")
+
+	query := `
+		UPDATE api_keys SET last_used_at = now()
+		WHERE key_hash = $1 AND revoked_at IS NULL
+		RETURNING ` + apiKeyColumns
+
+	key, err := scanAPIKey(conn(ctx, r.pool).QueryRow(ctx, query, keyHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, ErrAPIKeyNotFound
+	}
+
+	if err != nil {
+		log.Printf("ERROR: failed to look up api key: %v", err)
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// scanAPIKey reads a row of apiKeyColumns.
+func scanAPIKey(row pgx.Row) (*model.APIKey, error) {
+	var (
+		key    model.APIKey
+		scopes []string
+	)
+
+	if err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Prefix,
+		&key.KeyHash,
+		&scopes,
+		&key.CreatedAt,
+		&key.LastUsedAt,
+		&key.RevokedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	for _, s := range scopes {
+		key.Scopes = append(key.Scopes, model.Scope(s))
+	}
+
+	return &key, nil
+}
+
+// scopeStrings converts scopes into the text values stored in the scopes column.
+func scopeStrings(scopes []model.Scope) []string {
+	result := make([]string, 0, len(scopes))
+	for _, s := range scopes {
+		result = append(result, string(s))
+	}
+	return result
+}
@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"subscription-service/internal/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// AuditRepository defines the interface for reading the subscription audit log.
+// Events are written by SubscriptionRepository in the same transaction as the change they describe.
+type AuditRepository interface {
+	List(ctx context.Context, query model.AuditQuery) ([]*model.SubscriptionEvent, error)
+}
+
+type auditRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewAuditRepository creates a new instance of the audit log repository using a pgx connection pool.
+func NewAuditRepository(pool *pgxpool.Pool) AuditRepository {
+	return &auditRepo{pool: pool}
+}
+
+// List returns audit events matching the query filters, newest first, with pagination support.
+func (r *auditRepo) List(ctx context.Context, q model.AuditQuery) ([]*model.SubscriptionEvent, error) {
+	ctx = withQueryName(ctx, "auditRepo.List")
+
+	log.Printf("INFO: listing subscription events")
+
+	query := `
+		SELECT id, subscription_id, user_id, action, actor, request_id, before, after, created_at
+		FROM subscription_events
+		WHERE ($1::uuid IS NULL OR subscription_id = $1)
+		  AND ($2::uuid IS NULL OR user_id = $2)
+		  AND ($3::text IS NULL OR actor = $3)
+		  AND ($4::text IS NULL OR action = $4)
+		  AND ($5::timestamptz IS NULL OR created_at >= $5)
+		  AND ($6::timestamptz IS NULL OR created_at < $6)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $7 OFFSET $8
+	`
+
+	var action *string
+	if q.Action != nil {
+		a := string(*q.Action)
+		action = &a
+	}
+
+	rows, err := conn(ctx, r.pool).Query(
+		ctx,
+		query,
+		q.SubscriptionID,
+		q.UserID,
+		q.Actor,
+		action,
+		q.From,
+		q.To,
+		q.Limit,
+		q.Offset,
+	)
+	if err != nil {
+		log.Printf("ERROR: list subscription events failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.SubscriptionEvent
+
+	for rows.Next() {
+		var e model.SubscriptionEvent
+		if err := rows.Scan(
+			&e.ID,
+			&e.SubscriptionID,
+			&e.UserID,
+			&e.Action,
+			&e.Actor,
+			&e.RequestID,
+			&e.Before,
+			&e.After,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, &e)
+	}
+
+	return result, rows.Err()
+}
+
+// insertEvent records a change of a subscription in the audit log and the event outbox inside tx.
+// The actor and request ID are taken from ctx; before and after are stored as API snapshots and may be nil.
+func insertEvent(ctx context.Context, tx pgx.Tx, action model.EventAction, before, after *model.Subscription) error {
+	info := model.AuditInfoFrom(ctx)
+
+	subject := after
+	if subject == nil {
+		subject = before
+	}
+
+	beforeJSON, err := snapshot(before)
+	if err != nil {
+		return err
+	}
+
+	afterJSON, err := snapshot(after)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO subscription_events (subscription_id, user_id, action, actor, request_id, before, after)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		subject.ID,
+		subject.UserID,
+		string(action),
+		info.Actor,
+		info.RequestID,
+		beforeJSON,
+		afterJSON,
+	)
+	if err != nil {
+		return err
+	}
+
+	return enqueueEvent(ctx, tx, model.EventTypeOf(action), change{before: before, after: after})
+}
+
+// snapshot encodes the subscription the way the API returns it; a nil subscription is stored as NULL.
+func snapshot(sub *model.Subscription) ([]byte, error) {
+	if sub == nil {
+		return nil, nil
+	}
+	return json.Marshal(model.ToResponse(sub))
+}
+
+// change is the state of a subscription before and after a write.
+type change struct {
+	before, after *model.Subscription
+}
+
+// insertEvents records changes of the same kind in the audit log and the event outbox with a single COPY each,
+// attributed to the actor and request stored in ctx. It is used by batch writes instead of one insertEvent
+// per subscription.
+func insertEvents(ctx context.Context, tx pgx.Tx, action model.EventAction, changes []change) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	info := model.AuditInfoFrom(ctx)
+	rows := make([][]any, 0, len(changes))
+
+	for _, c := range changes {
+		subject := c.after
+		if subject == nil {
+			subject = c.before
+		}
+
+		beforeJSON, err := snapshot(c.before)
+		if err != nil {
+			return err
+		}
+
+		afterJSON, err := snapshot(c.after)
+		if err != nil {
+			return err
+		}
+
+		rows = append(rows, []any{
+			subject.ID,
+			subject.UserID,
+			string(action),
+			info.Actor,
+			info.RequestID,
+			beforeJSON,
+			afterJSON,
+		})
+	}
+
+	_, err := tx.CopyFrom(
+		ctx,
+		pgx.Identifier{"subscription_events"},
+		[]string{"subscription_id", "user_id", "action", "actor", "request_id", "before", "after"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return err
+	}
+
+	return enqueueEvents(ctx, tx, model.EventTypeOf(action), changes)
+}
@@ -0,0 +1,372 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"subscription-service/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// errBatchRejected rolls back an atomic batch after one of its items failed.
+var errBatchRejected = errors.New("batch rejected")
+
+// CreateBatch inserts subscriptions in a single transaction with COPY instead of one INSERT per subscription
+// and populates their IDs, versions and timestamps. An empty currency is stored as RUB and an empty billing period
+// as monthly. Prices and audit events are written in bulk as well; either all subscriptions are created or none.
+func (r *subscriptionRepo) CreateBatch(ctx context.Context, subs []*model.Subscription) error {
+	ctx = withQueryName(ctx, "subscriptionRepo.CreateBatch")
+
+	log.Printf("INFO: creating %d subscriptions", len(subs))
+
+	if len(subs) == 0 {
+		return nil
+	}
+
+	ids := make([]uuid.UUID, len(subs))
+	subRows := make([][]any, len(subs))
+	priceRows := make([][]any, len(subs))
+
+	for i, sub := range subs {
+		if sub.Currency == "" {
+			sub.Currency = model.DefaultCurrency
+		}
+		if sub.BillingPeriod == "" {
+			sub.BillingPeriod = model.BillingMonthly
+		}
+
+		ids[i] = uuid.New()
+		subRows[i] = []any{
+			ids[i], sub.UserID, sub.ServiceName, sub.Price, sub.Currency, string(sub.BillingPeriod), sub.StartDate, sub.EndDate,
+			sub.OverlapAllowed(), sub.ServiceID,
+		}
+		month := time.Date(sub.StartDate.Year(), sub.StartDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+		priceRows[i] = []any{ids[i], sub.Price, month}
+	}
+
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		if _, err := tx.CopyFrom(
+			ctx,
+			pgx.Identifier{"subscriptions"},
+			[]string{
+				"id", "user_id", "service_name", "price", "currency", "billing_period", "start_date", "end_date",
+				"allow_overlap", "service_id",
+			},
+			pgx.CopyFromRows(subRows),
+		); err != nil {
+			return err
+		}
+
+		if _, err := tx.CopyFrom(
+			ctx,
+			pgx.Identifier{"subscription_prices"},
+			[]string{"subscription_id", "price", "effective_from"},
+			pgx.CopyFromRows(priceRows),
+		); err != nil {
+			return err
+		}
+
+		created, err := selectSubscriptions(ctx, tx, ids, false, false)
+		if err != nil {
+			return err
+		}
+
+		changes := make([]change, len(subs))
+		for i, id := range ids {
+			*subs[i] = *created[id]
+			changes[i] = change{after: subs[i]}
+		}
+
+		return insertEvents(ctx, tx, model.EventCreated, changes)
+	})
+
+	if err != nil {
+		log.Printf("ERROR: failed to create subscriptions: %v", err)
+		return overlapError(err)
+	}
+
+	return nil
+}
+
+// UpdateBatch replaces several live subscriptions in a single transaction. All rows are locked with one query and
+// the updates are sent as one pgx.Batch. The result holds the error of each item: ErrNotFound, ErrVersionConflict
+// when expectedVersions[i] is set and differs from the stored version, or the error returned by allow for the
+// stored subscription (e.g. an authorization check). An unset AllowOverlap keeps the stored setting. Updated
+// subscriptions are populated with their stored state.
+// With atomic set, nothing is written if any item fails.
+func (r *subscriptionRepo) UpdateBatch(
+	ctx context.Context,
+	subs []*model.Subscription,
+	expectedVersions []*int,
+	allow func(before *model.Subscription) error,
+	atomic bool,
+) ([]error, error) {
+
+	ctx = withQueryName(ctx, "subscriptionRepo.UpdateBatch")
+
+	log.Printf("INFO: updating %d subscriptions", len(subs))
+
+	ids := make([]uuid.UUID, len(subs))
+	for i, sub := range subs {
+		ids[i] = sub.ID
+	}
+
+	results := make([]error, len(subs))
+
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		locked, err := selectSubscriptions(ctx, tx, ids, false, true)
+		if err != nil {
+			return err
+		}
+
+		var (
+			batch   pgx.Batch
+			changes []change
+		)
+
+		for i, sub := range subs {
+			before, ok := locked[sub.ID]
+			results[i] = checkBatchItem(before, ok, expectedVersions[i], allow)
+			if results[i] != nil {
+				continue
+			}
+			delete(locked, sub.ID)
+
+			if sub.Price != before.Price {
+				batch.Queue(upsertPrice, sub.ID, sub.Price, priceEffectiveFrom(sub))
+			}
+
+			batch.Queue(
+				updateSubscription,
+				sub.ServiceName,
+				sub.Price,
+				sub.Currency,
+				sub.BillingPeriod,
+				sub.StartDate,
+				sub.EndDate,
+				sub.ID,
+				sub.AllowOverlap,
+				sub.ServiceID,
+			).QueryRow(func(row pgx.Row) error {
+				after, err := scanSubscription(row)
+				if err != nil {
+					return err
+				}
+				*sub = *after
+				changes = append(changes, change{before: before, after: after})
+				return nil
+			})
+		}
+
+		if atomic && failed(results) {
+			return errBatchRejected
+		}
+
+		if batch.Len() > 0 {
+			if err := tx.SendBatch(ctx, &batch).Close(); err != nil {
+				return err
+			}
+		}
+
+		return insertEvents(ctx, tx, model.EventUpdated, changes)
+	})
+
+	if err != nil && !errors.Is(err, errBatchRejected) {
+		log.Printf("ERROR: failed to update subscriptions: %v", err)
+		return nil, overlapError(err)
+	}
+
+	return results, nil
+}
+
+// DeleteBatch soft-deletes several live subscriptions with a single UPDATE in one transaction.
+// The result holds the error of each item: ErrNotFound or the error returned by allow for the stored
+// subscription. With atomic set, nothing is deleted if any item fails.
+func (r *subscriptionRepo) DeleteBatch(
+	ctx context.Context,
+	ids []uuid.UUID,
+	allow func(before *model.Subscription) error,
+	atomic bool,
+) ([]error, error) {
+
+	ctx = withQueryName(ctx, "subscriptionRepo.DeleteBatch")
+
+	log.Printf("INFO: deleting %d subscriptions", len(ids))
+
+	query := `
+		UPDATE subscriptions
+		SET deleted_at = now(),
+			version = version + 1,
+			updated_at = now()
+		WHERE id = ANY($1::uuid[])
+		RETURNING ` + subscriptionColumns
+
+	results := make([]error, len(ids))
+
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		locked, err := selectSubscriptions(ctx, tx, ids, false, true)
+		if err != nil {
+			return err
+		}
+
+		befores := make(map[uuid.UUID]*model.Subscription, len(ids))
+		var deleted []uuid.UUID
+
+		for i, id := range ids {
+			before, ok := locked[id]
+			results[i] = checkBatchItem(before, ok, nil, allow)
+			if results[i] != nil {
+				continue
+			}
+			delete(locked, id)
+			befores[id] = before
+			deleted = append(deleted, id)
+		}
+
+		if atomic && failed(results) {
+			return errBatchRejected
+		}
+
+		if len(deleted) == 0 {
+			return nil
+		}
+
+		rows, err := tx.Query(ctx, query, deleted)
+		if err != nil {
+			return err
+		}
+
+		var changes []change
+		for rows.Next() {
+			after, err := scanSubscription(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			changes = append(changes, change{before: befores[after.ID], after: after})
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		return insertEvents(ctx, tx, model.EventDeleted, changes)
+	})
+
+	if err != nil && !errors.Is(err, errBatchRejected) {
+		log.Printf("ERROR: failed to delete subscriptions: %v", err)
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// selectSubscriptions reads the subscriptions with the given IDs inside tx, indexed by ID, optionally locking
+// their rows in ID order. With deleted set, only soft-deleted subscriptions are matched, otherwise only live ones.
+func selectSubscriptions(
+	ctx context.Context,
+	tx pgx.Tx,
+	ids []uuid.UUID,
+	deleted bool,
+	lock bool,
+) (map[uuid.UUID]*model.Subscription, error) {
+
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE id = ANY($1::uuid[])
+		  AND (deleted_at IS NOT NULL) = $2
+		ORDER BY id
+	`
+	if lock {
+		query += ` FOR UPDATE`
+	}
+
+	rows, err := tx.Query(ctx, query, ids, deleted)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[uuid.UUID]*model.Subscription, len(ids))
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		result[sub.ID] = sub
+	}
+
+	return result, rows.Err()
+}
+
+// checkBatchItem returns the error of a batch item whose stored state is before (found reports whether it exists).
+func checkBatchItem(
+	before *model.Subscription,
+	found bool,
+	expectedVersion *int,
+	allow func(before *model.Subscription) error,
+) error {
+	switch {
+	case !found:
+		return ErrNotFound
+	case expectedVersion != nil && before.Version != *expectedVersion:
+		return ErrVersionConflict
+	case allow != nil:
+		return allow(before)
+	}
+	return nil
+}
+
+// failed reports whether any item of a batch failed.
+func failed(results []error) bool {
+	for _, err := range results {
+		if err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ExistingKeys returns which of the given keys match a live subscription.
+func (r *subscriptionRepo) ExistingKeys(ctx context.Context, keys []model.SubscriptionKey) (map[model.SubscriptionKey]bool, error) {
+	ctx = withQueryName(ctx, "subscriptionRepo.ExistingKeys")
+
+	query := `
+		SELECT DISTINCT user_id, service_name, start_date
+		FROM subscriptions
+		WHERE deleted_at IS NULL
+		  AND (user_id, service_name, start_date) IN (
+			SELECT * FROM unnest($1::uuid[], $2::text[], $3::date[])
+		  )
+	`
+
+	userIDs := make([]uuid.UUID, len(keys))
+	names := make([]string, len(keys))
+	dates := make([]time.Time, len(keys))
+	for i, key := range keys {
+		userIDs[i], names[i], dates[i] = key.UserID, key.ServiceName, key.StartDate
+	}
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, userIDs, names, dates)
+	if err != nil {
+		log.Printf("ERROR: failed to look up existing subscriptions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	existing := make(map[model.SubscriptionKey]bool)
+	for rows.Next() {
+		sub := &model.Subscription{}
+		if err := rows.Scan(&sub.UserID, &sub.ServiceName, &sub.StartDate); err != nil {
+			return nil, err
+		}
+		existing[model.KeyOf(sub)] = true
+	}
+
+	return existing, rows.Err()
+}
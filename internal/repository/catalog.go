@@ -0,0 +1,288 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"subscription-service/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CatalogRepository defines the interface for managing the service catalog in the storage.
+type CatalogRepository interface {
+	Create(ctx context.Context, svc *model.Service) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Service, error)
+	List(ctx context.Context, category *string) ([]*model.Service, error)
+	Update(ctx context.Context, svc *model.Service) error
+	Delete(ctx context.Context, id uuid.UUID) error
+	Resolve(ctx context.Context, names []string) (map[string]*model.Service, error)
+}
+
+var (
+	ErrServiceNotFound = errors.New("service not found")
+	// ErrServiceNameTaken is returned when the name or an alias of a service already names another service.
+	ErrServiceNameTaken = errors.New("service name or alias is already used by another service")
+)
+
+// uniqueViolation is the SQLSTATE reported when a unique constraint is violated.
+const uniqueViolation = "23505"
+
+// serviceColumns is the column list read by scanService; aliases other than the canonical name are aggregated
+// from service_aliases, so queries must join it as a and group by s.id.
+const serviceColumns = `s.id, s.name, COALESCE(s.category, ''), s.default_price, s.currency, s.created_at, s.updated_at,
+	COALESCE(array_agg(a.alias ORDER BY a.alias) FILTER (WHERE a.alias <> lower(btrim(s.name))), '{}')`
+
+// scanService reads a service selected with serviceColumns.
+func scanService(row rowScanner) (*model.Service, error) {
+	var svc model.Service
+	err := row.Scan(
+		&svc.ID,
+		&svc.Name,
+		&svc.Category,
+		&svc.DefaultPrice,
+		&svc.Currency,
+		&svc.CreatedAt,
+		&svc.UpdatedAt,
+		&svc.Aliases,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &svc, nil
+}
+
+type catalogRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewCatalogRepository creates a new instance of the service catalog repository using a pgx connection pool.
+func NewCatalogRepository(pool *pgxpool.Pool) CatalogRepository {
+	return &catalogRepo{pool: pool}
+}
+
+// Create inserts a catalog entry together with its names and populates the ID and timestamps.
+// Returns ErrServiceNameTaken if the name or an alias already belongs to another service.
+func (r *catalogRepo) Create(ctx context.Context, svc *model.Service) error {
+	ctx = withQueryName(ctx, "catalogRepo.Create")
+
+	log.Printf("INFO: creating service %q", svc.Name)
+
+	query := `
+		INSERT INTO services (name, category, default_price, currency)
+		VALUES ($1, NULLIF($2::text, ''), $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query, svc.Name, svc.Category, svc.DefaultPrice, svc.Currency).
+			Scan(&svc.ID, &svc.CreatedAt, &svc.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		return insertAliases(ctx, tx, svc)
+	})
+
+	if err != nil {
+		log.Printf("ERROR: failed to create service: %v", err)
+		return nameTakenError(err)
+	}
+
+	log.Printf("INFO: service %s created", svc.ID)
+	return nil
+}
+
+// GetByID retrieves a catalog entry by its ID. Returns ErrServiceNotFound if it does not exist.
+func (r *catalogRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Service, error) {
+	ctx = withQueryName(ctx, "catalogRepo.GetByID")
+
+	log.Printf("INFO: getting service %s", id)
+
+	query := `
+		SELECT ` + serviceColumns + `
+		FROM services s
+		LEFT JOIN service_aliases a ON a.service_id = s.id
+		WHERE s.id = $1
+		GROUP BY s.id
+	`
+
+	svc, err := scanService(conn(ctx, r.pool).QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("WARN: service %s not found", id)
+		return nil, ErrServiceNotFound
+	}
+
+	if err != nil {
+		log.Printf("ERROR: failed to get service %s: %v", id, err)
+		return nil, err
+	}
+
+	return svc, nil
+}
+
+// List returns the catalog entries, optionally of a single category, ordered by name.
+func (r *catalogRepo) List(ctx context.Context, category *string) ([]*model.Service, error) {
+	ctx = withQueryName(ctx, "catalogRepo.List")
+
+	log.Printf("INFO: listing services")
+
+	query := `
+		SELECT ` + serviceColumns + `
+		FROM services s
+		LEFT JOIN service_aliases a ON a.service_id = s.id
+		WHERE ($1::text IS NULL OR s.category = $1)
+		GROUP BY s.id
+		ORDER BY lower(s.name), s.id
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, category)
+	if err != nil {
+		log.Printf("ERROR: list services failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.Service
+
+	for rows.Next() {
+		svc, err := scanService(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, svc)
+	}
+
+	return result, rows.Err()
+}
+
+// Update replaces the fields and names of a catalog entry and populates its timestamps.
+// Subscriptions stay linked to it; their stored service names are left unchanged.
+// Returns ErrServiceNotFound if it does not exist and ErrServiceNameTaken if the name or an alias
+// already belongs to another service.
+func (r *catalogRepo) Update(ctx context.Context, svc *model.Service) error {
+	ctx = withQueryName(ctx, "catalogRepo.Update")
+
+	log.Printf("INFO: updating service %s", svc.ID)
+
+	query := `
+		UPDATE services
+		SET name = $1,
+			category = NULLIF($2::text, ''),
+			default_price = $3,
+			currency = $4,
+			updated_at = now()
+		WHERE id = $5
+		RETURNING created_at, updated_at
+	`
+
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query, svc.Name, svc.Category, svc.DefaultPrice, svc.Currency, svc.ID).
+			Scan(&svc.CreatedAt, &svc.UpdatedAt)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrServiceNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, `DELETE FROM service_aliases WHERE service_id = $1`, svc.ID); err != nil {
+			return err
+		}
+
+		return insertAliases(ctx, tx, svc)
+	})
+
+	if err != nil && !errors.Is(err, ErrServiceNotFound) {
+		log.Printf("ERROR: failed to update service %s: %v", svc.ID, err)
+	}
+
+	return nameTakenError(err)
+}
+
+// Delete removes a catalog entry by its ID; linked subscriptions keep their service name but are unlinked.
+// Returns ErrServiceNotFound if no record was deleted.
+func (r *catalogRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx = withQueryName(ctx, "catalogRepo.Delete")
+
+	log.Printf("INFO: deleting service %s", id)
+
+	cmd, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM services WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("ERROR: failed to delete service %s: %v", id, err)
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		log.Printf("WARN: service %s not found for delete", id)
+		return ErrServiceNotFound
+	}
+
+	return nil
+}
+
+// Resolve looks up the catalog entries named by the given service names (by canonical name or alias, compared
+// normalized) and returns them keyed by each of their normalized names. Names without an entry are absent.
+func (r *catalogRepo) Resolve(ctx context.Context, names []string) (map[string]*model.Service, error) {
+	ctx = withQueryName(ctx, "catalogRepo.Resolve")
+
+	normalized := make([]string, len(names))
+	for i, name := range names {
+		normalized[i] = model.NormalizeServiceName(name)
+	}
+
+	query := `
+		SELECT ` + serviceColumns + `
+		FROM services s
+		LEFT JOIN service_aliases a ON a.service_id = s.id
+		WHERE s.id IN (SELECT service_id FROM service_aliases WHERE alias = ANY($1::text[]))
+		GROUP BY s.id
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, normalized)
+	if err != nil {
+		log.Printf("ERROR: resolve service names failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]*model.Service)
+
+	for rows.Next() {
+		svc, err := scanService(rows)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range svc.Names() {
+			result[name] = svc
+		}
+	}
+
+	return result, rows.Err()
+}
+
+// insertAliases stores every name of the service in service_aliases.
+func insertAliases(ctx context.Context, tx pgx.Tx, svc *model.Service) error {
+	names := svc.Names()
+
+	rows := make([][]any, len(names))
+	for i, name := range names {
+		rows[i] = []any{name, svc.ID}
+	}
+
+	_, err := tx.CopyFrom(ctx, pgx.Identifier{"service_aliases"}, []string{"alias", "service_id"}, pgx.CopyFromRows(rows))
+	return err
+}
+
+// nameTakenError returns ErrServiceNameTaken for unique violations of service_aliases and err otherwise.
+func nameTakenError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation && pgErr.TableName == "service_aliases" {
+		return ErrServiceNameTaken
+	}
+	return err
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"subscription-service/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// CurrencyRateRepository defines the interface for managing currency conversion rates in the storage.
+type CurrencyRateRepository interface {
+	Upsert(ctx context.Context, rate *model.CurrencyRate) error
+	List(ctx context.Context, baseCurrency, quoteCurrency *string) ([]*model.CurrencyRate, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+var (
+	ErrRateNotFound = errors.New("currency rate not found")
+)
+
+type currencyRateRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewCurrencyRateRepository creates a new instance of the currency rate repository using a pgx connection pool.
+func NewCurrencyRateRepository(pool *pgxpool.Pool) CurrencyRateRepository {
+	return &currencyRateRepo{pool: pool}
+}
+
+// Upsert stores a rate for the currency pair and month, replacing the existing rate for the same month.
+// It populates the ID and creation timestamp.
+func (r *currencyRateRepo) Upsert(ctx context.Context, rate *model.CurrencyRate) error {
+	ctx = withQueryName(ctx, "currencyRateRepo.Upsert")
+
+	log.Printf("INFO: upserting currency rate %s/%s", rate.BaseCurrency, rate.QuoteCurrency)
+
+	query := `
+		INSERT INTO currency_rates (base_currency, quote_currency, rate, effective_from)
+		VALUES ($1, $2, $3, date_trunc('month', $4::date))
+		ON CONFLICT (base_currency, quote_currency, effective_from)
+		DO UPDATE SET rate = EXCLUDED.rate, created_at = now()
+		RETURNING id, effective_from, created_at
+	`
+
+	err := conn(ctx, r.pool).QueryRow(
+		ctx,
+		query,
+		rate.BaseCurrency,
+		rate.QuoteCurrency,
+		rate.Rate,
+		rate.EffectiveFrom,
+	).Scan(&rate.ID, &rate.EffectiveFrom, &rate.CreatedAt)
+
+	if err != nil {
+		log.Printf("ERROR: failed to upsert currency rate: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// List returns currency rates filtered by optional base and quote currencies,
+// ordered by currency pair and effective month.
+func (r *currencyRateRepo) List(ctx context.Context, baseCurrency, quoteCurrency *string) ([]*model.CurrencyRate, error) {
+	ctx = withQueryName(ctx, "currencyRateRepo.List")
+
+	log.Printf("INFO: listing currency rates")
+
+	query := `
+		SELECT id, base_currency, quote_currency, rate, effective_from, created_at
+		FROM currency_rates
+		WHERE ($1::text IS NULL OR base_currency = $1)
+		  AND ($2::text IS NULL OR quote_currency = $2)
+		ORDER BY base_currency, quote_currency, effective_from
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, baseCurrency, quoteCurrency)
+	if err != nil {
+		log.Printf("ERROR: list currency rates failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.CurrencyRate
+
+	for rows.Next() {
+		var rate model.CurrencyRate
+		if err := rows.Scan(
+			&rate.ID,
+			&rate.BaseCurrency,
+			&rate.QuoteCurrency,
+			&rate.Rate,
+			&rate.EffectiveFrom,
+			&rate.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		result = append(result, &rate)
+	}
+
+	return result, rows.Err()
+}
+
+// Delete removes a currency rate by its ID. Returns ErrRateNotFound if no record was deleted.
+func (r *currencyRateRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx = withQueryName(ctx, "currencyRateRepo.Delete")
+
+	log.Printf("INFO: deleting currency rate %s", id)
+
+	cmd, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM currency_rates WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("ERROR: failed to delete currency rate %s: %v", id, err)
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		log.Printf("WARN: currency rate %s not found for delete", id)
+		return ErrRateNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"subscription-service/internal/model"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// exportFetchSize is the number of rows fetched from the export cursor at a time.
+const exportFetchSize = 500
+
+// Export calls fn for every subscription matching the filter, in the filter's sort order with the ID breaking
+// ties. Rows are read through a server-side cursor in a read-only transaction, exportFetchSize at a time, so
+// memory use does not depend on the number of subscriptions. Inside a unit of work the cursor is opened in a
+// savepoint of its transaction instead, so the export sees the unit's own writes and snapshot. Export stops at
+// the first error returned by fn.
+func (r *subscriptionRepo) Export(
+	ctx context.Context,
+	filter model.SubscriptionFilter,
+	fn func(sub *model.Subscription) error,
+) error {
+
+	ctx = withQueryName(ctx, "subscriptionRepo.Export")
+
+	log.Printf("INFO: exporting subscriptions")
+
+	if filter.Sort.Field == "" {
+		filter.Sort = model.DefaultSort
+	}
+
+	sort, ok := sortColumns[filter.Sort.Field]
+	if !ok {
+		return model.ErrInvalidSort
+	}
+
+	direction := "ASC"
+	if filter.Sort.Desc {
+		direction = "DESC"
+	}
+
+	var args queryArgs
+	where := filterConditions(filter, &args)
+
+	query := fmt.Sprintf(`
+		DECLARE subscription_export NO SCROLL CURSOR FOR
+		SELECT %s
+		FROM subscriptions
+		WHERE %s
+		ORDER BY %s %s, id %s
+	`, subscriptionColumns, where, sort.expr, direction, direction)
+
+	fetch := fmt.Sprintf(`FETCH %d FROM subscription_export`, exportFetchSize)
+
+	export := func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, query, args...); err != nil {
+			return err
+		}
+
+		for {
+			rows, err := tx.Query(ctx, fetch)
+			if err != nil {
+				return err
+			}
+
+			fetched := 0
+			for rows.Next() {
+				sub, err := scanSubscription(rows)
+				if err == nil {
+					err = fn(sub)
+				}
+				if err != nil {
+					rows.Close()
+					return err
+				}
+				fetched++
+			}
+			rows.Close()
+
+			if err := rows.Err(); err != nil {
+				return err
+			}
+			if fetched < exportFetchSize {
+				return nil
+			}
+		}
+	}
+
+	var err error
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		err = pgx.BeginFunc(ctx, conn(ctx, r.pool), export)
+	} else {
+		err = pgx.BeginTxFunc(ctx, r.pool, pgx.TxOptions{AccessMode: pgx.ReadOnly}, export)
+	}
+
+	if err != nil {
+		log.Printf("ERROR: export subscriptions failed: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,294 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"subscription-service/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// OutboxRepository defines the interface for enqueueing time-based events to the event outbox and relaying
+// the outbox to the message broker.
+type OutboxRepository interface {
+	EnqueueExpired(ctx context.Context, since, until time.Time) (int64, error)
+	Relay(ctx context.Context, limit int, lease time.Duration, publish func(ctx context.Context, event *model.Event) error) (int, error)
+}
+
+type outboxRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewOutboxRepository creates a new instance of the outbox repository using a pgx connection pool.
+func NewOutboxRepository(pool *pgxpool.Pool) OutboxRepository {
+	return &outboxRepo{pool: pool}
+}
+
+// eventPayload encodes the data of the lifecycle event describing a change: the subscription after the change
+// (before it for purges) and, when both states exist, the previous state.
+func eventPayload(c change) ([]byte, error) {
+	var data model.EventData
+
+	if c.after != nil {
+		data.Subscription = model.ToResponse(c.after)
+		if c.before != nil {
+			previous := model.ToResponse(c.before)
+			data.Previous = &previous
+		}
+	} else {
+		data.Subscription = model.ToResponse(c.before)
+	}
+
+	return json.Marshal(data)
+}
+
+// subject returns the subscription a change is about.
+func (c change) subject() *model.Subscription {
+	if c.after != nil {
+		return c.after
+	}
+	return c.before
+}
+
+// enqueueEvent writes the lifecycle event describing a change to the event outbox inside tx, so that the event
+// is published if and only if the change is committed.
+func enqueueEvent(ctx context.Context, tx pgx.Tx, eventType string, c change) error {
+	payload, err := eventPayload(c)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO event_outbox (event_type, subscription_id, payload) VALUES ($1, $2, $3)`,
+		eventType,
+		c.subject().ID,
+		payload,
+	)
+	return err
+}
+
+// enqueueEvents writes the lifecycle events of changes of the same kind to the event outbox with a single COPY.
+func enqueueEvents(ctx context.Context, tx pgx.Tx, eventType string, changes []change) error {
+	rows := make([][]any, 0, len(changes))
+
+	for _, c := range changes {
+		payload, err := eventPayload(c)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, []any{eventType, c.subject().ID, payload})
+	}
+
+	_, err := tx.CopyFrom(
+		ctx,
+		pgx.Identifier{"event_outbox"},
+		[]string{"event_type", "subscription_id", "payload"},
+		pgx.CopyFromRows(rows),
+	)
+	return err
+}
+
+// EnqueueExpired writes an expiry event to the event outbox for every live subscription whose end month is over
+// and that became inactive in (since, until], i.e. whose first inactive day falls into that range. Each expiry
+// is enqueued once; it returns the number of new events.
+func (r *outboxRepo) EnqueueExpired(ctx context.Context, since, until time.Time) (int64, error) {
+	ctx = withQueryName(ctx, "outboxRepo.EnqueueExpired")
+
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions
+		WHERE deleted_at IS NULL
+		  AND end_date IS NOT NULL
+		  AND (date_trunc('month', end_date) + interval '1 month')::date > $1::date
+		  AND (date_trunc('month', end_date) + interval '1 month')::date <= $2::date
+	`
+
+	insert := `
+		INSERT INTO event_outbox (event_type, subscription_id, payload, dedup_key)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (dedup_key) DO NOTHING
+	`
+
+	var enqueued int64
+
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, since, until)
+		if err != nil {
+			return err
+		}
+
+		var expired []*model.Subscription
+		for rows.Next() {
+			sub, err := scanSubscription(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			expired = append(expired, sub)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, sub := range expired {
+			payload, err := eventPayload(change{after: sub})
+			if err != nil {
+				return err
+			}
+
+			key := model.EventTypeExpired + ":" + sub.ID.String() + ":" + sub.EndsAt().Format("2006-01-02")
+			cmd, err := tx.Exec(ctx, insert, model.EventTypeExpired, sub.ID, payload, key)
+			if err != nil {
+				return err
+			}
+			enqueued += cmd.RowsAffected()
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("ERROR: failed to enqueue expiry events: %v", err)
+		return 0, err
+	}
+
+	return enqueued, nil
+}
+
+// Relay claims up to limit unpublished outbox events, oldest first, for lease and passes them to publish in order,
+// marking each published once publish returns. No rows are locked while publish talks to the broker: the claim is
+// committed first, and concurrent relays skip claimed events until the claim expires. The claim of an event is
+// renewed just before it is published and checked again when it is marked, so a relay whose claim expired and
+// was taken over stops instead of publishing or marking events that another relay now owns. Relaying stops at the first event that fails to publish and releases the events left for
+// the next relay. An event is published at least once; events claimed by concurrent relays may be published out
+// of order. It returns the number of events published.
+func (r *outboxRepo) Relay(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+	publish func(ctx context.Context, event *model.Event) error,
+) (int, error) {
+
+	ctx = withQueryName(ctx, "outboxRepo.Relay")
+
+	claimID := uuid.New()
+	events, err := r.claim(ctx, claimID, lease, limit)
+	if err != nil {
+		log.Printf("ERROR: failed to claim outbox events: %v", err)
+		return 0, err
+	}
+
+	published := 0
+	for _, e := range events {
+		renewed, err := r.renew(ctx, e.ID, claimID, lease)
+		if err != nil {
+			log.Printf("ERROR: failed to renew claim of outbox event %d: %v", e.ID, err)
+			return published, err
+		}
+		if !renewed {
+			log.Printf("WARN: claim of outbox event %d expired before it was published", e.ID)
+			break
+		}
+
+		if err := publish(ctx, e); err != nil {
+			r.release(ctx, claimID)
+			return published, err
+		}
+
+		cmd, err := conn(ctx, r.pool).Exec(
+			ctx,
+			`UPDATE event_outbox SET published_at = now() WHERE id = $1 AND claim_id = $2 AND published_at IS NULL`,
+			e.ID,
+			claimID,
+		)
+		if err != nil {
+			log.Printf("ERROR: failed to mark outbox event %d published: %v", e.ID, err)
+			return published, err
+		}
+		published++
+		if cmd.RowsAffected() == 0 {
+			log.Printf("WARN: claim of outbox event %d was taken over while it was published", e.ID)
+			break
+		}
+	}
+
+	return published, nil
+}
+
+// claim marks up to limit unpublished events that are not claimed by another relay as claimed by claimID until
+// lease from now and returns them in order.
+func (r *outboxRepo) claim(ctx context.Context, claimID uuid.UUID, lease time.Duration, limit int) ([]*model.Event, error) {
+	query := `
+		WITH due AS (
+			SELECT id
+			FROM event_outbox
+			WHERE published_at IS NULL AND (claimed_until IS NULL OR claimed_until <= now())
+			ORDER BY id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		), claimed AS (
+			UPDATE event_outbox
+			SET claim_id = $1, claimed_until = now() + make_interval(secs => $2)
+			FROM due
+			WHERE event_outbox.id = due.id
+			RETURNING event_outbox.id, event_outbox.event_type, event_outbox.subscription_id,
+				event_outbox.created_at, event_outbox.payload
+		)
+		SELECT id, event_type, subscription_id, created_at, payload
+		FROM claimed
+		ORDER BY id
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, claimID, lease.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*model.Event
+	for rows.Next() {
+		var e model.Event
+		if err := rows.Scan(&e.ID, &e.Type, &e.SubscriptionID, &e.CreatedAt, &e.Data); err != nil {
+			return nil, err
+		}
+		events = append(events, &e)
+	}
+
+	return events, rows.Err()
+}
+
+// renew extends the claim of an unpublished event by lease from now. It returns false if the event is no longer
+// claimed by claimID.
+func (r *outboxRepo) renew(ctx context.Context, id int64, claimID uuid.UUID, lease time.Duration) (bool, error) {
+	query := `
+		UPDATE event_outbox
+		SET claimed_until = now() + make_interval(secs => $3)
+		WHERE id = $1 AND claim_id = $2 AND published_at IS NULL
+	`
+
+	cmd, err := conn(ctx, r.pool).Exec(ctx, query, id, claimID, lease.Seconds())
+	if err != nil {
+		return false, err
+	}
+	return cmd.RowsAffected() == 1, nil
+}
+
+// release gives up the claim of the unpublished events claimed by claimID, so that the next relay retries them
+// without waiting for the claim to expire. Failing to release only delays the retry, so the error is logged.
+func (r *outboxRepo) release(ctx context.Context, claimID uuid.UUID) {
+	query := `
+		UPDATE event_outbox
+		SET claim_id = NULL, claimed_until = NULL
+		WHERE claim_id = $1 AND published_at IS NULL
+	`
+
+	if _, err := conn(ctx, r.pool).Exec(ctx, query, claimID); err != nil {
+		log.Printf("ERROR: failed to release claimed outbox events: %v", err)
+	}
+}
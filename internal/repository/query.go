@@ -0,0 +1,18 @@
+package repository
+
+import "context"
+
+type queryNameKey struct{}
+
+// withQueryName returns a context naming the repository method, such as "subscriptionRepo.Create", that issues
+// the queries run with it. Every repository method sets its name before it runs a query.
+func withQueryName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, queryNameKey{}, name)
+}
+
+// QueryName returns the name of the repository method that issued a query run with ctx, or "" when the query
+// was not issued by one. Query tracers label queries with it.
+func QueryName(ctx context.Context) string {
+	name, _ := ctx.Value(queryNameKey{}).(string)
+	return name
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"log"
+
+	"subscription-service/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ReminderRepository defines the interface for recording the reminders emitted by the reminder scheduler.
+type ReminderRepository interface {
+	Claim(ctx context.Context, reminder *model.Reminder) (bool, error)
+	Release(ctx context.Context, reminder *model.Reminder) error
+}
+
+type reminderRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewReminderRepository creates a new instance of the reminder repository using a pgx connection pool.
+func NewReminderRepository(pool *pgxpool.Pool) ReminderRepository {
+	return &reminderRepo{pool: pool}
+}
+
+// Claim records the reminder as sent. It returns false if the reminder of the same kind for the same
+// subscription and date has already been claimed, so that concurrent schedulers send it only once.
+func (r *reminderRepo) Claim(ctx context.Context, reminder *model.Reminder) (bool, error) {
+	ctx = withQueryName(ctx, "reminderRepo.Claim")
+
+	query := `
+		INSERT INTO sent_reminders (subscription_id, kind, due_date)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`
+
+	cmd, err := conn(ctx, r.pool).Exec(ctx, query, reminder.Subscription.ID, reminder.Kind, reminder.Date)
+	if err != nil {
+		log.Printf("ERROR: failed to claim %s reminder of subscription %s: %v", reminder.Kind, reminder.Subscription.ID, err)
+		return false, err
+	}
+
+	return cmd.RowsAffected() == 1, nil
+}
+
+// Release forgets a claimed reminder whose delivery failed so that it is claimed again later.
+func (r *reminderRepo) Release(ctx context.Context, reminder *model.Reminder) error {
+	ctx = withQueryName(ctx, "reminderRepo.Release")
+
+	query := `
+		DELETE FROM sent_reminders
+		WHERE subscription_id = $1 AND kind = $2 AND due_date = $3
+	`
+
+	if _, err := conn(ctx, r.pool).Exec(ctx, query, reminder.Subscription.ID, reminder.Kind, reminder.Date); err != nil {
+		log.Printf("ERROR: failed to release %s reminder of subscription %s: %v", reminder.Kind, reminder.Subscription.ID, err)
+		return err
+	}
+
+	return nil
+}
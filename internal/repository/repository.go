@@ -3,42 +3,259 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"subscription-service/internal/model"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // SubscriptionRepository defines the interface for managing subscription data in the storage.
 type SubscriptionRepository interface {
 	Create(ctx context.Context, sub *model.Subscription) error
+	CreateBatch(ctx context.Context, subs []*model.Subscription) error
 	GetByID(ctx context.Context, id uuid.UUID) (*model.Subscription, error)
-	Update(ctx context.Context, sub *model.Subscription) error
-	Delete(ctx context.Context, id uuid.UUID) error
-	List(
+	Owner(ctx context.Context, id uuid.UUID) (uuid.UUID, error)
+	FindOverlaps(ctx context.Context, subs []*model.Subscription) ([]uuid.UUID, []bool, error)
+	Update(ctx context.Context, sub *model.Subscription, expectedVersion *int) error
+	Delete(ctx context.Context, id uuid.UUID, expectedVersion *int) error
+	UpdateBatch(
 		ctx context.Context,
-		userID *uuid.UUID,
-		serviceName *string,
-		limit, offset int,
-	) ([]*model.Subscription, error)
-
-	AggregateCost(
-		ctx context.Context,
-		userID *uuid.UUID,
-		serviceName *string,
-		from time.Time,
-		to time.Time,
-	) (int, error)
+		subs []*model.Subscription,
+		expectedVersions []*int,
+		allow func(before *model.Subscription) error,
+		atomic bool,
+	) ([]error, error)
+	DeleteBatch(ctx context.Context, ids []uuid.UUID, allow func(before *model.Subscription) error, atomic bool) ([]error, error)
+	ExistingKeys(ctx context.Context, keys []model.SubscriptionKey) (map[model.SubscriptionKey]bool, error)
+	Restore(ctx context.Context, id uuid.UUID) (*model.Subscription, error)
+	Purge(ctx context.Context, deletedBefore time.Time) (int64, error)
+	List(ctx context.Context, filter model.SubscriptionFilter, page model.Page) (*model.SubscriptionPage, error)
+	Export(ctx context.Context, filter model.SubscriptionFilter, fn func(sub *model.Subscription) error) error
+
+	SchedulePrice(ctx context.Context, change *model.PriceChange) error
+	ListPrices(ctx context.Context, subscriptionID uuid.UUID) ([]*model.PriceChange, error)
+	PriceHistories(ctx context.Context, subscriptionIDs []uuid.UUID) (map[uuid.UUID][]*model.PriceChange, error)
+
+	AggregateCost(ctx context.Context, query model.CostQuery) ([]model.CostBucket, error)
 }
 
 var (
-	ErrNotFound = errors.New("subscription not found")
+	ErrNotFound        = errors.New("subscription not found")
+	ErrVersionConflict = errors.New("subscription version does not match")
+	// ErrOverlap is returned when a write violates the subscriptions_no_overlap constraint: a live subscription
+	// of the same user to the same service overlaps the written period and neither allows overlaps.
+	ErrOverlap = errors.New("subscription overlaps another subscription to the same service")
 )
 
+// groupColumns maps summary grouping keys to the SQL expressions used in AggregateCost.
+var groupColumns = map[string]string{
+	model.GroupByMonth:    "month::date",
+	model.GroupByService:  "service_name",
+	model.GroupByUser:     "user_id",
+	model.GroupByCategory: "category",
+}
+
+// currentPrice is the price in force in the current month according to subscription_prices, falling back
+// to the stored price for subscriptions that start in the future.
+const currentPrice = `COALESCE((
+		SELECT sp.price
+		FROM subscription_prices sp
+		WHERE sp.subscription_id = subscriptions.id
+		  AND sp.effective_from <= date_trunc('month', now())
+		ORDER BY sp.effective_from DESC
+		LIMIT 1
+	), price)`
+
+// subscriptionColumns is the column list read by scanSubscription.
+const subscriptionColumns = `id, user_id, service_name, ` + currentPrice + `,
+	currency, billing_period, start_date, end_date, version, created_at, updated_at, deleted_at, allow_overlap, service_id`
+
+// sortColumn describes how List orders by a sortable field: the SQL expression, the type a cursor value
+// is cast to, and how the cursor value is taken from the last subscription of a page.
+type sortColumn struct {
+	expr  string
+	cast  string
+	value func(sub *model.Subscription) string
+}
+
+// sortColumns whitelists the fields List can be sorted by.
+var sortColumns = map[string]sortColumn{
+	model.SortCreatedAt: {
+		expr:  "created_at",
+		cast:  "timestamp",
+		value: func(sub *model.Subscription) string { return sub.CreatedAt.Format("2006-01-02T15:04:05.999999") },
+	},
+	model.SortPrice: {
+		expr:  currentPrice,
+		cast:  "bigint",
+		value: func(sub *model.Subscription) string { return strconv.Itoa(sub.Price) },
+	},
+	model.SortStartDate: {
+		expr:  "start_date",
+		cast:  "date",
+		value: func(sub *model.Subscription) string { return sub.StartDate.Format("2006-01-02") },
+	},
+	model.SortServiceName: {
+		expr:  "service_name",
+		cast:  "text",
+		value: func(sub *model.Subscription) string { return sub.ServiceName },
+	},
+}
+
+// queryArgs collects positional query arguments.
+type queryArgs []any
+
+// add appends an argument and returns its placeholder.
+func (a *queryArgs) add(v any) string {
+	*a = append(*a, v)
+	return "$" + strconv.Itoa(len(*a))
+}
+
+// likeEscaper escapes the LIKE wildcards of user input.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// filterConditions translates the filter into SQL conditions joined by AND; values are passed as arguments.
+func filterConditions(f model.SubscriptionFilter, args *queryArgs) string {
+	conds := []string{"(deleted_at IS NOT NULL) = " + args.add(f.Deleted)}
+
+	if len(f.UserIDs) > 0 {
+		conds = append(conds, "user_id = ANY("+args.add(f.UserIDs)+"::uuid[])")
+	}
+	if f.ServiceName != nil {
+		conds = append(conds, "service_name = "+args.add(*f.ServiceName))
+	}
+	if f.ServiceNamePrefix != nil {
+		conds = append(conds, "service_name ILIKE "+args.add(likeEscaper.Replace(*f.ServiceNamePrefix)+"%"))
+	}
+	if f.ServiceID != nil {
+		conds = append(conds, "service_id = "+args.add(*f.ServiceID))
+	}
+	if f.Category != nil {
+		conds = append(conds, "service_id IN (SELECT id FROM services WHERE category = "+args.add(*f.Category)+")")
+	}
+	if f.MinPrice != nil {
+		conds = append(conds, currentPrice+" >= "+args.add(*f.MinPrice))
+	}
+	if f.MaxPrice != nil {
+		conds = append(conds, currentPrice+" <= "+args.add(*f.MaxPrice))
+	}
+	if f.ActiveAt != nil {
+		month := args.add(*f.ActiveAt)
+		conds = append(conds, fmt.Sprintf(
+			"date_trunc('month', start_date) <= date_trunc('month', %[1]s::date)"+
+				" AND (end_date IS NULL OR date_trunc('month', end_date) >= date_trunc('month', %[1]s::date))",
+			month,
+		))
+	}
+	if f.StartFrom != nil {
+		conds = append(conds, "start_date >= "+args.add(*f.StartFrom)+"::date")
+	}
+	if f.StartTo != nil {
+		conds = append(conds, "start_date <= "+args.add(*f.StartTo)+"::date")
+	}
+	if f.EndFrom != nil {
+		conds = append(conds, "end_date >= "+args.add(*f.EndFrom)+"::date")
+	}
+	if f.EndTo != nil {
+		conds = append(conds, "end_date <= "+args.add(*f.EndTo)+"::date")
+	}
+	if f.HasEndDate != nil {
+		conds = append(conds, "(end_date IS NOT NULL) = "+args.add(*f.HasEndDate))
+	}
+
+	return strings.Join(conds, "\n\t\t  AND ")
+}
+
+// upsertPrice stores the price in force from the given month ($3), replacing an existing entry for that month.
+const upsertPrice = `
+	INSERT INTO subscription_prices (subscription_id, price, effective_from)
+	VALUES ($1, $2, date_trunc('month', $3::date))
+	ON CONFLICT (subscription_id, effective_from)
+	DO UPDATE SET price = EXCLUDED.price, created_at = now()
+	RETURNING id, effective_from, created_at
+`
+
+// updateSubscription replaces the fields of a subscription ($7) and increments its version.
+// $8 tells whether the subscription may overlap others to the same service and $9 is its catalog entry.
+const updateSubscription = `
+	UPDATE subscriptions
+	SET service_name = $1,
+		price = $2,
+		currency = COALESCE(NULLIF($3::text, ''), 'RUB'),
+		billing_period = COALESCE(NULLIF($4::text, ''), 'monthly'),
+		start_date = $5,
+		end_date = $6,
+		allow_overlap = COALESCE($8, allow_overlap),
+		service_id = $9,
+		version = version + 1,
+		updated_at = now()
+	WHERE id = $7
+	RETURNING ` + subscriptionColumns
+
+// priceEffectiveFrom returns the month from which a changed price applies: the current month,
+// or the start month if the subscription starts later.
+func priceEffectiveFrom(sub *model.Subscription) time.Time {
+	effectiveFrom := time.Now()
+	if sub.StartDate.After(effectiveFrom) {
+		effectiveFrom = sub.StartDate
+	}
+	return effectiveFrom
+}
+
+// overlapConstraint is the exclusion constraint that keeps subscriptions to the same service from overlapping;
+// exclusionViolation is the SQLSTATE reported when it is violated.
+const (
+	overlapConstraint  = "subscriptions_no_overlap"
+	exclusionViolation = "23P01"
+)
+
+// overlapError returns ErrOverlap for violations of overlapConstraint and err otherwise.
+func overlapError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == exclusionViolation && pgErr.ConstraintName == overlapConstraint {
+		return ErrOverlap
+	}
+	return err
+}
+
+// rowScanner is implemented by both pgx.Row and pgx.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanSubscription reads a subscription selected with subscriptionColumns.
+func scanSubscription(row rowScanner) (*model.Subscription, error) {
+	var sub model.Subscription
+	err := row.Scan(
+		&sub.ID,
+		&sub.UserID,
+		&sub.ServiceName,
+		&sub.Price,
+		&sub.Currency,
+		&sub.BillingPeriod,
+		&sub.StartDate,
+		&sub.EndDate,
+		&sub.Version,
+		&sub.CreatedAt,
+		&sub.UpdatedAt,
+		&sub.DeletedAt,
+		&sub.AllowOverlap,
+		&sub.ServiceID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &sub, nil
+}
+
 type subscriptionRepo struct {
 	pool *pgxpool.Pool
 }
@@ -48,56 +265,71 @@ func NewSubscriptionRepository(pool *pgxpool.Pool) SubscriptionRepository {
 	return &subscriptionRepo{pool: pool}
 }
 
-// Create inserts a new subscription record into the database and populates the ID and timestamps.
+// Create inserts a new subscription record into the database and populates the ID, billing period and timestamps.
+// An empty currency is stored as RUB and an empty billing period as monthly. The price is recorded as the first
+// entry of the price history, and the creation in the audit log, within the same transaction.
 func (r *subscriptionRepo) Create(ctx context.Context, sub *model.Subscription) error {
+	ctx = withQueryName(ctx, "subscriptionRepo.Create")
+
 	log.Printf("INFO: creating subscription for user %s", sub.UserID)
 
 	query := `
-		INSERT INTO subscriptions (user_id, service_name, price, start_date, end_date)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, created_at, updated_at
+		INSERT INTO subscriptions (
+			user_id, service_name, price, currency, billing_period, start_date, end_date, allow_overlap, service_id
+		)
+		VALUES ($1, $2, $3, COALESCE(NULLIF($4::text, ''), 'RUB'), COALESCE(NULLIF($5::text, ''), 'monthly'), $6, $7, COALESCE($8, false), $9)
+		RETURNING id, currency, billing_period, version, created_at, updated_at
 	`
 
-	err := r.pool.QueryRow(
-		ctx,
-		query,
-		sub.UserID,
-		sub.ServiceName,
-		sub.Price,
-		sub.StartDate,
-		sub.EndDate,
-	).Scan(&sub.ID, &sub.CreatedAt, &sub.UpdatedAt)
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		err := tx.QueryRow(
+			ctx,
+			query,
+			sub.UserID,
+			sub.ServiceName,
+			sub.Price,
+			sub.Currency,
+			sub.BillingPeriod,
+			sub.StartDate,
+			sub.EndDate,
+			sub.AllowOverlap,
+			sub.ServiceID,
+		).Scan(&sub.ID, &sub.Currency, &sub.BillingPeriod, &sub.Version, &sub.CreatedAt, &sub.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.Exec(ctx, upsertPrice, sub.ID, sub.Price, sub.StartDate); err != nil {
+			return err
+		}
+
+		return insertEvent(ctx, tx, model.EventCreated, nil, sub)
+	})
 
 	if err != nil {
 		log.Printf("ERROR: failed to create subscription: %v", err)
-		return err
+		return overlapError(err)
 	}
 
 	log.Printf("INFO: subscription %s created", sub.ID)
 	return nil
 }
 
-// GetByID retrieves a single subscription by its unique identifier. Returns ErrNotFound if no record exists.
+// GetByID retrieves a single subscription by its unique identifier.
+// Returns ErrNotFound if no record exists or the subscription is soft-deleted.
 func (r *subscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	ctx = withQueryName(ctx, "subscriptionRepo.GetByID")
+
 	log.Printf("INFO: getting subscription %s", id)
 
 	query := `
-		SELECT id, user_id, service_name, price, start_date, end_date, created_at, updated_at
+		SELECT ` + subscriptionColumns + `
 		FROM subscriptions
 		WHERE id = $1
+		  AND deleted_at IS NULL
 	`
 
-	var sub model.Subscription
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&sub.ID,
-		&sub.UserID,
-		&sub.ServiceName,
-		&sub.Price,
-		&sub.StartDate,
-		&sub.EndDate,
-		&sub.CreatedAt,
-		&sub.UpdatedAt,
-	)
+	sub, err := scanSubscription(conn(ctx, r.pool).QueryRow(ctx, query, id))
 
 	if errors.Is(err, pgx.ErrNoRows) {
 		log.Printf("WARN: subscription %s not found", id)
@@ -109,159 +341,663 @@ func (r *subscriptionRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Su
 		return nil, err
 	}
 
-	return &sub, nil
+	return sub, nil
 }
 
-// Update modifies an existing subscription record. Returns ErrNotFound if the subscription ID does not exist.
-func (r *subscriptionRepo) Update(ctx context.Context, sub *model.Subscription) error {
-	log.Printf("INFO: updating subscription %s", sub.ID)
+// Owner returns the ID of the user a subscription belongs to, whether it is live or soft-deleted.
+// Returns ErrNotFound if no record exists.
+func (r *subscriptionRepo) Owner(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	ctx = withQueryName(ctx, "subscriptionRepo.Owner")
+
+	var userID uuid.UUID
+
+	err := conn(ctx, r.pool).QueryRow(ctx, `SELECT user_id FROM subscriptions WHERE id = $1`, id).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("WARN: subscription %s not found", id)
+		return uuid.Nil, ErrNotFound
+	}
+
+	if err != nil {
+		log.Printf("ERROR: failed to get owner of subscription %s: %v", id, err)
+		return uuid.Nil, err
+	}
+
+	return userID, nil
+}
+
+// FindOverlaps returns, for every given subscription, the ID of a stored live subscription of the same user to the
+// same service (compared trimmed and case-insensitively) whose period overlaps it, or uuid.Nil if there is none,
+// and whether the subscription allows overlaps. An unset AllowOverlap resolves to the stored setting of the
+// subscription being replaced, or false for a new one; subscriptions that allow overlaps are not checked.
+// Stored subscriptions that allow overlaps and those among the given ones (which are being replaced) are ignored.
+func (r *subscriptionRepo) FindOverlaps(ctx context.Context, subs []*model.Subscription) ([]uuid.UUID, []bool, error) {
+	ctx = withQueryName(ctx, "subscriptionRepo.FindOverlaps")
 
 	query := `
-		UPDATE subscriptions
-		SET service_name = $1,
-			price = $2,
-			start_date = $3,
-			end_date = $4,
-			updated_at = now()
-		WHERE id = $5
+		SELECT c.idx, COALESCE(c.allow_overlap, o.allow_overlap, false), x.id
+		FROM unnest($1::uuid[], $2::uuid[], $3::text[], $4::date[], $5::date[], $6::bool[])
+			WITH ORDINALITY AS c(id, user_id, service_name, start_date, end_date, allow_overlap, idx)
+		LEFT JOIN subscriptions o ON o.id = c.id
+		LEFT JOIN LATERAL (
+			SELECT s.id
+			FROM subscriptions s
+			WHERE s.user_id = c.user_id
+			  AND lower(btrim(s.service_name)) = lower(btrim(c.service_name))
+			  AND daterange(s.start_date, s.end_date, '[]') && daterange(c.start_date, c.end_date, '[]')
+			  AND s.deleted_at IS NULL
+			  AND NOT s.allow_overlap
+			  AND s.id <> ALL($1::uuid[])
+			ORDER BY s.start_date, s.id
+			LIMIT 1
+		) x ON NOT COALESCE(c.allow_overlap, o.allow_overlap, false)
 	`
 
-	cmd, err := r.pool.Exec(
-		ctx,
-		query,
-		sub.ServiceName,
-		sub.Price,
-		sub.StartDate,
-		sub.EndDate,
-		sub.ID,
-	)
+	ids := make([]uuid.UUID, len(subs))
+	userIDs := make([]uuid.UUID, len(subs))
+	names := make([]string, len(subs))
+	starts := make([]time.Time, len(subs))
+	ends := make([]*time.Time, len(subs))
+	allows := make([]*bool, len(subs))
+	for i, sub := range subs {
+		ids[i], userIDs[i], names[i], starts[i], ends[i] = sub.ID, sub.UserID, sub.ServiceName, sub.StartDate, sub.EndDate
+		allows[i] = sub.AllowOverlap
+	}
 
+	rows, err := conn(ctx, r.pool).Query(ctx, query, ids, userIDs, names, starts, ends, allows)
 	if err != nil {
-		log.Printf("ERROR: failed to update subscription %s: %v", sub.ID, err)
-		return err
+		log.Printf("ERROR: failed to look up overlapping subscriptions: %v", err)
+		return nil, nil, err
 	}
+	defer rows.Close()
 
-	if cmd.RowsAffected() == 0 {
-		log.Printf("WARN: subscription %s not found for update", sub.ID)
-		return ErrNotFound
+	conflicts := make([]uuid.UUID, len(subs))
+	allowed := make([]bool, len(subs))
+	for rows.Next() {
+		var (
+			idx   int
+			allow bool
+			id    *uuid.UUID
+		)
+		if err := rows.Scan(&idx, &allow, &id); err != nil {
+			return nil, nil, err
+		}
+		allowed[idx-1] = allow
+		if id != nil {
+			conflicts[idx-1] = *id
+		}
 	}
 
-	return nil
+	return conflicts, allowed, rows.Err()
+}
+
+// Update modifies an existing subscription record, increments its version and populates the stored state
+// (including the new version and update timestamp). When expectedVersion is set, the row is only updated if its
+// current version matches; otherwise ErrVersionConflict is returned. Returns ErrNotFound if the subscription ID
+// does not exist. A new price takes effect from the current month (or the start month, if later) so that
+// earlier months keep the price they were charged. An unset AllowOverlap keeps the stored setting. The change is
+// recorded in the audit log within the same transaction.
+func (r *subscriptionRepo) Update(ctx context.Context, sub *model.Subscription, expectedVersion *int) error {
+	ctx = withQueryName(ctx, "subscriptionRepo.Update")
+
+	log.Printf("INFO: updating subscription %s", sub.ID)
+
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		before, err := lockSubscription(ctx, tx, sub.ID, false, expectedVersion)
+		if err != nil {
+			return err
+		}
+
+		if sub.Price != before.Price {
+			if _, err := tx.Exec(ctx, upsertPrice, sub.ID, sub.Price, priceEffectiveFrom(sub)); err != nil {
+				return err
+			}
+		}
+
+		after, err := scanSubscription(tx.QueryRow(
+			ctx,
+			updateSubscription,
+			sub.ServiceName,
+			sub.Price,
+			sub.Currency,
+			sub.BillingPeriod,
+			sub.StartDate,
+			sub.EndDate,
+			sub.ID,
+			sub.AllowOverlap,
+			sub.ServiceID,
+		))
+		if err != nil {
+			return err
+		}
+
+		*sub = *after
+		return insertEvent(ctx, tx, model.EventUpdated, before, after)
+	})
+
+	if err != nil && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrVersionConflict) {
+		log.Printf("ERROR: failed to update subscription %s: %v", sub.ID, err)
+	}
+
+	return overlapError(err)
 }
 
-// Delete removes a subscription record from the database by its ID. Returns ErrNotFound if no record was deleted.
-func (r *subscriptionRepo) Delete(ctx context.Context, id uuid.UUID) error {
+// Delete soft-deletes a subscription by setting deleted_at and incrementing its version; the record is kept
+// until Purge removes it. When expectedVersion is set, the record is only deleted if its current version matches;
+// otherwise ErrVersionConflict is returned. Returns ErrNotFound if no live record was deleted.
+// The deletion is recorded in the audit log within the same transaction.
+func (r *subscriptionRepo) Delete(ctx context.Context, id uuid.UUID, expectedVersion *int) error {
+	ctx = withQueryName(ctx, "subscriptionRepo.Delete")
+
 	log.Printf("INFO: deleting subscription %s", id)
 
-	cmd, err := r.pool.Exec(
-		ctx,
-		`DELETE FROM subscriptions WHERE id = $1`,
-		id,
-	)
+	query := `
+		UPDATE subscriptions
+		SET deleted_at = now(),
+			version = version + 1,
+			updated_at = now()
+		WHERE id = $1
+		RETURNING ` + subscriptionColumns
 
-	if err != nil {
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		before, err := lockSubscription(ctx, tx, id, false, expectedVersion)
+		if err != nil {
+			return err
+		}
+
+		after, err := scanSubscription(tx.QueryRow(ctx, query, id))
+		if err != nil {
+			return err
+		}
+
+		return insertEvent(ctx, tx, model.EventDeleted, before, after)
+	})
+
+	if err != nil && !errors.Is(err, ErrNotFound) && !errors.Is(err, ErrVersionConflict) {
 		log.Printf("ERROR: failed to delete subscription %s: %v", id, err)
-		return err
 	}
 
-	if cmd.RowsAffected() == 0 {
-		log.Printf("WARN: subscription %s not found for delete", id)
-		return ErrNotFound
+	return err
+}
+
+// Restore brings a soft-deleted subscription back and increments its version.
+// Returns ErrNotFound if there is no deleted subscription with the given ID.
+// The restoration is recorded in the audit log within the same transaction.
+func (r *subscriptionRepo) Restore(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	ctx = withQueryName(ctx, "subscriptionRepo.Restore")
+
+	log.Printf("INFO: restoring subscription %s", id)
+
+	query := `
+		UPDATE subscriptions
+		SET deleted_at = NULL,
+			version = version + 1,
+			updated_at = now()
+		WHERE id = $1
+		RETURNING ` + subscriptionColumns
+
+	var sub *model.Subscription
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		before, err := lockSubscription(ctx, tx, id, true, nil)
+		if err != nil {
+			return err
+		}
+
+		sub, err = scanSubscription(tx.QueryRow(ctx, query, id))
+		if err != nil {
+			return err
+		}
+
+		return insertEvent(ctx, tx, model.EventRestored, before, sub)
+	})
+
+	if err != nil {
+		if !errors.Is(err, ErrNotFound) {
+			log.Printf("ERROR: failed to restore subscription %s: %v", id, err)
+		}
+		return nil, overlapError(err)
+	}
+
+	return sub, nil
+}
+
+// Purge permanently removes subscriptions soft-deleted before the given moment and returns their number.
+// Every removed subscription is recorded in the audit log within the same transaction.
+func (r *subscriptionRepo) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	ctx = withQueryName(ctx, "subscriptionRepo.Purge")
+
+	log.Printf("INFO: purging subscriptions deleted before %s", deletedBefore.Format(time.RFC3339))
+
+	query := `
+		DELETE FROM subscriptions
+		WHERE deleted_at IS NOT NULL
+		  AND deleted_at < $1::timestamptz
+		RETURNING ` + subscriptionColumns
+
+	var purged []*model.Subscription
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		rows, err := tx.Query(ctx, query, deletedBefore)
+		if err != nil {
+			return err
+		}
+
+		for rows.Next() {
+			sub, err := scanSubscription(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			purged = append(purged, sub)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, sub := range purged {
+			if err := insertEvent(ctx, tx, model.EventPurged, sub, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("ERROR: failed to purge subscriptions: %v", err)
+		return 0, err
 	}
 
-	return nil
+	log.Printf("INFO: purged %d subscriptions", len(purged))
+	return int64(len(purged)), nil
 }
 
-// List returns a slice of subscriptions based on optional filters (userID, serviceName) with pagination support.
-func (r *subscriptionRepo) List(
-	ctx context.Context,
-	userID *uuid.UUID,
-	serviceName *string,
-	limit, offset int,
-) ([]*model.Subscription, error) {
+// SchedulePrice stores the price of a live subscription in force from change.EffectiveFrom's month onwards,
+// replacing an existing change for the same month, and populates the ID and creation timestamp.
+// Returns ErrNotFound if the subscription does not exist or is soft-deleted.
+func (r *subscriptionRepo) SchedulePrice(ctx context.Context, change *model.PriceChange) error {
+	ctx = withQueryName(ctx, "subscriptionRepo.SchedulePrice")
 
-	log.Printf("INFO: listing subscriptions")
+	log.Printf("INFO: scheduling price change of subscription %s", change.SubscriptionID)
+
+	err := pgx.BeginFunc(ctx, conn(ctx, r.pool), func(tx pgx.Tx) error {
+		if _, err := lockSubscription(ctx, tx, change.SubscriptionID, false, nil); err != nil {
+			return err
+		}
+
+		return tx.QueryRow(
+			ctx,
+			upsertPrice,
+			change.SubscriptionID,
+			change.Price,
+			change.EffectiveFrom,
+		).Scan(&change.ID, &change.EffectiveFrom, &change.CreatedAt)
+	})
+
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		log.Printf("ERROR: failed to schedule price change of subscription %s: %v", change.SubscriptionID, err)
+	}
+
+	return err
+}
+
+// ListPrices returns the price history of a subscription, including scheduled changes, ordered by month.
+func (r *subscriptionRepo) ListPrices(ctx context.Context, subscriptionID uuid.UUID) ([]*model.PriceChange, error) {
+	ctx = withQueryName(ctx, "subscriptionRepo.ListPrices")
+
+	log.Printf("INFO: listing prices of subscription %s", subscriptionID)
 
 	query := `
-		SELECT id, user_id, service_name, price, start_date, end_date, created_at, updated_at
-		FROM subscriptions
-		WHERE ($1::uuid IS NULL OR user_id = $1)
-		  AND ($2::text IS NULL OR service_name = $2)
-		ORDER BY created_at DESC
-		LIMIT $3 OFFSET $4
+		SELECT id, subscription_id, price, effective_from, created_at
+		FROM subscription_prices
+		WHERE subscription_id = $1
+		ORDER BY effective_from
 	`
 
-	rows, err := r.pool.Query(
-		ctx,
-		query,
-		userID,
-		serviceName,
-		limit,
-		offset,
-	)
+	rows, err := conn(ctx, r.pool).Query(ctx, query, subscriptionID)
 	if err != nil {
-		log.Printf("ERROR: list subscriptions failed: %v", err)
+		log.Printf("ERROR: list subscription prices failed: %v", err)
 		return nil, err
 	}
 	defer rows.Close()
 
-	var result []*model.Subscription
+	var result []*model.PriceChange
 
 	for rows.Next() {
-		var sub model.Subscription
+		var change model.PriceChange
 		if err := rows.Scan(
-			&sub.ID,
-			&sub.UserID,
-			&sub.ServiceName,
-			&sub.Price,
-			&sub.StartDate,
-			&sub.EndDate,
-			&sub.CreatedAt,
-			&sub.UpdatedAt,
+			&change.ID,
+			&change.SubscriptionID,
+			&change.Price,
+			&change.EffectiveFrom,
+			&change.CreatedAt,
 		); err != nil {
 			return nil, err
 		}
-		result = append(result, &sub)
+		result = append(result, &change)
 	}
 
-	return result, nil
+	return result, rows.Err()
 }
 
-// AggregateCost calculates the total cost of active subscriptions for a given user and service within a specific time range.
-func (r *subscriptionRepo) AggregateCost(
+// PriceHistories returns the price histories of several subscriptions keyed by subscription ID, each ordered
+// by month. Subscriptions without price changes are absent.
+func (r *subscriptionRepo) PriceHistories(
 	ctx context.Context,
-	userID *uuid.UUID,
-	serviceName *string,
-	from time.Time,
-	to time.Time,
-) (int, error) {
+	subscriptionIDs []uuid.UUID,
+) (map[uuid.UUID][]*model.PriceChange, error) {
+
+	ctx = withQueryName(ctx, "subscriptionRepo.PriceHistories")
+
+	log.Printf("INFO: listing prices of %d subscriptions", len(subscriptionIDs))
+
+	query := `
+		SELECT id, subscription_id, price, effective_from, created_at
+		FROM subscription_prices
+		WHERE subscription_id = ANY($1::uuid[])
+		ORDER BY subscription_id, effective_from
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, subscriptionIDs)
+	if err != nil {
+		log.Printf("ERROR: list price histories failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[uuid.UUID][]*model.PriceChange)
+
+	for rows.Next() {
+		var change model.PriceChange
+		if err := rows.Scan(
+			&change.ID,
+			&change.SubscriptionID,
+			&change.Price,
+			&change.EffectiveFrom,
+			&change.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		result[change.SubscriptionID] = append(result[change.SubscriptionID], &change)
+	}
 
-	log.Printf("INFO: aggregating subscriptions cost")
+	return result, rows.Err()
+}
 
+// lockSubscription reads a subscription inside tx and locks its row until the transaction ends.
+// With deleted set, only a soft-deleted subscription is matched, otherwise only a live one.
+// Returns ErrNotFound if there is no matching subscription and ErrVersionConflict if expectedVersion
+// is set and differs from the stored version.
+func lockSubscription(
+	ctx context.Context,
+	tx pgx.Tx,
+	id uuid.UUID,
+	deleted bool,
+	expectedVersion *int,
+) (*model.Subscription, error) {
 	query := `
-		SELECT COALESCE(SUM(price), 0)
+		SELECT ` + subscriptionColumns + `
 		FROM subscriptions
-		WHERE ($1::uuid IS NULL OR user_id = $1)
-		  AND ($2::text IS NULL OR service_name = $2)
-		  AND start_date <= $4
-		  AND (end_date IS NULL OR end_date >= $3)
+		WHERE id = $1
+		  AND (deleted_at IS NOT NULL) = $2
+		FOR UPDATE
 	`
 
-	var total int
-	err := r.pool.QueryRow(
+	sub, err := scanSubscription(tx.QueryRow(ctx, query, id, deleted))
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("WARN: subscription %s not found", id)
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if expectedVersion != nil && sub.Version != *expectedVersion {
+		log.Printf("WARN: subscription %s version conflict, expected %d, got %d", id, *expectedVersion, sub.Version)
+		return nil, ErrVersionConflict
+	}
+
+	return sub, nil
+}
+
+// List returns a page of subscriptions matching the filter together with the number of subscriptions matching
+// it across all pages. Subscriptions are ordered by the filter's sort field with the ID breaking ties, so that a
+// page can start right after a cursor (keyset pagination) instead of skipping an offset.
+// Only whitelisted sort fields are accepted (newest first when unset); filter values are always passed as
+// query arguments.
+func (r *subscriptionRepo) List(
+	ctx context.Context,
+	filter model.SubscriptionFilter,
+	page model.Page,
+) (*model.SubscriptionPage, error) {
+
+	ctx = withQueryName(ctx, "subscriptionRepo.List")
+
+	log.Printf("INFO: listing subscriptions")
+
+	if filter.Sort.Field == "" {
+		filter.Sort = model.DefaultSort
+	}
+
+	sort, ok := sortColumns[filter.Sort.Field]
+	if !ok {
+		return nil, model.ErrInvalidSort
+	}
+
+	var args queryArgs
+	where := filterConditions(filter, &args)
+
+	var result model.SubscriptionPage
+
+	err := conn(ctx, r.pool).QueryRow(ctx, `SELECT COUNT(*) FROM subscriptions WHERE `+where, args...).Scan(&result.Total)
+	if err != nil {
+		log.Printf("ERROR: count subscriptions failed: %v", err)
+		return nil, err
+	}
+
+	direction, compare := "ASC", ">"
+	if filter.Sort.Desc {
+		direction, compare = "DESC", "<"
+	}
+
+	if page.After != nil {
+		where += fmt.Sprintf(
+			"\n\t\t  AND (%s, id) %s (%s::%s, %s::uuid)",
+			sort.expr, compare, args.add(page.After.Value), sort.cast, args.add(page.After.ID),
+		)
+	}
+
+	// One extra row tells whether another page follows.
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM subscriptions
+		WHERE %s
+		ORDER BY %s %s, id %s
+		LIMIT %s OFFSET %s
+	`, subscriptionColumns, where, sort.expr, direction, direction, args.add(page.Limit+1), args.add(page.Offset))
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, args...)
+	if err != nil {
+		log.Printf("ERROR: list subscriptions failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		result.Items = append(result.Items, sub)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(result.Items) > page.Limit {
+		result.Items = result.Items[:page.Limit]
+		last := result.Items[page.Limit-1]
+		result.NextCursor = &model.Cursor{Sort: filter.Sort.String(), Value: sort.value(last), ID: last.ID}
+	}
+
+	return &result, nil
+}
+
+// monthlyCharges expands the window [$3, $4] into months with generate_series, joins every subscription
+// active in a month and computes the amount (in minor units of the subscription currency) charged for it, using
+// the price in force in that month according to the price history (the earliest price for months before it):
+//   - monthly subscriptions are charged their price every month;
+//   - quarterly and yearly subscriptions are charged in their anniversary months (every 3rd/12th month
+//     counted from start_date), or amortised evenly across months when $5 (amortize) is true;
+//   - weekly subscriptions are charged once per weekly charge date (start_date + 7*k) that falls
+//     into the month, or 52/12 of the price per month when amortised.
+//
+// The category is the one of the linked catalog entry, empty for subscriptions without one.
+// Soft-deleted subscriptions are excluded; $1, $2, $6 and $7 are the optional user_id, service_name, service_id
+// and catalog category filters.
+const monthlyCharges = `
+	SELECT m.month, s.user_id, s.service_name, COALESCE(c.category, '') AS category, s.currency,
+		CASE s.billing_period
+			WHEN 'quarterly' THEN
+				CASE WHEN $5::boolean THEN p.price / 3.0
+				     WHEN p.months_since % 3 = 0 THEN p.price
+				     ELSE 0 END
+			WHEN 'yearly' THEN
+				CASE WHEN $5::boolean THEN p.price / 12.0
+				     WHEN p.months_since % 12 = 0 THEN p.price
+				     ELSE 0 END
+			WHEN 'weekly' THEN
+				CASE WHEN $5::boolean THEN p.price * 52 / 12.0
+				     ELSE p.price * (
+						(((m.month + interval '1 month')::date - s.start_date) + 6) / 7
+						- (GREATEST(m.month::date - s.start_date, 0) + 6) / 7
+				     ) END
+			ELSE p.price
+		END AS charge
+	FROM generate_series(
+		date_trunc('month', $3::date),
+		date_trunc('month', $4::date),
+		interval '1 month'
+	) AS m(month)
+	JOIN subscriptions s
+	  ON date_trunc('month', s.start_date) <= m.month
+	 AND (s.end_date IS NULL OR date_trunc('month', s.end_date) >= m.month)
+	LEFT JOIN services c ON c.id = s.service_id
+	CROSS JOIN LATERAL (
+		SELECT ((EXTRACT(YEAR FROM m.month) - EXTRACT(YEAR FROM s.start_date)) * 12
+			+ EXTRACT(MONTH FROM m.month) - EXTRACT(MONTH FROM s.start_date))::int AS months_since,
+			COALESCE(
+				(SELECT sp.price FROM subscription_prices sp
+				  WHERE sp.subscription_id = s.id AND sp.effective_from <= m.month
+				  ORDER BY sp.effective_from DESC LIMIT 1),
+				(SELECT sp.price FROM subscription_prices sp
+				  WHERE sp.subscription_id = s.id
+				  ORDER BY sp.effective_from LIMIT 1),
+				s.price
+			) AS price
+	) AS p
+	WHERE s.deleted_at IS NULL
+	  AND ($1::uuid IS NULL OR s.user_id = $1)
+	  AND ($2::text IS NULL OR s.service_name = $2)
+	  AND ($6::uuid IS NULL OR s.service_id = $6)
+	  AND ($7::text IS NULL OR s.service_id IN (SELECT id FROM services WHERE category = $7))
+`
+
+// AggregateCost calculates the cost of subscriptions within the query window, broken down by the requested
+// grouping keys (month, service, user, category). Every subscription is charged for each month it is active inside the
+// window according to its billing period; with query.Amortize, quarterly, yearly and weekly prices are spread
+// evenly over the months instead.
+//
+// Amounts in different currencies are never summed together: every returned bucket is additionally keyed by
+// month and currency (Period and Currency are always set) so that the caller can convert them with the rate
+// effective for that month. Buckets are rounded to the nearest minor unit and ordered by the grouping keys.
+func (r *subscriptionRepo) AggregateCost(ctx context.Context, q model.CostQuery) ([]model.CostBucket, error) {
+	ctx = withQueryName(ctx, "subscriptionRepo.AggregateCost")
+
+	log.Printf("INFO: aggregating subscriptions cost grouped by %v", q.GroupBy)
+
+	columns := make([]string, 0, len(q.GroupBy)+2)
+	for _, key := range q.GroupBy {
+		column, ok := groupColumns[key]
+		if !ok {
+			return nil, fmt.Errorf("unsupported group_by key %q", key)
+		}
+		if key != model.GroupByMonth {
+			columns = append(columns, column)
+		}
+	}
+	columns = append(columns, groupColumns[model.GroupByMonth], "currency")
+
+	selectList := strings.Join(columns, ", ")
+	query := fmt.Sprintf(`
+		SELECT %[1]s, ROUND(SUM(charge))::bigint
+		FROM (%[2]s) AS charges
+		GROUP BY %[1]s
+		ORDER BY %[1]s
+	`, selectList, monthlyCharges)
+
+	rows, err := conn(ctx, r.pool).Query(
 		ctx,
 		query,
-		userID,
-		serviceName,
-		from,
-		to,
-	).Scan(&total)
-
+		q.UserID,
+		q.ServiceName,
+		q.From,
+		q.To,
+		q.Amortize,
+		q.ServiceID,
+		q.Category,
+	)
 	if err != nil {
 		log.Printf("ERROR: aggregate cost failed: %v", err)
-		return 0, err
+		return nil, err
 	}
+	defer rows.Close()
+
+	var result []model.CostBucket
+
+	for rows.Next() {
+		var (
+			bucket      model.CostBucket
+			period      time.Time
+			service     string
+			user        uuid.UUID
+			category    string
+			destination = make([]any, 0, len(columns)+1)
+		)
+
+		for _, key := range q.GroupBy {
+			switch key {
+			case model.GroupByService:
+				destination = append(destination, &service)
+			case model.GroupByUser:
+				destination = append(destination, &user)
+			case model.GroupByCategory:
+				destination = append(destination, &category)
+			}
+		}
+		destination = append(destination, &period, &bucket.Currency, &bucket.Total)
 
-	log.Printf("INFO: aggregated cost = %d", total)
-	return total, nil
+		if err := rows.Scan(destination...); err != nil {
+			return nil, err
+		}
+
+		bucket.Period = &period
+		for _, key := range q.GroupBy {
+			switch key {
+			case model.GroupByService:
+				bucket.ServiceName = &service
+			case model.GroupByUser:
+				bucket.UserID = &user
+			case model.GroupByCategory:
+				bucket.Category = &category
+			}
+		}
+
+		result = append(result, bucket)
+	}
+
+	if err := rows.Err(); err != nil {
+		log.Printf("ERROR: aggregate cost failed: %v", err)
+		return nil, err
+	}
+
+	return result, nil
 }
@@ -2,6 +2,8 @@ package repository_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"log"
 	"os"
 	"testing"
@@ -13,6 +15,8 @@ import (
 	"subscription-service/internal/repository"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/joho/godotenv"
 
 	"github.com/stretchr/testify/assert"
@@ -77,9 +81,9 @@ func getTestConfig() *config.Config {
 	return cfg
 }
 
-// setupTestDB initializes the test environment by loading configuration,
-// establishing a database connection, and returning a cleanup function to truncate tables.
-func setupTestDB(t *testing.T) (repository.SubscriptionRepository, func()) {
+// connectTestDB loads the test configuration, establishes a database connection
+// and returns a cleanup function that truncates all tables and closes the pool.
+func connectTestDB(t *testing.T) (*db.Database, func()) {
 
 	cfg := getTestConfig()
 
@@ -87,18 +91,23 @@ func setupTestDB(t *testing.T) (repository.SubscriptionRepository, func()) {
 	database, err := db.Connect(ctx, cfg)
 	require.NoError(t, err, "failed to connect to db")
 
-	repo := repository.NewSubscriptionRepository(database.Pool)
-
 	// Cleans up (called via defer in the test)
 	cleanup := func() {
-		_, err := database.Pool.Exec(ctx, "TRUNCATE subscriptions RESTART IDENTITY CASCADE")
+		_, err := database.Pool.Exec(ctx, "TRUNCATE subscriptions, currency_rates, subscription_events, api_keys, services, webhooks, event_outbox RESTART IDENTITY CASCADE")
 		if err != nil {
 			log.Printf("failed to truncate table: %v", err)
 		}
 		database.Pool.Close()
 	}
 
-	return repo, cleanup
+	return database, cleanup
+}
+
+// setupTestDB initializes the test environment by loading configuration,
+// establishing a database connection, and returning a cleanup function to truncate tables.
+func setupTestDB(t *testing.T) (repository.SubscriptionRepository, func()) {
+	database, cleanup := connectTestDB(t)
+	return repository.NewSubscriptionRepository(database.Pool), cleanup
 }
 
 // TestSubscriptionCRUD verifies the full lifecycle of a subscription (Create, Read, Update, Delete)
@@ -145,19 +154,43 @@ func TestSubscriptionCRUD(t *testing.T) {
 		newSub.Price = 1200
 		newSub.ServiceName = "Netflix Premium"
 
-		err := repo.Update(ctx, newSub)
+		err := repo.Update(ctx, newSub, nil)
 		assert.NoError(t, err)
+		assert.Equal(t, 2, newSub.Version)
 
 		// Check through Get what has been updated
 		fetched, err := repo.GetByID(ctx, newSub.ID)
 		assert.NoError(t, err)
 		assert.Equal(t, 1200, fetched.Price)
 		assert.Equal(t, "Netflix Premium", fetched.ServiceName)
+		assert.Equal(t, 2, fetched.Version)
 	})
 
-	// 4. DELETE
+	// 4. VERSIONED UPDATE
+	t.Run("Update With Stale Version", func(t *testing.T) {
+		stale := 1
+		err := repo.Update(ctx, newSub, &stale)
+		assert.ErrorIs(t, err, repository.ErrVersionConflict)
+
+		current := 2
+		err = repo.Update(ctx, newSub, &current)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, newSub.Version)
+	})
+
+	t.Run("Update Missing With Version", func(t *testing.T) {
+		version := 1
+		err := repo.Update(ctx, &model.Subscription{ID: uuid.New(), ServiceName: "Missing", StartDate: startDate}, &version)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	// 5. DELETE
 	t.Run("Delete", func(t *testing.T) {
-		err := repo.Delete(ctx, newSub.ID)
+		stale := 1
+		err := repo.Delete(ctx, newSub.ID, &stale)
+		assert.ErrorIs(t, err, repository.ErrVersionConflict)
+
+		err = repo.Delete(ctx, newSub.ID, nil)
 		assert.NoError(t, err)
 
 		// Should get a NotFound error
@@ -166,6 +199,294 @@ func TestSubscriptionCRUD(t *testing.T) {
 	})
 }
 
+// TestSoftDeleteLifecycle verifies that deleted subscriptions move to the trash,
+// drop out of listings and aggregation, can be restored and are finally purged.
+func TestSoftDeleteLifecycle(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	userID := uuid.New()
+	sub := &model.Subscription{UserID: userID, ServiceName: "Netflix", Price: 500, StartDate: date(2025, 1, 1)}
+	require.NoError(t, repo.Create(ctx, sub))
+	require.NoError(t, repo.Delete(ctx, sub.ID, nil))
+
+	t.Run("Hidden From Live Queries", func(t *testing.T) {
+		list, err := repo.List(ctx, model.SubscriptionFilter{UserIDs: []uuid.UUID{userID}}, model.Page{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, list.Items)
+
+		total, err := aggregateTotal(ctx, repo, model.CostQuery{UserID: &userID, From: date(2025, 1, 1), To: date(2025, 1, 1)})
+		require.NoError(t, err)
+		assert.Equal(t, 0, total)
+
+		assert.ErrorIs(t, repo.Delete(ctx, sub.ID, nil), repository.ErrNotFound)
+	})
+
+	t.Run("Listed In Trash", func(t *testing.T) {
+		trash, err := repo.List(ctx, model.SubscriptionFilter{UserIDs: []uuid.UUID{userID}, Deleted: true}, model.Page{Limit: 10})
+		require.NoError(t, err)
+		require.Len(t, trash.Items, 1)
+		assert.NotNil(t, trash.Items[0].DeletedAt)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		restored, err := repo.Restore(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+		assert.Equal(t, 3, restored.Version)
+
+		_, err = repo.Restore(ctx, sub.ID)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		require.NoError(t, repo.Delete(ctx, sub.ID, nil))
+
+		purged, err := repo.Purge(ctx, time.Now().Add(-time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(0), purged, "recently deleted rows are kept")
+
+		purged, err = repo.Purge(ctx, time.Now().Add(time.Hour))
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), purged)
+
+		_, err = repo.Restore(ctx, sub.ID)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+// TestAuditEvents verifies that every change of a subscription is recorded in the audit log
+// together with the actor, request ID and before/after snapshots.
+func TestAuditEvents(t *testing.T) {
+	database, cleanup := connectTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewSubscriptionRepository(database.Pool)
+	audit := repository.NewAuditRepository(database.Pool)
+	ctx := model.WithAuditInfo(context.Background(), model.AuditInfo{Actor: "support", RequestID: "req-42"})
+
+	sub := &model.Subscription{UserID: uuid.New(), ServiceName: "Netflix", Price: 500, StartDate: date(2025, 1, 1)}
+	require.NoError(t, repo.Create(ctx, sub))
+
+	sub.Price = 700
+	require.NoError(t, repo.Update(ctx, sub, nil))
+
+	stale := 1
+	require.ErrorIs(t, repo.Update(ctx, sub, &stale), repository.ErrVersionConflict)
+
+	require.NoError(t, repo.Delete(ctx, sub.ID, nil))
+
+	events, err := audit.List(ctx, model.AuditQuery{SubscriptionID: &sub.ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 3, "failed writes are not recorded")
+
+	assert.Equal(t, model.EventDeleted, events[0].Action)
+	assert.Equal(t, model.EventUpdated, events[1].Action)
+	assert.Equal(t, model.EventCreated, events[2].Action)
+
+	updated := events[1]
+	assert.Equal(t, "support", updated.Actor)
+	assert.Equal(t, "req-42", updated.RequestID)
+	assert.Equal(t, sub.UserID, updated.UserID)
+	assert.JSONEq(t, "500", string(jsonField(t, updated.Before, "price")))
+	assert.JSONEq(t, "700", string(jsonField(t, updated.After, "price")))
+	assert.Nil(t, events[2].Before)
+
+	action := model.EventUpdated
+	actor := "support"
+	filtered, err := audit.List(ctx, model.AuditQuery{Actor: &actor, Action: &action, Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, filtered, 1)
+}
+
+// jsonField extracts a top-level field from a JSON object.
+func jsonField(t *testing.T, raw []byte, field string) json.RawMessage {
+	var obj map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(raw, &obj))
+	return obj[field]
+}
+
+// TestPriceHistory verifies that price changes are effective-dated: aggregation charges every month the price
+// that was in force then, and updating the price does not rewrite months that were already charged.
+func TestPriceHistory(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	userID := uuid.New()
+	sub := &model.Subscription{UserID: userID, ServiceName: "Netflix", Price: 300, StartDate: date(2025, 1, 1)}
+	require.NoError(t, repo.Create(ctx, sub))
+
+	require.NoError(t, repo.SchedulePrice(ctx, &model.PriceChange{
+		SubscriptionID: sub.ID,
+		Price:          500,
+		EffectiveFrom:  date(2025, 4, 1),
+	}))
+
+	t.Run("Aggregate Uses Historical Prices", func(t *testing.T) {
+		total, err := aggregateTotal(ctx, repo, model.CostQuery{UserID: &userID, From: date(2025, 1, 1), To: date(2025, 6, 1)})
+		require.NoError(t, err)
+		assert.Equal(t, 3*300+3*500, total)
+	})
+
+	t.Run("Update Keeps Past Prices", func(t *testing.T) {
+		current, err := repo.GetByID(ctx, sub.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 500, current.Price, "the latest effective price is current")
+
+		current.Price = 700
+		require.NoError(t, repo.Update(ctx, current, nil))
+		assert.Equal(t, 700, current.Price)
+
+		total, err := aggregateTotal(ctx, repo, model.CostQuery{UserID: &userID, From: date(2025, 1, 1), To: date(2025, 6, 1)})
+		require.NoError(t, err)
+		assert.Equal(t, 3*300+3*500, total)
+
+		prices, err := repo.ListPrices(ctx, sub.ID)
+		require.NoError(t, err)
+		require.Len(t, prices, 3)
+		assert.Equal(t, 300, prices[0].Price)
+		assert.Equal(t, 700, prices[2].Price)
+	})
+
+	t.Run("Unknown Subscription", func(t *testing.T) {
+		err := repo.SchedulePrice(ctx, &model.PriceChange{SubscriptionID: uuid.New(), Price: 1, EffectiveFrom: date(2030, 1, 1)})
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+}
+
+// TestListCursorPagination verifies that keyset pages cover every subscription exactly once, newest first,
+// and that the total count reflects all subscriptions matching the filters.
+func TestListCursorPagination(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	userID := uuid.New()
+	allow := true
+	for i := 0; i < 5; i++ {
+		sub := &model.Subscription{UserID: userID, ServiceName: "Netflix", Price: 100, StartDate: date(2025, 1, 1), AllowOverlap: &allow}
+		require.NoError(t, repo.Create(ctx, sub))
+	}
+
+	var (
+		seen  []uuid.UUID
+		after *model.Cursor
+	)
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 3, "5 subscriptions fit into 3 pages of 2")
+
+		page, err := repo.List(ctx, model.SubscriptionFilter{UserIDs: []uuid.UUID{userID}}, model.Page{Limit: 2, After: after})
+		require.NoError(t, err)
+		assert.Equal(t, 5, page.Total)
+
+		for _, sub := range page.Items {
+			seen = append(seen, sub.ID)
+		}
+
+		if page.NextCursor == nil {
+			break
+		}
+		after = page.NextCursor
+	}
+
+	all, err := repo.List(ctx, model.SubscriptionFilter{UserIDs: []uuid.UUID{userID}}, model.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, all.Items, 5)
+	assert.Nil(t, all.NextCursor)
+
+	expected := make([]uuid.UUID, 0, len(all.Items))
+	for _, sub := range all.Items {
+		expected = append(expected, sub.ID)
+	}
+	assert.Equal(t, expected, seen)
+}
+
+// TestListFilterAndSort verifies the individual list filters, sorting by a whitelisted field
+// and keyset pagination under a non-default sort.
+func TestListFilterAndSort(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	user1, user2, user3 := uuid.New(), uuid.New(), uuid.New()
+	end := date(2025, 6, 1)
+
+	subs := []*model.Subscription{
+		{UserID: user1, ServiceName: "Yandex Plus", Price: 300, StartDate: date(2025, 1, 1)},
+		{UserID: user1, ServiceName: "YouTube", Price: 500, StartDate: date(2025, 3, 1), EndDate: &end},
+		{UserID: user2, ServiceName: "yandex_music", Price: 200, StartDate: date(2024, 11, 1)},
+		{UserID: user3, ServiceName: "Netflix", Price: 900, StartDate: date(2025, 8, 1)},
+	}
+	for _, sub := range subs {
+		require.NoError(t, repo.Create(ctx, sub))
+	}
+
+	names := func(filter model.SubscriptionFilter) []string {
+		page, err := repo.List(ctx, filter, model.Page{Limit: 10})
+		require.NoError(t, err)
+		result := make([]string, 0, len(page.Items))
+		for _, sub := range page.Items {
+			result = append(result, sub.ServiceName)
+		}
+		return result
+	}
+
+	// Ordered by price so that results do not depend on the database collation.
+	byPrice := model.Sort{Field: model.SortPrice}
+	minPrice, maxPrice := 250, 600
+	active := date(2025, 7, 1)
+	startFrom, startTo := date(2025, 1, 1), date(2025, 3, 1)
+	hasEnd := true
+	prefix := "YA"
+	underscore := "yandex_"
+
+	tests := []struct {
+		name     string
+		filter   model.SubscriptionFilter
+		expected []string
+	}{
+		{"Several Users", model.SubscriptionFilter{UserIDs: []uuid.UUID{user1, user2}, Sort: byPrice},
+			[]string{"yandex_music", "Yandex Plus", "YouTube"}},
+		{"Prefix Ignores Case", model.SubscriptionFilter{ServiceNamePrefix: &prefix, Sort: byPrice},
+			[]string{"yandex_music", "Yandex Plus"}},
+		{"Prefix Escapes Wildcards", model.SubscriptionFilter{ServiceNamePrefix: &underscore, Sort: byPrice},
+			[]string{"yandex_music"}},
+		{"Price Range", model.SubscriptionFilter{MinPrice: &minPrice, MaxPrice: &maxPrice, Sort: byPrice},
+			[]string{"Yandex Plus", "YouTube"}},
+		{"Active At", model.SubscriptionFilter{ActiveAt: &active, Sort: byPrice},
+			[]string{"yandex_music", "Yandex Plus"}},
+		{"Start Range", model.SubscriptionFilter{StartFrom: &startFrom, StartTo: &startTo, Sort: byPrice},
+			[]string{"Yandex Plus", "YouTube"}},
+		{"Has End Date", model.SubscriptionFilter{HasEndDate: &hasEnd}, []string{"YouTube"}},
+		{"Sort By Price Desc", model.SubscriptionFilter{Sort: model.Sort{Field: model.SortPrice, Desc: true}},
+			[]string{"Netflix", "YouTube", "Yandex Plus", "yandex_music"}},
+		{"Sort By Start Date", model.SubscriptionFilter{Sort: model.Sort{Field: model.SortStartDate}},
+			[]string{"yandex_music", "Yandex Plus", "YouTube", "Netflix"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, names(tt.filter))
+		})
+	}
+
+	t.Run("Cursor Under Price Sort", func(t *testing.T) {
+		filter := model.SubscriptionFilter{Sort: model.Sort{Field: model.SortPrice}}
+
+		first, err := repo.List(ctx, filter, model.Page{Limit: 3})
+		require.NoError(t, err)
+		require.NotNil(t, first.NextCursor)
+
+		second, err := repo.List(ctx, filter, model.Page{Limit: 3, After: first.NextCursor})
+		require.NoError(t, err)
+		require.Len(t, second.Items, 1)
+		assert.Equal(t, "Netflix", second.Items[0].ServiceName)
+		assert.Nil(t, second.NextCursor)
+	})
+}
+
 // TestListAndAggregation evaluates the repository's ability to filter records by various criteria
 // and correctly sum subscription costs over specific time periods.
 func TestListAndAggregation(t *testing.T) {
@@ -191,27 +512,27 @@ func TestListAndAggregation(t *testing.T) {
 	}
 
 	t.Run("List Filter by UserID", func(t *testing.T) {
-		list, err := repo.List(ctx, &user1, nil, 10, 0)
+		list, err := repo.List(ctx, model.SubscriptionFilter{UserIDs: []uuid.UUID{user1}}, model.Page{Limit: 10})
 		assert.NoError(t, err)
-		assert.Len(t, list, 2, "У юзера 1 должно быть 2 подписки")
+		assert.Len(t, list.Items, 2, "У юзера 1 должно быть 2 подписки")
 	})
 
 	t.Run("List Filter by ServiceName", func(t *testing.T) {
 		srvName := "Yandex"
-		list, err := repo.List(ctx, nil, &srvName, 10, 0)
+		list, err := repo.List(ctx, model.SubscriptionFilter{ServiceName: &srvName}, model.Page{Limit: 10})
 		assert.NoError(t, err)
-		assert.Len(t, list, 2, "Всего 2 подписки на Яндекс")
+		assert.Len(t, list.Items, 2, "Всего 2 подписки на Яндекс")
 	})
 
 	t.Run("Aggregate Cost", func(t *testing.T) {
 		// Calculate the amount for User1 for the period from January to March
-		// Both subscriptions should be included (300 + 200 = 500)
+		// Yandex is active 3 months, Google 2 months (300*3 + 200*2 = 1300)
 		from := date(2025, 1, 1)
 		to := date(2025, 3, 1)
 
-		cost, err := repo.AggregateCost(ctx, &user1, nil, from, to)
+		cost, err := aggregateTotal(ctx, repo, model.CostQuery{UserID: &user1, From: from, To: to})
 		assert.NoError(t, err)
-		assert.Equal(t, 500, cost)
+		assert.Equal(t, 1300, cost)
 	})
 
 	t.Run("Aggregate Cost Partial", func(t *testing.T) {
@@ -220,13 +541,1012 @@ func TestListAndAggregation(t *testing.T) {
 		from := date(2025, 1, 1)
 		to := date(2025, 1, 31)
 
-		cost, err := repo.AggregateCost(ctx, &user1, nil, from, to)
+		cost, err := aggregateTotal(ctx, repo, model.CostQuery{UserID: &user1, From: from, To: to})
 		assert.NoError(t, err)
 		assert.Equal(t, 300, cost)
 	})
 }
 
+// TestAggregateCostMonths verifies that each subscription is charged its monthly price
+// for every month it is active inside the requested window.
+func TestAggregateCostMonths(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	tests := []struct {
+		name  string
+		start time.Time
+		end   *time.Time
+		from  time.Time
+		to    time.Time
+		want  int
+	}{
+		{
+			name:  "Open-ended subscription covers the whole year",
+			start: date(2025, 1, 1),
+			from:  date(2025, 1, 1),
+			to:    date(2025, 12, 1),
+			want:  400 * 12,
+		},
+		{
+			name:  "Open-ended subscription started before the window",
+			start: date(2024, 6, 1),
+			from:  date(2025, 1, 1),
+			to:    date(2025, 3, 1),
+			want:  400 * 3,
+		},
+		{
+			name:  "Subscription starts inside the window",
+			start: date(2025, 10, 1),
+			from:  date(2025, 1, 1),
+			to:    date(2025, 12, 1),
+			want:  400 * 3,
+		},
+		{
+			name:  "Subscription ends inside the window",
+			start: date(2024, 1, 1),
+			end:   ptr(date(2025, 2, 1)),
+			from:  date(2025, 1, 1),
+			to:    date(2025, 12, 1),
+			want:  400 * 2,
+		},
+		{
+			name:  "Subscription fully inside the window",
+			start: date(2025, 3, 1),
+			end:   ptr(date(2025, 5, 1)),
+			from:  date(2025, 1, 1),
+			to:    date(2025, 12, 1),
+			want:  400 * 3,
+		},
+		{
+			name:  "Single-month window",
+			start: date(2025, 1, 1),
+			from:  date(2025, 6, 1),
+			to:    date(2025, 6, 1),
+			want:  400,
+		},
+		{
+			name:  "Single-month subscription",
+			start: date(2025, 6, 1),
+			end:   ptr(date(2025, 6, 1)),
+			from:  date(2025, 1, 1),
+			to:    date(2025, 12, 1),
+			want:  400,
+		},
+		{
+			name:  "Subscription ended before the window",
+			start: date(2024, 1, 1),
+			end:   ptr(date(2024, 12, 1)),
+			from:  date(2025, 1, 1),
+			to:    date(2025, 12, 1),
+			want:  0,
+		},
+		{
+			name:  "Subscription starts after the window",
+			start: date(2026, 1, 1),
+			from:  date(2025, 1, 1),
+			to:    date(2025, 12, 1),
+			want:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			err := repo.Create(ctx, &model.Subscription{
+				UserID:      userID,
+				ServiceName: "Netflix",
+				Price:       400,
+				StartDate:   tt.start,
+				EndDate:     tt.end,
+			})
+			require.NoError(t, err)
+
+			cost, err := aggregateTotal(ctx, repo, model.CostQuery{UserID: &userID, From: tt.from, To: tt.to})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, cost)
+		})
+	}
+}
+
+// TestAggregateCostBillingPeriods verifies that quarterly, yearly and weekly subscriptions are
+// charged in their billing months, or spread evenly over the window when amortised.
+func TestAggregateCostBillingPeriods(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		period   model.BillingPeriod
+		price    int
+		start    time.Time
+		from     time.Time
+		to       time.Time
+		amortize bool
+		want     int
+	}{
+		{
+			name:   "Yearly charged in anniversary month",
+			period: model.BillingYearly,
+			price:  1200,
+			start:  date(2024, 3, 1),
+			from:   date(2025, 1, 1),
+			to:     date(2025, 12, 1),
+			want:   1200,
+		},
+		{
+			name:   "Yearly window without anniversary month",
+			period: model.BillingYearly,
+			price:  1200,
+			start:  date(2024, 3, 1),
+			from:   date(2025, 4, 1),
+			to:     date(2025, 12, 1),
+			want:   0,
+		},
+		{
+			name:     "Yearly amortised",
+			period:   model.BillingYearly,
+			price:    1200,
+			start:    date(2024, 3, 1),
+			from:     date(2025, 4, 1),
+			to:       date(2025, 6, 1),
+			amortize: true,
+			want:     300,
+		},
+		{
+			name:   "Quarterly charged every third month",
+			period: model.BillingQuarterly,
+			price:  300,
+			start:  date(2025, 2, 1),
+			from:   date(2025, 1, 1),
+			to:     date(2025, 12, 1),
+			want:   300 * 4,
+		},
+		{
+			name:     "Quarterly amortised",
+			period:   model.BillingQuarterly,
+			price:    300,
+			start:    date(2025, 2, 1),
+			from:     date(2025, 3, 1),
+			to:       date(2025, 4, 1),
+			amortize: true,
+			want:     200,
+		},
+		{
+			name:   "Weekly charged per charge date in month",
+			period: model.BillingWeekly,
+			price:  100,
+			start:  date(2025, 1, 1),
+			from:   date(2025, 1, 1),
+			to:     date(2025, 1, 1),
+			want:   500,
+		},
+		{
+			name:   "Weekly charged over February",
+			period: model.BillingWeekly,
+			price:  100,
+			start:  date(2025, 1, 1),
+			from:   date(2025, 2, 1),
+			to:     date(2025, 2, 1),
+			want:   400,
+		},
+		{
+			name:     "Weekly amortised",
+			period:   model.BillingWeekly,
+			price:    120,
+			start:    date(2025, 1, 1),
+			from:     date(2025, 1, 1),
+			to:       date(2025, 12, 1),
+			amortize: true,
+			want:     120 * 52,
+		},
+		{
+			name:     "Monthly unaffected by amortize",
+			period:   model.BillingMonthly,
+			price:    400,
+			start:    date(2025, 1, 1),
+			from:     date(2025, 1, 1),
+			to:       date(2025, 3, 1),
+			amortize: true,
+			want:     1200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := uuid.New()
+			err := repo.Create(ctx, &model.Subscription{
+				UserID:        userID,
+				ServiceName:   "iCloud",
+				Price:         tt.price,
+				BillingPeriod: tt.period,
+				StartDate:     tt.start,
+			})
+			require.NoError(t, err)
+
+			cost, err := aggregateTotal(ctx, repo, model.CostQuery{UserID: &userID, From: tt.from, To: tt.to, Amortize: tt.amortize})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, cost)
+		})
+	}
+}
+
+// TestAggregateCostGrouped verifies the per-service and per-user breakdowns produced by generate_series
+// over the requested window; every bucket is additionally keyed by month and currency.
+func TestAggregateCostGrouped(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	user1 := uuid.New()
+	user2 := uuid.New()
+
+	subs := []*model.Subscription{
+		{UserID: user1, ServiceName: "Netflix", Price: 400, StartDate: date(2025, 1, 1)},
+		{UserID: user1, ServiceName: "Spotify", Price: 150, StartDate: date(2025, 2, 1), EndDate: ptr(date(2025, 2, 1))},
+		{UserID: user2, ServiceName: "Netflix", Price: 10, Currency: "USD", StartDate: date(2025, 3, 1)},
+	}
+
+	for _, s := range subs {
+		require.NoError(t, repo.Create(ctx, s))
+	}
+
+	from := date(2025, 1, 1)
+	to := date(2025, 3, 1)
+
+	t.Run("Month and currency only", func(t *testing.T) {
+		buckets, err := repo.AggregateCost(ctx, model.CostQuery{From: from, To: to})
+		require.NoError(t, err)
+		require.Len(t, buckets, 4)
+
+		assert.Equal(t, "01-2025", buckets[0].Period.Format("01-2006"))
+		assert.Equal(t, "RUB", buckets[0].Currency)
+		assert.Equal(t, 400, buckets[0].Total)
+		assert.Equal(t, "02-2025", buckets[1].Period.Format("01-2006"))
+		assert.Equal(t, 550, buckets[1].Total)
+		assert.Equal(t, "03-2025", buckets[2].Period.Format("01-2006"))
+		assert.Equal(t, "RUB", buckets[2].Currency)
+		assert.Equal(t, 400, buckets[2].Total)
+		assert.Equal(t, "USD", buckets[3].Currency)
+		assert.Equal(t, 10, buckets[3].Total)
+		assert.Nil(t, buckets[0].ServiceName)
+	})
+
+	t.Run("Group by service", func(t *testing.T) {
+		buckets, err := repo.AggregateCost(ctx, model.CostQuery{
+			UserID:  &user1,
+			From:    from,
+			To:      to,
+			GroupBy: []string{model.GroupByService},
+		})
+		require.NoError(t, err)
+		require.Len(t, buckets, 4)
+
+		totals := make(map[string]int)
+		for _, b := range buckets {
+			totals[*b.ServiceName] += b.Total
+		}
+		assert.Equal(t, map[string]int{"Netflix": 1200, "Spotify": 150}, totals)
+	})
+
+	t.Run("Group by user", func(t *testing.T) {
+		buckets, err := repo.AggregateCost(ctx, model.CostQuery{
+			UserID:  &user2,
+			From:    from,
+			To:      to,
+			GroupBy: []string{model.GroupByUser, model.GroupByMonth},
+		})
+		require.NoError(t, err)
+		require.Len(t, buckets, 1)
+
+		assert.Equal(t, user2, *buckets[0].UserID)
+		assert.Equal(t, "03-2025", buckets[0].Period.Format("01-2006"))
+		assert.Equal(t, "USD", buckets[0].Currency)
+		assert.Equal(t, 10, buckets[0].Total)
+	})
+}
+
+// TestCurrencyRates verifies storing, replacing, listing and deleting currency rates.
+func TestCurrencyRates(t *testing.T) {
+	database, cleanup := connectTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	repo := repository.NewCurrencyRateRepository(database.Pool)
+
+	rate := &model.CurrencyRate{BaseCurrency: "USD", QuoteCurrency: "RUB", Rate: 90, EffectiveFrom: date(2025, 1, 15)}
+	require.NoError(t, repo.Upsert(ctx, rate))
+	assert.NotEqual(t, uuid.Nil, rate.ID)
+	assert.Equal(t, 1, rate.EffectiveFrom.Day(), "effective_from is truncated to the month")
+
+	// Same pair and month replaces the rate
+	replaced := &model.CurrencyRate{BaseCurrency: "USD", QuoteCurrency: "RUB", Rate: 92.5, EffectiveFrom: date(2025, 1, 1)}
+	require.NoError(t, repo.Upsert(ctx, replaced))
+
+	require.NoError(t, repo.Upsert(ctx, &model.CurrencyRate{BaseCurrency: "EUR", QuoteCurrency: "RUB", Rate: 100, EffectiveFrom: date(2025, 1, 1)}))
+
+	base := "USD"
+	rates, err := repo.List(ctx, &base, nil)
+	require.NoError(t, err)
+	require.Len(t, rates, 1)
+	assert.Equal(t, 92.5, rates[0].Rate)
+
+	require.NoError(t, repo.Delete(ctx, rates[0].ID))
+	assert.ErrorIs(t, repo.Delete(ctx, rates[0].ID), repository.ErrRateNotFound)
+}
+
+// TestAPIKeys checks that keys are found by hash until they are revoked and that their use is recorded.
+func TestAPIKeys(t *testing.T) {
+	database, cleanup := connectTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewAPIKeyRepository(database.Pool)
+	ctx := context.Background()
+
+	key := &model.APIKey{
+		Name:    "billing-batch",
+		Prefix:  "sk_abcde",
+		KeyHash: "hash-1",
+		Scopes:  []model.Scope{model.ScopeSubscriptionsRead, model.ScopeSummaryRead},
+	}
+	require.NoError(t, repo.Create(ctx, key))
+	assert.NotEqual(t, uuid.Nil, key.ID)
+
+	used, err := repo.MarkUsed(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.Equal(t, key.Scopes, used.Scopes)
+	assert.NotNil(t, used.LastUsedAt)
+
+	_, err = repo.MarkUsed(ctx, "unknown")
+	assert.ErrorIs(t, err, repository.ErrAPIKeyNotFound)
+
+	require.NoError(t, repo.Revoke(ctx, key.ID))
+	assert.ErrorIs(t, repo.Revoke(ctx, key.ID), repository.ErrAPIKeyNotFound)
+
+	_, err = repo.MarkUsed(ctx, "hash-1")
+	assert.ErrorIs(t, err, repository.ErrAPIKeyNotFound)
+
+	keys, err := repo.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.NotNil(t, keys[0].RevokedAt)
+}
+
+// aggregateTotal sums the per-month, per-currency buckets returned by AggregateCost.
+func aggregateTotal(ctx context.Context, repo repository.SubscriptionRepository, q model.CostQuery) (int, error) {
+	buckets, err := repo.AggregateCost(ctx, q)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for _, b := range buckets {
+		total += b.Total
+	}
+	return total, nil
+}
+
 // date is a test helper that returns a time.Time object for a given year, month, and day in UTC.
 func date(y, m, d int) time.Time {
 	return time.Date(y, time.Month(m), d, 0, 0, 0, 0, time.UTC)
 }
+
+// ptr is a test helper that returns a pointer to the given time.
+func ptr(t time.Time) *time.Time {
+	return &t
+}
+
+// TestBatchWrites verifies bulk creates, updates and deletes, including per-item errors, atomic rollback
+// and the audit events written for every item.
+func TestBatchWrites(t *testing.T) {
+	database, cleanup := connectTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewSubscriptionRepository(database.Pool)
+	audit := repository.NewAuditRepository(database.Pool)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	subs := []*model.Subscription{
+		{UserID: userID, ServiceName: "Netflix", Price: 500, StartDate: date(2025, 1, 1)},
+		{UserID: userID, ServiceName: "Spotify", Price: 300, StartDate: date(2025, 2, 1), Currency: "USD"},
+	}
+	require.NoError(t, repo.CreateBatch(ctx, subs))
+
+	for _, sub := range subs {
+		assert.NotEqual(t, uuid.Nil, sub.ID)
+		assert.Equal(t, 1, sub.Version)
+		assert.Equal(t, model.BillingMonthly, sub.BillingPeriod)
+	}
+	assert.Equal(t, model.DefaultCurrency, subs[0].Currency)
+	assert.Equal(t, "USD", subs[1].Currency)
+
+	prices, err := repo.ListPrices(ctx, subs[1].ID)
+	require.NoError(t, err)
+	require.Len(t, prices, 1)
+	assert.Equal(t, 300, prices[0].Price)
+
+	t.Run("Atomic Update Rolls Back", func(t *testing.T) {
+		stale := 7
+		changed := *subs[0]
+		changed.Price = 900
+
+		errs, err := repo.UpdateBatch(ctx, []*model.Subscription{&changed, {ID: uuid.New()}}, []*int{nil, &stale}, nil, true)
+		require.NoError(t, err)
+		assert.NoError(t, errs[0])
+		assert.ErrorIs(t, errs[1], repository.ErrNotFound)
+
+		stored, err := repo.GetByID(ctx, subs[0].ID)
+		require.NoError(t, err)
+		assert.Equal(t, 500, stored.Price)
+	})
+
+	t.Run("Partial Update", func(t *testing.T) {
+		stale := 7
+		first, second := *subs[0], *subs[1]
+		first.Price = 900
+
+		errs, err := repo.UpdateBatch(ctx, []*model.Subscription{&first, &second}, []*int{nil, &stale}, nil, false)
+		require.NoError(t, err)
+		assert.NoError(t, errs[0])
+		assert.ErrorIs(t, errs[1], repository.ErrVersionConflict)
+		assert.Equal(t, 2, first.Version)
+		assert.Equal(t, 900, first.Price)
+	})
+
+	t.Run("Delete Checks Allow", func(t *testing.T) {
+		denied := errors.New("denied")
+		allow := func(before *model.Subscription) error {
+			if before.ID == subs[1].ID {
+				return denied
+			}
+			return nil
+		}
+
+		errs, err := repo.DeleteBatch(ctx, []uuid.UUID{subs[0].ID, subs[1].ID}, allow, false)
+		require.NoError(t, err)
+		assert.NoError(t, errs[0])
+		assert.ErrorIs(t, errs[1], denied)
+
+		_, err = repo.GetByID(ctx, subs[0].ID)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+		_, err = repo.GetByID(ctx, subs[1].ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Existing Keys", func(t *testing.T) {
+		live := model.KeyOf(subs[1])
+		deleted := model.KeyOf(subs[0])
+		unknown := model.SubscriptionKey{UserID: userID, ServiceName: "Spotify", StartDate: date(2025, 3, 1)}
+
+		existing, err := repo.ExistingKeys(ctx, []model.SubscriptionKey{live, deleted, unknown})
+		require.NoError(t, err)
+		assert.Equal(t, map[model.SubscriptionKey]bool{live: true}, existing)
+	})
+
+	events, err := audit.List(ctx, model.AuditQuery{SubscriptionID: &subs[0].ID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, model.EventDeleted, events[0].Action)
+	assert.Equal(t, model.EventUpdated, events[1].Action)
+	assert.Equal(t, model.EventCreated, events[2].Action)
+}
+
+// TestExport verifies that the export cursor returns every matching subscription in sort order,
+// across several fetches, and stops at the first error of the callback.
+func TestExport(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	userID := uuid.New()
+	allow := true
+	subs := make([]*model.Subscription, 1200)
+	for i := range subs {
+		subs[i] = &model.Subscription{UserID: userID, ServiceName: "Netflix", Price: i, StartDate: date(2025, 1, 1), AllowOverlap: &allow}
+	}
+	require.NoError(t, repo.CreateBatch(ctx, subs))
+	require.NoError(t, repo.Create(ctx, &model.Subscription{UserID: uuid.New(), ServiceName: "Other", Price: 1, StartDate: date(2025, 1, 1)}))
+
+	filter := model.SubscriptionFilter{UserIDs: []uuid.UUID{userID}, Sort: model.Sort{Field: model.SortPrice, Desc: true}}
+
+	var prices []int
+	err := repo.Export(ctx, filter, func(sub *model.Subscription) error {
+		prices = append(prices, sub.Price)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, prices, len(subs))
+	assert.Equal(t, len(subs)-1, prices[0])
+	assert.Equal(t, 0, prices[len(prices)-1])
+
+	stop := errors.New("stop")
+	calls := 0
+	err = repo.Export(ctx, filter, func(*model.Subscription) error {
+		calls++
+		return stop
+	})
+	assert.ErrorIs(t, err, stop)
+	assert.Equal(t, 1, calls)
+}
+
+// TestOverlaps verifies that the exclusion constraint rejects overlapping live subscriptions to the same service
+// and that FindOverlaps reports the conflicting subscription.
+func TestOverlaps(t *testing.T) {
+	repo, cleanup := setupTestDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	userID := uuid.New()
+	stored := &model.Subscription{UserID: userID, ServiceName: "Netflix", Price: 500, StartDate: date(2025, 1, 1), EndDate: ptr(date(2025, 6, 1))}
+	require.NoError(t, repo.Create(ctx, stored))
+
+	overlapping := &model.Subscription{UserID: userID, ServiceName: " NETFLIX", Price: 700, StartDate: date(2025, 6, 1)}
+	later := &model.Subscription{UserID: userID, ServiceName: "Netflix", Price: 700, StartDate: date(2025, 7, 1)}
+	other := &model.Subscription{UserID: uuid.New(), ServiceName: "Netflix", Price: 700, StartDate: date(2025, 3, 1)}
+
+	conflicts, allowed, err := repo.FindOverlaps(ctx, []*model.Subscription{overlapping, later, other})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{stored.ID, uuid.Nil, uuid.Nil}, conflicts)
+	assert.Equal(t, []bool{false, false, false}, allowed)
+
+	conflicts, _, err = repo.FindOverlaps(ctx, []*model.Subscription{stored})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{uuid.Nil}, conflicts, "a subscription does not overlap itself")
+
+	assert.ErrorIs(t, repo.Create(ctx, overlapping), repository.ErrOverlap)
+
+	allow := true
+	overlapping.AllowOverlap = &allow
+	require.NoError(t, repo.Create(ctx, overlapping))
+
+	// An update without an override keeps the stored one, both in the check and in the write.
+	overlapping.AllowOverlap = nil
+	conflicts, allowed, err = repo.FindOverlaps(ctx, []*model.Subscription{overlapping})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{uuid.Nil}, conflicts)
+	assert.Equal(t, []bool{true}, allowed)
+
+	overlapping.Price = 800
+	require.NoError(t, repo.Update(ctx, overlapping, nil))
+	assert.True(t, overlapping.OverlapAllowed())
+
+	require.NoError(t, repo.Delete(ctx, stored.ID, nil))
+	conflicts, _, err = repo.FindOverlaps(ctx, []*model.Subscription{{UserID: userID, ServiceName: "Netflix", StartDate: date(2025, 2, 1)}})
+	require.NoError(t, err)
+	assert.Equal(t, []uuid.UUID{uuid.Nil}, conflicts, "deleted and allowed subscriptions are ignored")
+}
+
+// TestCatalog verifies catalog entries, name resolution by alias, unique names across entries and
+// filtering subscriptions by catalog entry and category.
+func TestCatalog(t *testing.T) {
+	database, cleanup := connectTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewSubscriptionRepository(database.Pool)
+	catalog := repository.NewCatalogRepository(database.Pool)
+	ctx := context.Background()
+
+	price := 799
+	netflix := &model.Service{Name: "Netflix", Aliases: []string{"нетфликс"}, Category: "video", DefaultPrice: &price, Currency: "RUB"}
+	require.NoError(t, catalog.Create(ctx, netflix))
+	assert.NotEqual(t, uuid.Nil, netflix.ID)
+
+	spotify := &model.Service{Name: "Spotify", Category: "music", Currency: "USD"}
+	require.NoError(t, catalog.Create(ctx, spotify))
+
+	taken := &model.Service{Name: "Кино", Aliases: []string{"NETFLIX"}, Currency: "RUB"}
+	assert.ErrorIs(t, catalog.Create(ctx, taken), repository.ErrServiceNameTaken)
+
+	stored, err := catalog.GetByID(ctx, netflix.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"нетфликс"}, stored.Aliases)
+	assert.Equal(t, &price, stored.DefaultPrice)
+
+	video := "video"
+	list, err := catalog.List(ctx, &video)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, netflix.ID, list[0].ID)
+
+	resolved, err := catalog.Resolve(ctx, []string{" НЕТФЛИКС", "Yandex"})
+	require.NoError(t, err)
+	require.Contains(t, resolved, "нетфликс")
+	assert.Equal(t, netflix.ID, resolved["нетфликс"].ID)
+	assert.NotContains(t, resolved, "yandex")
+
+	userID := uuid.New()
+	subs := []*model.Subscription{
+		{UserID: userID, ServiceName: "Netflix", ServiceID: &netflix.ID, Price: 500, StartDate: date(2025, 1, 1)},
+		{UserID: userID, ServiceName: "Spotify", ServiceID: &spotify.ID, Price: 300, StartDate: date(2025, 1, 1)},
+		{UserID: userID, ServiceName: "Yandex", Price: 100, StartDate: date(2025, 1, 1)},
+	}
+	require.NoError(t, repo.CreateBatch(ctx, subs))
+	assert.Equal(t, &netflix.ID, subs[0].ServiceID)
+
+	page, err := repo.List(ctx, model.SubscriptionFilter{Category: &video}, model.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, subs[0].ID, page.Items[0].ID)
+
+	page, err = repo.List(ctx, model.SubscriptionFilter{ServiceID: &spotify.ID}, model.Page{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, subs[1].ID, page.Items[0].ID)
+
+	cost, err := aggregateTotal(ctx, repo, model.CostQuery{UserID: &userID, Category: &video, From: date(2025, 1, 1), To: date(2025, 2, 1)})
+	require.NoError(t, err)
+	assert.Equal(t, 1000, cost)
+
+	spotify.Name = "Spotify Premium"
+	spotify.Aliases = []string{"spotify"}
+	require.NoError(t, catalog.Update(ctx, spotify))
+	resolved, err = catalog.Resolve(ctx, []string{"spotify premium", "spotify"})
+	require.NoError(t, err)
+	assert.Equal(t, spotify.ID, resolved["spotify"].ID)
+	assert.Equal(t, "Spotify Premium", resolved["spotify premium"].Name)
+
+	assert.ErrorIs(t, catalog.Update(ctx, &model.Service{ID: uuid.New(), Name: "Missing", Currency: "RUB"}), repository.ErrServiceNotFound)
+
+	require.NoError(t, catalog.Delete(ctx, netflix.ID))
+	sub, err := repo.GetByID(ctx, subs[0].ID)
+	require.NoError(t, err)
+	assert.Nil(t, sub.ServiceID, "deleting a catalog entry unlinks its subscriptions")
+	assert.ErrorIs(t, catalog.Delete(ctx, netflix.ID), repository.ErrServiceNotFound)
+}
+
+// TestAggregateCostByCategory verifies that costs are grouped by the category of the linked catalog entry and
+// that subscriptions without one fall into the empty category.
+func TestAggregateCostByCategory(t *testing.T) {
+	database, cleanup := connectTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewSubscriptionRepository(database.Pool)
+	catalog := repository.NewCatalogRepository(database.Pool)
+	ctx := context.Background()
+
+	netflix := &model.Service{Name: "Netflix", Category: "streaming", Currency: "RUB"}
+	require.NoError(t, catalog.Create(ctx, netflix))
+	spotify := &model.Service{Name: "Spotify", Category: "music", Currency: "RUB"}
+	require.NoError(t, catalog.Create(ctx, spotify))
+
+	userID := uuid.New()
+	require.NoError(t, repo.CreateBatch(ctx, []*model.Subscription{
+		{UserID: userID, ServiceName: "Netflix", ServiceID: &netflix.ID, Price: 900, StartDate: date(2025, 1, 1)},
+		{UserID: userID, ServiceName: "Spotify", ServiceID: &spotify.ID, Price: 300, StartDate: date(2025, 2, 1)},
+		{UserID: userID, ServiceName: "Yandex", Price: 100, StartDate: date(2025, 1, 1)},
+	}))
+
+	buckets, err := repo.AggregateCost(ctx, model.CostQuery{
+		UserID:  &userID,
+		From:    date(2025, 1, 1),
+		To:      date(2025, 2, 1),
+		GroupBy: []string{model.GroupByCategory},
+	})
+	require.NoError(t, err)
+
+	totals := make(map[string]int)
+	for _, b := range buckets {
+		require.NotNil(t, b.Category)
+		totals[*b.Category] += b.Total
+	}
+	assert.Equal(t, map[string]int{"": 200, "music": 300, "streaming": 1800}, totals)
+}
+
+// TestPriceHistoriesAndReminders verifies reading several price histories at once and that a reminder
+// can only be claimed once until it is released.
+func TestPriceHistoriesAndReminders(t *testing.T) {
+	database, cleanup := connectTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewSubscriptionRepository(database.Pool)
+	reminders := repository.NewReminderRepository(database.Pool)
+	ctx := context.Background()
+
+	netflix := &model.Subscription{UserID: uuid.New(), ServiceName: "Netflix", Price: 500, StartDate: date(2025, 1, 1)}
+	spotify := &model.Subscription{UserID: uuid.New(), ServiceName: "Spotify", Price: 300, StartDate: date(2025, 3, 1)}
+	require.NoError(t, repo.Create(ctx, netflix))
+	require.NoError(t, repo.Create(ctx, spotify))
+	require.NoError(t, repo.SchedulePrice(ctx, &model.PriceChange{SubscriptionID: netflix.ID, Price: 700, EffectiveFrom: date(2025, 6, 1)}))
+
+	histories, err := repo.PriceHistories(ctx, []uuid.UUID{netflix.ID, spotify.ID, uuid.New()})
+	require.NoError(t, err)
+	require.Len(t, histories, 2)
+	require.Len(t, histories[netflix.ID], 2)
+	assert.Equal(t, 500, histories[netflix.ID][0].Price)
+	assert.Equal(t, 700, histories[netflix.ID][1].Price)
+	assert.Equal(t, 300, histories[spotify.ID][0].Price)
+
+	reminder := &model.Reminder{Kind: model.ReminderCharge, Subscription: netflix, Date: date(2025, 7, 1)}
+
+	claimed, err := reminders.Claim(ctx, reminder)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = reminders.Claim(ctx, reminder)
+	require.NoError(t, err)
+	assert.False(t, claimed, "a reminder is claimed once")
+
+	claimed, err = reminders.Claim(ctx, &model.Reminder{Kind: model.ReminderEnd, Subscription: netflix, Date: date(2025, 7, 1)})
+	require.NoError(t, err)
+	assert.True(t, claimed, "reminders of other kinds are claimed separately")
+
+	require.NoError(t, reminders.Release(ctx, reminder))
+	claimed, err = reminders.Claim(ctx, reminder)
+	require.NoError(t, err)
+	assert.True(t, claimed, "a released reminder is claimed again")
+}
+
+// TestWebhookOutbox verifies that subscription changes are written to the event outbox and fanned out to the
+// subscribed webhooks, and the lifecycle of deliveries: leasing, dead-lettering, redelivery and expiry events.
+func TestWebhookOutbox(t *testing.T) {
+	database, cleanup := connectTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewSubscriptionRepository(database.Pool)
+	hooks := repository.NewWebhookRepository(database.Pool)
+	ctx := context.Background()
+
+	all := &model.Webhook{URL: "https://example.com/all", Secret: "secret-for-all-events", Active: true}
+	created := &model.Webhook{URL: "https://example.com/created", Secret: "secret-for-created", Events: []string{model.EventTypeCreated}, Active: true}
+	require.NoError(t, hooks.Create(ctx, all))
+	require.NoError(t, hooks.Create(ctx, created))
+
+	sub := &model.Subscription{UserID: uuid.New(), ServiceName: "Netflix", Price: 500, StartDate: date(2025, 1, 1)}
+	require.NoError(t, repo.Create(ctx, sub))
+	sub.Price = 600
+	require.NoError(t, repo.Update(ctx, sub, nil))
+
+	var outboxed int
+	require.NoError(t, database.Pool.QueryRow(ctx, "SELECT count(*) FROM event_outbox").Scan(&outboxed))
+	assert.Equal(t, 2, outboxed, "every change is written to the outbox")
+
+	dispatched, err := hooks.FanOut(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), dispatched)
+
+	dispatched, err = hooks.FanOut(ctx, 10)
+	require.NoError(t, err)
+	assert.Zero(t, dispatched, "events are fanned out once")
+
+	deliveries, err := hooks.ClaimDeliveries(ctx, time.Minute, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 3, "the filtered webhook only receives the created event")
+	for _, d := range deliveries {
+		assert.Contains(t, string(d.Event.Data), "Netflix")
+		assert.NotEmpty(t, d.Secret)
+	}
+
+	again, err := hooks.ClaimDeliveries(ctx, time.Minute, 10)
+	require.NoError(t, err)
+	assert.Empty(t, again, "claimed deliveries are leased")
+
+	failed := deliveries[0]
+	status := 500
+	failed.Status, failed.Attempts, failed.LastStatus, failed.LastError = model.DeliveryDead, 1, &status, "boom"
+	completed, err := hooks.CompleteDelivery(ctx, failed, 0)
+	require.NoError(t, err)
+	assert.True(t, completed)
+
+	completed, err = hooks.CompleteDelivery(ctx, failed, 0)
+	require.NoError(t, err)
+	assert.False(t, completed, "a delivery that is no longer leased keeps its outcome")
+
+	dead := model.DeliveryDead
+	list, err := hooks.ListDeliveries(ctx, failed.WebhookID, &dead, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "boom", list[0].LastError)
+
+	redelivered, err := hooks.Redeliver(ctx, failed.WebhookID, failed.ID)
+	require.NoError(t, err)
+	assert.Equal(t, model.DeliveryPending, redelivered.Status)
+	assert.Zero(t, redelivered.Attempts)
+
+	_, err = hooks.Redeliver(ctx, uuid.New(), failed.ID)
+	assert.ErrorIs(t, err, repository.ErrDeliveryNotFound)
+
+	ended := &model.Subscription{UserID: uuid.New(), ServiceName: "Spotify", Price: 300, StartDate: date(2025, 1, 1), EndDate: ptr(date(2025, 2, 1))}
+	require.NoError(t, repo.Create(ctx, ended))
+
+	outbox := repository.NewOutboxRepository(database.Pool)
+	enqueued, err := outbox.EnqueueExpired(ctx, date(2025, 2, 25), date(2025, 3, 1))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), enqueued)
+
+	enqueued, err = outbox.EnqueueExpired(ctx, date(2025, 2, 26), date(2025, 3, 2))
+	require.NoError(t, err)
+	assert.Zero(t, enqueued, "an expiry is enqueued once")
+
+	require.NoError(t, hooks.Delete(ctx, all.ID))
+	_, err = hooks.GetByID(ctx, all.ID)
+	assert.ErrorIs(t, err, repository.ErrWebhookNotFound)
+}
+
+// TestOutboxRelay verifies that outbox events are relayed in order, only after their transaction commits, that
+// relaying stops at the first event that fails to publish, and that claimed events are neither locked while they
+// are published nor relayed by a concurrent relay.
+func TestOutboxRelay(t *testing.T) {
+	database, cleanup := connectTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewSubscriptionRepository(database.Pool)
+	outbox := repository.NewOutboxRepository(database.Pool)
+	ctx := context.Background()
+
+	sub := &model.Subscription{UserID: uuid.New(), ServiceName: "Netflix", Price: 500, StartDate: date(2025, 1, 1)}
+	require.NoError(t, repo.Create(ctx, sub))
+	require.NoError(t, repo.Delete(ctx, sub.ID, nil))
+
+	// A write rejected by the database leaves no event behind.
+	invalid := &model.Subscription{UserID: uuid.New(), ServiceName: "Spotify", Price: -1, StartDate: date(2025, 1, 1)}
+	require.Error(t, repo.Create(ctx, invalid))
+
+	var relayed []*model.Event
+	failAt := model.EventTypeDeleted
+	publish := func(ctx context.Context, e *model.Event) error {
+		if e.Type == failAt {
+			return errors.New("broker unavailable")
+		}
+		relayed = append(relayed, e)
+
+		if len(relayed) == 1 {
+			concurrent, err := outbox.Relay(ctx, 10, time.Minute, func(context.Context, *model.Event) error {
+				return errors.New("claimed events must not be relayed twice")
+			})
+			assert.NoError(t, err)
+			assert.Zero(t, concurrent)
+
+			lockCtx, cancel := context.WithTimeout(ctx, time.Second)
+			defer cancel()
+			_, err = database.Pool.Exec(lockCtx, "UPDATE event_outbox SET payload = payload WHERE id = $1", e.ID)
+			assert.NoError(t, err, "events are not locked while they are published")
+		}
+		return nil
+	}
+
+	published, err := outbox.Relay(ctx, 10, time.Minute, publish)
+	assert.EqualError(t, err, "broker unavailable")
+	assert.Equal(t, 1, published)
+	require.Len(t, relayed, 1)
+	assert.Equal(t, model.EventTypeCreated, relayed[0].Type)
+	assert.Equal(t, sub.ID, relayed[0].SubscriptionID)
+	assert.JSONEq(t, `"Netflix"`, string(jsonField(t, jsonField(t, relayed[0].Data, "subscription"), "service_name")))
+
+	failAt = ""
+	published, err = outbox.Relay(ctx, 10, time.Minute, publish)
+	require.NoError(t, err)
+	assert.Equal(t, 1, published, "the failed event is retried, published events are not")
+	require.Len(t, relayed, 2)
+	assert.Equal(t, model.EventTypeDeleted, relayed[1].Type)
+	assert.Greater(t, relayed[1].ID, relayed[0].ID)
+
+	published, err = outbox.Relay(ctx, 10, time.Minute, publish)
+	require.NoError(t, err)
+	assert.Zero(t, published)
+
+	// A relay whose claim was taken over while it published does not mark the event as published.
+	require.NoError(t, repo.Create(ctx, &model.Subscription{UserID: uuid.New(), ServiceName: "Spotify", Price: 300, StartDate: date(2025, 1, 1)}))
+	published, err = outbox.Relay(ctx, 10, time.Minute, func(ctx context.Context, e *model.Event) error {
+		_, err := database.Pool.Exec(ctx, "UPDATE event_outbox SET claim_id = $2 WHERE id = $1", e.ID, uuid.New())
+		return err
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 1, published)
+
+	var unpublished int
+	require.NoError(t, database.Pool.QueryRow(ctx, "SELECT count(*) FROM event_outbox WHERE published_at IS NULL").Scan(&unpublished))
+	assert.Equal(t, 1, unpublished, "the event is left to the relay that took it over")
+}
+
+func TestTxManager(t *testing.T) {
+	database, cleanup := connectTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewSubscriptionRepository(database.Pool)
+	txm := repository.NewTxManager(database.Pool, pgx.Serializable, 2)
+	ctx := context.Background()
+
+	t.Run("Commit", func(t *testing.T) {
+		sub := &model.Subscription{UserID: uuid.New(), ServiceName: "Netflix", Price: 500, StartDate: date(2025, 1, 1)}
+
+		err := txm.WithinTx(ctx, func(ctx context.Context) error {
+			if err := repo.Create(ctx, sub); err != nil {
+				return err
+			}
+			// Writes of the unit of work are visible within it, including from a nested unit.
+			return txm.WithinTx(ctx, func(ctx context.Context) error {
+				_, err := repo.GetByID(ctx, sub.ID)
+				return err
+			})
+		})
+		require.NoError(t, err)
+
+		_, err = repo.GetByID(ctx, sub.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Rollback", func(t *testing.T) {
+		sub := &model.Subscription{UserID: uuid.New(), ServiceName: "Spotify", Price: 300, StartDate: date(2025, 1, 1)}
+		failure := errors.New("later step failed")
+
+		err := txm.WithinTx(ctx, func(ctx context.Context) error {
+			if err := repo.Create(ctx, sub); err != nil {
+				return err
+			}
+			return failure
+		})
+		assert.ErrorIs(t, err, failure)
+
+		_, err = repo.GetByID(ctx, sub.ID)
+		assert.ErrorIs(t, err, repository.ErrNotFound)
+	})
+
+	t.Run("Export Joins Unit", func(t *testing.T) {
+		userID := uuid.New()
+		sub := &model.Subscription{UserID: userID, ServiceName: "Kinopoisk", Price: 400, StartDate: date(2025, 1, 1)}
+		filter := model.SubscriptionFilter{UserIDs: []uuid.UUID{userID}}
+
+		var exported []uuid.UUID
+		err := txm.WithinTx(ctx, func(ctx context.Context) error {
+			if err := repo.Create(ctx, sub); err != nil {
+				return err
+			}
+			return repo.Export(ctx, filter, func(s *model.Subscription) error {
+				exported = append(exported, s.ID)
+				return nil
+			})
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []uuid.UUID{sub.ID}, exported, "the export sees the writes of its unit of work")
+	})
+
+	t.Run("Retry Serialization Failure", func(t *testing.T) {
+		attempts := 0
+		err := txm.WithinTx(ctx, func(ctx context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+			}
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, 3, attempts)
+
+		attempts = 0
+		err = txm.WithinTx(ctx, func(ctx context.Context) error {
+			attempts++
+			return &pgconn.PgError{Code: "40001", Message: "could not serialize access"}
+		})
+		assert.Error(t, err)
+		assert.Equal(t, 3, attempts, "the first attempt and maxRetries retries")
+	})
+}
+
+func TestActiveStats(t *testing.T) {
+	database, cleanup := connectTestDB(t)
+	defer cleanup()
+
+	repo := repository.NewSubscriptionRepository(database.Pool)
+	stats := repository.NewStatsRepository(database.Pool)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	for _, sub := range []*model.Subscription{
+		{UserID: userID, ServiceName: "Netflix", Price: 500, StartDate: date(2025, 1, 1)},
+		{UserID: userID, ServiceName: "Spotify", Price: 300, StartDate: date(2025, 1, 1), EndDate: ptr(date(2025, 3, 1))},
+		{UserID: uuid.New(), ServiceName: "Yandex", Price: 200, StartDate: date(2025, 5, 1)},
+	} {
+		require.NoError(t, repo.Create(ctx, sub))
+	}
+
+	active, err := stats.Active(ctx, date(2025, 3, 15))
+	require.NoError(t, err)
+	assert.Equal(t, &model.ActiveStats{Subscriptions: 2, Users: 1}, active)
+
+	active, err = stats.Active(ctx, date(2025, 5, 1))
+	require.NoError(t, err)
+	assert.Equal(t, &model.ActiveStats{Subscriptions: 2, Users: 2}, active)
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"subscription-service/internal/model"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// StatsRepository defines the interface for the aggregate figures exported as business metrics.
+type StatsRepository interface {
+	Active(ctx context.Context, on time.Time) (*model.ActiveStats, error)
+}
+
+type statsRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewStatsRepository creates a new instance of the stats repository using a pgx connection pool.
+func NewStatsRepository(pool *pgxpool.Pool) StatsRepository {
+	return &statsRepo{pool: pool}
+}
+
+// Active counts the subscriptions in effect in the month of on and their distinct users. A subscription ending
+// in that month is still in effect, matching the cost aggregation.
+func (r *statsRepo) Active(ctx context.Context, on time.Time) (*model.ActiveStats, error) {
+	ctx = withQueryName(ctx, "statsRepo.Active")
+
+	query := `
+		SELECT count(*), count(DISTINCT user_id)
+		FROM subscriptions
+		WHERE deleted_at IS NULL
+		  AND start_date <= $1::date
+		  AND (end_date IS NULL OR date_trunc('month', end_date) >= date_trunc('month', $1::date))
+	`
+
+	var stats model.ActiveStats
+	if err := conn(ctx, r.pool).QueryRow(ctx, query, on).Scan(&stats.Subscriptions, &stats.Users); err != nil {
+		log.Printf("ERROR: failed to count active subscriptions: %v", err)
+		return nil, err
+	}
+	return &stats, nil
+}
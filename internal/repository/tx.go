@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// TxManager runs units of work in a database transaction. The transaction travels in the context passed to the
+// unit of work, and repository methods called with that context run inside it.
+type TxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// SQLSTATEs of transactions aborted by a concurrent one; retrying them from scratch usually succeeds.
+const (
+	serializationFailure = "40001"
+	deadlockDetected     = "40P01"
+)
+
+// retryBackoff is the pause before the first retry of an aborted transaction, doubled for every further retry.
+const retryBackoff = 10 * time.Millisecond
+
+type txKey struct{}
+
+// dbtx is implemented by both *pgxpool.Pool and pgx.Tx. Begin on a transaction starts a savepoint,
+// so methods that need their own transaction nest inside the caller's one.
+type dbtx interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	CopyFrom(ctx context.Context, table pgx.Identifier, columns []string, rows pgx.CopyFromSource) (int64, error)
+}
+
+// conn returns the transaction of ctx, if any, and the pool otherwise.
+func conn(ctx context.Context, pool *pgxpool.Pool) dbtx {
+	if tx, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return tx
+	}
+	return pool
+}
+
+type txManager struct {
+	pool       *pgxpool.Pool
+	isolation  pgx.TxIsoLevel
+	maxRetries int
+}
+
+// NewTxManager creates a transaction manager starting transactions on pool with the given isolation level
+// (the server default when empty). A unit of work aborted by a serialization failure or a deadlock is retried
+// up to maxRetries times.
+func NewTxManager(pool *pgxpool.Pool, isolation pgx.TxIsoLevel, maxRetries int) TxManager {
+	return &txManager{pool: pool, isolation: isolation, maxRetries: maxRetries}
+}
+
+// WithinTx runs fn in a transaction that is committed if fn returns nil and rolled back otherwise. Called within
+// a unit of work, it joins the enclosing transaction, which alone commits and retries. Because a retry runs fn
+// again from the start, fn must not have side effects outside the database other than on its own results.
+func (m *txManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(pgx.Tx); ok {
+		return fn(ctx)
+	}
+
+	// BEGIN and COMMIT are labelled with the manager; the unit of work runs with the caller's context.
+	txCtx := withQueryName(ctx, "txManager.WithinTx")
+
+	backoff := retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := pgx.BeginTxFunc(txCtx, m.pool, pgx.TxOptions{IsoLevel: m.isolation}, func(tx pgx.Tx) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+		if err == nil || !retryable(err) || attempt >= m.maxRetries {
+			return err
+		}
+
+		log.Printf("WARN: transaction aborted (%v), retry %d of %d in %s", err, attempt+1, m.maxRetries, backoff)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// retryable reports whether err aborted a transaction because of a concurrent one.
+func retryable(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && (pgErr.Code == serializationFailure || pgErr.Code == deadlockDetected)
+}
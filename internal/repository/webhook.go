@@ -0,0 +1,452 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"subscription-service/internal/model"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// WebhookRepository defines the interface for storing webhooks and delivering the events of the event outbox
+// to them.
+type WebhookRepository interface {
+	Create(ctx context.Context, hook *model.Webhook) error
+	GetByID(ctx context.Context, id uuid.UUID) (*model.Webhook, error)
+	List(ctx context.Context) ([]*model.Webhook, error)
+	Update(ctx context.Context, hook *model.Webhook) error
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	ListDeliveries(ctx context.Context, webhookID uuid.UUID, status *string, limit, offset int) ([]*model.WebhookDelivery, error)
+	Redeliver(ctx context.Context, webhookID, deliveryID uuid.UUID) (*model.WebhookDelivery, error)
+
+	FanOut(ctx context.Context, limit int) (int64, error)
+	ClaimDeliveries(ctx context.Context, lease time.Duration, limit int) ([]*model.WebhookDelivery, error)
+	RenewDelivery(ctx context.Context, delivery *model.WebhookDelivery, lease time.Duration) (bool, error)
+	CompleteDelivery(ctx context.Context, delivery *model.WebhookDelivery, retryIn time.Duration) (bool, error)
+}
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+)
+
+// webhookColumns is the column list read by scanWebhook.
+const webhookColumns = `id, url, secret, events, active, created_at, updated_at`
+
+// scanWebhook reads a webhook selected with webhookColumns.
+func scanWebhook(row rowScanner) (*model.Webhook, error) {
+	var hook model.Webhook
+	err := row.Scan(
+		&hook.ID,
+		&hook.URL,
+		&hook.Secret,
+		&hook.Events,
+		&hook.Active,
+		&hook.CreatedAt,
+		&hook.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+// deliveryColumns is the column list read by scanDelivery; queries must join event_outbox as e.
+const deliveryColumns = `d.id, d.webhook_id, d.event_id, e.event_type, d.status, d.attempts, d.next_attempt_at,
+	d.last_status, d.last_error, d.created_at, d.delivered_at`
+
+// scanDelivery reads a delivery selected with deliveryColumns followed by the extra destinations.
+func scanDelivery(row rowScanner, extra ...any) (*model.WebhookDelivery, error) {
+	var d model.WebhookDelivery
+	dest := append([]any{
+		&d.ID,
+		&d.WebhookID,
+		&d.Event.ID,
+		&d.Event.Type,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.LastStatus,
+		&d.LastError,
+		&d.CreatedAt,
+		&d.DeliveredAt,
+	}, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+type webhookRepo struct {
+	pool *pgxpool.Pool
+}
+
+// NewWebhookRepository creates a new instance of the webhook repository using a pgx connection pool.
+func NewWebhookRepository(pool *pgxpool.Pool) WebhookRepository {
+	return &webhookRepo{pool: pool}
+}
+
+// Create stores a new webhook and populates its ID and timestamps.
+func (r *webhookRepo) Create(ctx context.Context, hook *model.Webhook) error {
+	ctx = withQueryName(ctx, "webhookRepo.Create")
+
+	log.Printf("INFO: creating webhook for %s", hook.URL)
+
+	query := `
+		INSERT INTO webhooks (url, secret, events, active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := conn(ctx, r.pool).QueryRow(ctx, query, hook.URL, hook.Secret, webhookEvents(hook), hook.Active).
+		Scan(&hook.ID, &hook.CreatedAt, &hook.UpdatedAt)
+	if err != nil {
+		log.Printf("ERROR: failed to create webhook: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetByID retrieves a webhook by its ID. Returns ErrWebhookNotFound if it does not exist.
+func (r *webhookRepo) GetByID(ctx context.Context, id uuid.UUID) (*model.Webhook, error) {
+	ctx = withQueryName(ctx, "webhookRepo.GetByID")
+
+	log.Printf("INFO: getting webhook %s", id)
+
+	hook, err := scanWebhook(conn(ctx, r.pool).QueryRow(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("WARN: webhook %s not found", id)
+		return nil, ErrWebhookNotFound
+	}
+
+	if err != nil {
+		log.Printf("ERROR: failed to get webhook %s: %v", id, err)
+		return nil, err
+	}
+
+	return hook, nil
+}
+
+// List returns all webhooks, oldest first.
+func (r *webhookRepo) List(ctx context.Context) ([]*model.Webhook, error) {
+	ctx = withQueryName(ctx, "webhookRepo.List")
+
+	log.Printf("INFO: listing webhooks")
+
+	rows, err := conn(ctx, r.pool).Query(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY created_at, id`)
+	if err != nil {
+		log.Printf("ERROR: list webhooks failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.Webhook
+
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, hook)
+	}
+
+	return result, rows.Err()
+}
+
+// Update replaces the URL, event filter and state of a webhook and populates its timestamps and secret.
+// An empty secret keeps the stored one. Returns ErrWebhookNotFound if it does not exist.
+func (r *webhookRepo) Update(ctx context.Context, hook *model.Webhook) error {
+	ctx = withQueryName(ctx, "webhookRepo.Update")
+
+	log.Printf("INFO: updating webhook %s", hook.ID)
+
+	query := `
+		UPDATE webhooks
+		SET url = $1,
+			secret = COALESCE(NULLIF($2, ''), secret),
+			events = $3,
+			active = $4,
+			updated_at = now()
+		WHERE id = $5
+		RETURNING secret, created_at, updated_at
+	`
+
+	err := conn(ctx, r.pool).QueryRow(ctx, query, hook.URL, hook.Secret, webhookEvents(hook), hook.Active, hook.ID).
+		Scan(&hook.Secret, &hook.CreatedAt, &hook.UpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("WARN: webhook %s not found for update", hook.ID)
+		return ErrWebhookNotFound
+	}
+
+	if err != nil {
+		log.Printf("ERROR: failed to update webhook %s: %v", hook.ID, err)
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes a webhook together with its deliveries. Returns ErrWebhookNotFound if no record was deleted.
+func (r *webhookRepo) Delete(ctx context.Context, id uuid.UUID) error {
+	ctx = withQueryName(ctx, "webhookRepo.Delete")
+
+	log.Printf("INFO: deleting webhook %s", id)
+
+	cmd, err := conn(ctx, r.pool).Exec(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		log.Printf("ERROR: failed to delete webhook %s: %v", id, err)
+		return err
+	}
+
+	if cmd.RowsAffected() == 0 {
+		log.Printf("WARN: webhook %s not found for delete", id)
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+// ListDeliveries returns the deliveries of a webhook, optionally with the given status, newest first.
+func (r *webhookRepo) ListDeliveries(
+	ctx context.Context,
+	webhookID uuid.UUID,
+	status *string,
+	limit, offset int,
+) ([]*model.WebhookDelivery, error) {
+
+	ctx = withQueryName(ctx, "webhookRepo.ListDeliveries")
+
+	log.Printf("INFO: listing deliveries of webhook %s", webhookID)
+
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries d
+		JOIN event_outbox e ON e.id = d.event_id
+		WHERE d.webhook_id = $1
+		  AND ($2::text IS NULL OR d.status = $2)
+		ORDER BY d.created_at DESC, d.event_id DESC
+		LIMIT $3 OFFSET $4
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, webhookID, status, limit, offset)
+	if err != nil {
+		log.Printf("ERROR: list webhook deliveries failed: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.WebhookDelivery
+
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+
+	return result, rows.Err()
+}
+
+// Redeliver schedules a delivery of the webhook for an immediate new series of attempts, whatever its status,
+// and returns it. Returns ErrDeliveryNotFound if the webhook has no such delivery.
+func (r *webhookRepo) Redeliver(ctx context.Context, webhookID, deliveryID uuid.UUID) (*model.WebhookDelivery, error) {
+	ctx = withQueryName(ctx, "webhookRepo.Redeliver")
+
+	log.Printf("INFO: redelivering %s of webhook %s", deliveryID, webhookID)
+
+	query := `
+		WITH d AS (
+			UPDATE webhook_deliveries
+			SET status = 'pending', attempts = 0, next_attempt_at = now(), last_error = ''
+			WHERE id = $1 AND webhook_id = $2
+			RETURNING *
+		)
+		SELECT ` + deliveryColumns + `
+		FROM d
+		JOIN event_outbox e ON e.id = d.event_id
+	`
+
+	d, err := scanDelivery(conn(ctx, r.pool).QueryRow(ctx, query, deliveryID, webhookID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		log.Printf("WARN: delivery %s of webhook %s not found", deliveryID, webhookID)
+		return nil, ErrDeliveryNotFound
+	}
+
+	if err != nil {
+		log.Printf("ERROR: failed to redeliver %s: %v", deliveryID, err)
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// FanOut turns up to limit undispatched outbox events, oldest first, into one pending delivery per active webhook
+// subscribed to the event type and marks them dispatched. Events locked by a concurrent worker are skipped.
+// It returns the number of events dispatched.
+func (r *webhookRepo) FanOut(ctx context.Context, limit int) (int64, error) {
+	ctx = withQueryName(ctx, "webhookRepo.FanOut")
+
+	query := `
+		WITH events AS (
+			SELECT id, event_type
+			FROM event_outbox
+			WHERE dispatched_at IS NULL
+			ORDER BY id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		), deliveries AS (
+			INSERT INTO webhook_deliveries (webhook_id, event_id)
+			SELECT w.id, e.id
+			FROM events e
+			JOIN webhooks w ON w.active AND (cardinality(w.events) = 0 OR e.event_type = ANY(w.events))
+			ON CONFLICT DO NOTHING
+		)
+		UPDATE event_outbox o
+		SET dispatched_at = now()
+		FROM events e
+		WHERE o.id = e.id
+	`
+
+	cmd, err := conn(ctx, r.pool).Exec(ctx, query, limit)
+	if err != nil {
+		log.Printf("ERROR: failed to fan out events: %v", err)
+		return 0, err
+	}
+
+	return cmd.RowsAffected(), nil
+}
+
+// ClaimDeliveries returns up to limit pending deliveries that are due, oldest first, together with the event
+// and the URL and secret of their webhook. Claimed deliveries are postponed by lease so that concurrent workers
+// skip them and a delivery whose worker crashes is retried after the lease expires; workers renew the lease with
+// RenewDelivery before every attempt. Deliveries of inactive webhooks are left pending.
+func (r *webhookRepo) ClaimDeliveries(ctx context.Context, lease time.Duration, limit int) ([]*model.WebhookDelivery, error) {
+	ctx = withQueryName(ctx, "webhookRepo.ClaimDeliveries")
+
+	query := `
+		WITH due AS (
+			SELECT d.id
+			FROM webhook_deliveries d
+			JOIN webhooks w ON w.id = d.webhook_id
+			WHERE d.status = 'pending' AND d.next_attempt_at <= now() AND w.active
+			ORDER BY d.next_attempt_at
+			LIMIT $2
+			FOR UPDATE OF d SKIP LOCKED
+		), d AS (
+			UPDATE webhook_deliveries
+			SET next_attempt_at = now() + make_interval(secs => $1)
+			FROM due
+			WHERE webhook_deliveries.id = due.id
+			RETURNING webhook_deliveries.*
+		)
+		SELECT ` + deliveryColumns + `, e.subscription_id, e.payload, e.created_at, w.url, w.secret
+		FROM d
+		JOIN event_outbox e ON e.id = d.event_id
+		JOIN webhooks w ON w.id = d.webhook_id
+		ORDER BY d.created_at, d.event_id
+	`
+
+	rows, err := conn(ctx, r.pool).Query(ctx, query, lease.Seconds(), limit)
+	if err != nil {
+		log.Printf("ERROR: failed to claim webhook deliveries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []*model.WebhookDelivery
+
+	for rows.Next() {
+		var subscriptionID uuid.UUID
+		var payload []byte
+		var createdAt time.Time
+		var url, secret string
+
+		d, err := scanDelivery(rows, &subscriptionID, &payload, &createdAt, &url, &secret)
+		if err != nil {
+			return nil, err
+		}
+		d.Event.SubscriptionID, d.Event.Data, d.Event.CreatedAt = subscriptionID, payload, createdAt
+		d.URL, d.Secret = url, secret
+		result = append(result, d)
+	}
+
+	return result, rows.Err()
+}
+
+// RenewDelivery extends the lease of a claimed delivery by lease from now, just before it is attempted. The
+// lease is only renewed while the delivery is pending and still leased until d.NextAttemptAt, as returned by
+// ClaimDeliveries; otherwise its lease expired and another worker may have claimed it, so it returns false and
+// the delivery must not be attempted.
+func (r *webhookRepo) RenewDelivery(ctx context.Context, d *model.WebhookDelivery, lease time.Duration) (bool, error) {
+	ctx = withQueryName(ctx, "webhookRepo.RenewDelivery")
+
+	query := `
+		UPDATE webhook_deliveries
+		SET next_attempt_at = now() + make_interval(secs => $2)
+		WHERE id = $1 AND status = 'pending' AND next_attempt_at = $3
+		RETURNING next_attempt_at
+	`
+
+	err := conn(ctx, r.pool).QueryRow(ctx, query, d.ID, lease.Seconds(), d.NextAttemptAt).Scan(&d.NextAttemptAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		log.Printf("ERROR: failed to renew lease of delivery %s: %v", d.ID, err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// CompleteDelivery stores the outcome of a delivery attempt: its status, attempts and last response or error.
+// A pending delivery is retried after retryIn; a successful one records the delivery time. Like RenewDelivery,
+// the outcome is only stored while the delivery is pending and still leased until d.NextAttemptAt; otherwise
+// the lease was lost to another worker and it returns false without changing the delivery.
+func (r *webhookRepo) CompleteDelivery(ctx context.Context, d *model.WebhookDelivery, retryIn time.Duration) (bool, error) {
+	ctx = withQueryName(ctx, "webhookRepo.CompleteDelivery")
+
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2,
+			attempts = $3,
+			last_status = $4,
+			last_error = $5,
+			next_attempt_at = now() + make_interval(secs => $6),
+			delivered_at = CASE WHEN $2 = 'delivered' THEN now() END
+		WHERE id = $1 AND status = 'pending' AND next_attempt_at = $7
+		RETURNING next_attempt_at, delivered_at
+	`
+
+	err := conn(ctx, r.pool).
+		QueryRow(ctx, query, d.ID, d.Status, d.Attempts, d.LastStatus, d.LastError, retryIn.Seconds(), d.NextAttemptAt).
+		Scan(&d.NextAttemptAt, &d.DeliveredAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+
+	if err != nil {
+		log.Printf("ERROR: failed to store outcome of delivery %s: %v", d.ID, err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// webhookEvents returns the event filter of a webhook as stored, an empty array for all events.
+func webhookEvents(hook *model.Webhook) []string {
+	if hook.Events == nil {
+		return []string{}
+	}
+	return hook.Events
+}
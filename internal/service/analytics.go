@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+
+	"subscription-service/internal/model"
+)
+
+// CategorySpend calculates the spend per catalog category within the query window and the share of each
+// category in the total, in the target currency. The cost is aggregated per category in SQL like the summary
+// (see Aggregate for the charging, currency and authorization rules); shares are computed after the conversion
+// because amounts in different currencies cannot be compared before it. Any grouping keys of the query are ignored.
+func (s *subscriptionService) CategorySpend(ctx context.Context, q model.CostQuery) (*model.CategoryAnalytics, error) {
+	log.Printf("INFO: service category spend analytics")
+
+	q.GroupBy = []string{model.GroupByCategory}
+
+	summary, err := s.Aggregate(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	analytics := &model.CategoryAnalytics{
+		Total:      summary.Total,
+		Currency:   summary.Currency,
+		Categories: make([]model.CategorySpend, 0, len(summary.Buckets)),
+	}
+
+	for _, b := range summary.Buckets {
+		spend := model.CategorySpend{Category: *b.Category, Total: b.Total}
+		if summary.Total > 0 {
+			spend.Share = math.Round(float64(b.Total)*10000/float64(summary.Total)) / 100
+		}
+		analytics.Categories = append(analytics.Categories, spend)
+	}
+
+	sort.SliceStable(analytics.Categories, func(i, j int) bool {
+		return analytics.Categories[i].Total > analytics.Categories[j].Total
+	})
+
+	return analytics, nil
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log"
+	"slices"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyService defines the operations for minting, listing, revoking and checking API keys.
+type APIKeyService interface {
+	Create(ctx context.Context, name string, scopes []model.Scope) (*model.APIKey, string, error)
+	List(ctx context.Context) ([]*model.APIKey, error)
+	Revoke(ctx context.Context, id uuid.UUID) error
+	Authenticate(ctx context.Context, secret string) (*model.APIKey, error)
+}
+
+var (
+	ErrInvalidScope  = newError(KindValidation, "scopes", "scopes must be a non-empty list of subscriptions:read, subscriptions:write, summary:read")
+	ErrInvalidAPIKey = errors.New("invalid api key")
+)
+
+// apiKeyPrefix marks the secrets minted by this service.
+const apiKeyPrefix = "sk_"
+
+type apiKeyService struct {
+	repo repository.APIKeyRepository
+}
+
+// NewAPIKeyService creates a new instance of the API key service with the given repository.
+func NewAPIKeyService(repo repository.APIKeyRepository) APIKeyService {
+	return &apiKeyService{repo: repo}
+}
+
+// Create mints a key with the given scopes and returns it together with its secret.
+// Only the hash of the secret is stored, so the secret cannot be retrieved later. Only admins may mint keys.
+func (s *apiKeyService) Create(ctx context.Context, name string, scopes []model.Scope) (*model.APIKey, string, error) {
+	log.Printf("INFO: service create api key %q", name)
+
+	if err := requireAdmin(ctx); err != nil {
+		return nil, "", err
+	}
+
+	if len(scopes) == 0 {
+		return nil, "", ErrInvalidScope
+	}
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return nil, "", ErrInvalidScope
+		}
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", err
+	}
+	secret := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+
+	key := &model.APIKey{
+		Name:    name,
+		Prefix:  secret[:model.APIKeyPrefixLength],
+		KeyHash: hashAPIKey(secret),
+		Scopes:  slices.Compact(scopes),
+	}
+
+	if err := s.repo.Create(ctx, key); err != nil {
+		log.Printf("ERROR: repository create api key failed: %v", err)
+		return nil, "", err
+	}
+
+	return key, secret, nil
+}
+
+// List returns all API keys, including revoked ones. Only admins may list keys.
+func (s *apiKeyService) List(ctx context.Context) ([]*model.APIKey, error) {
+	log.Printf("INFO: service list api keys")
+
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.repo.List(ctx)
+}
+
+// Revoke disables an API key permanently. Only admins may revoke keys.
+func (s *apiKeyService) Revoke(ctx context.Context, id uuid.UUID) error {
+	log.Printf("INFO: service revoke api key %s", id)
+
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	if err := s.repo.Revoke(ctx, id); err != nil {
+		log.Printf("ERROR: revoke api key failed: %v", err)
+		return domainError(err)
+	}
+
+	return nil
+}
+
+// Authenticate returns the active key matching the secret and records its use.
+// It returns ErrInvalidAPIKey if the secret is unknown or the key was revoked.
+func (s *apiKeyService) Authenticate(ctx context.Context, secret string) (*model.APIKey, error) {
+	if secret == "" {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.MarkUsed(ctx, hashAPIKey(secret))
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	return key, err
+}
+
+// hashAPIKey returns the hex-encoded SHA-256 hash under which a secret is stored. Secrets carry 256 bits of
+// randomness, so a fast hash is sufficient and allows looking keys up by hash.
+func hashAPIKey(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// AuditService defines the operations for reading the subscription audit log.
+type AuditService interface {
+	History(ctx context.Context, subscriptionID uuid.UUID, limit, offset int) ([]*model.SubscriptionEvent, error)
+	List(ctx context.Context, query model.AuditQuery) ([]*model.SubscriptionEvent, error)
+}
+
+var (
+	ErrInvalidAuditAction = newError(KindValidation, "action", "action must be one of created, updated, deleted, restored, purged")
+	ErrInvalidAuditRange  = newError(KindValidation, "from", "from must be before to")
+)
+
+type auditService struct {
+	repo repository.AuditRepository
+}
+
+// NewAuditService creates a new instance of the audit service with the given repository.
+func NewAuditService(repo repository.AuditRepository) AuditService {
+	return &auditService{repo: repo}
+}
+
+// History returns the changes of a single subscription, newest first, including those made
+// after it was deleted or purged.
+func (s *auditService) History(
+	ctx context.Context,
+	subscriptionID uuid.UUID,
+	limit, offset int,
+) ([]*model.SubscriptionEvent, error) {
+	log.Printf("INFO: service history of subscription %s", subscriptionID)
+
+	return s.List(ctx, model.AuditQuery{SubscriptionID: &subscriptionID, Limit: limit, Offset: offset})
+}
+
+// List validates the filters and returns matching audit events with default values for pagination
+// (limit: 20, offset: 0) if they are not provided or invalid.
+// Non-admin callers only see events of their own subscriptions.
+func (s *auditService) List(ctx context.Context, q model.AuditQuery) ([]*model.SubscriptionEvent, error) {
+	log.Printf("INFO: service list audit events")
+
+	if own := restrictedUser(ctx); own != nil {
+		if q.UserID != nil && *q.UserID != *own {
+			return nil, ErrForbidden
+		}
+		q.UserID = own
+	}
+
+	if q.Action != nil && !q.Action.IsValid() {
+		return nil, ErrInvalidAuditAction
+	}
+
+	if q.From != nil && q.To != nil && !q.From.Before(*q.To) {
+		return nil, ErrInvalidAuditRange
+	}
+
+	if q.Limit <= 0 {
+		q.Limit = 20
+	}
+
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+
+	return s.repo.List(ctx, q)
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+
+	"subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// ErrForbidden is returned when the authenticated caller may not act on the requested user's data.
+var ErrForbidden = newError(KindForbidden, "", "access denied")
+
+// restrictedUser returns the only user whose data the caller may access, or nil when the caller is an admin,
+// a service using an API key or the request is not authenticated (authentication disabled).
+func restrictedUser(ctx context.Context) *uuid.UUID {
+	id, ok := model.IdentityFrom(ctx)
+	if !ok || id.Admin || id.APIKey != nil {
+		return nil
+	}
+	return &id.UserID
+}
+
+// authorizeUser returns ErrForbidden unless the caller may act on behalf of userID.
+func authorizeUser(ctx context.Context, userID uuid.UUID) error {
+	if own := restrictedUser(ctx); own != nil && *own != userID {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// requireAdmin returns ErrForbidden for authenticated callers without the admin role.
+func requireAdmin(ctx context.Context) error {
+	if id, ok := model.IdentityFrom(ctx); ok && !id.Admin {
+		return ErrForbidden
+	}
+	return nil
+}
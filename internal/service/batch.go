@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrBatchTooLarge = newError(KindValidation, "items", fmt.Sprintf("a batch may contain at most %d items", model.MaxBatchSize))
+	ErrDuplicateID   = newError(KindValidation, "id", "subscription appears more than once in the batch")
+)
+
+// CreateBatch validates and saves several subscriptions with the Create rules, including service name resolution
+// and overlaps with stored
+// subscriptions and with earlier items. The result holds the error of each
+// item (nil for created ones). With atomic set, nothing is saved unless every item is valid; otherwise the valid
+// items are saved and the invalid ones are reported. Non-admin callers may only create subscriptions for themselves.
+func (s *subscriptionService) CreateBatch(ctx context.Context, subs []*model.Subscription, atomic bool) ([]error, error) {
+	log.Printf("INFO: service create %d subscriptions", len(subs))
+
+	if len(subs) > model.MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	var results []error
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		results = make([]error, len(subs))
+		for i, sub := range subs {
+			results[i] = checkBatchSubscription(ctx, sub)
+		}
+
+		if err := s.resolveServices(ctx, subs); err != nil {
+			return err
+		}
+
+		if err := s.rejectOverlaps(ctx, subs, results); err != nil {
+			return err
+		}
+
+		valid := make([]*model.Subscription, 0, len(subs))
+		for i, sub := range subs {
+			if results[i] == nil {
+				valid = append(valid, sub)
+			}
+		}
+
+		if len(valid) == 0 || atomic && len(valid) < len(subs) {
+			return nil
+		}
+
+		if err := s.repo.CreateBatch(ctx, valid); err != nil {
+			log.Printf("ERROR: repository batch create failed: %v", err)
+			return err
+		}
+
+		log.Printf("INFO: %d subscriptions created", len(valid))
+		return nil
+	})
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return results, nil
+}
+
+// UpdateBatch validates and saves several existing subscriptions with the Update rules, including service name
+// resolution and overlaps. expectedVersions holds
+// the optional expected version of each item. The result holds the error of each item (nil for updated ones);
+// a subscription may appear only once. With atomic set, nothing is saved unless every item succeeds.
+// Non-admin callers may only update their own subscriptions and may not hand them to another user.
+func (s *subscriptionService) UpdateBatch(
+	ctx context.Context,
+	subs []*model.Subscription,
+	expectedVersions []*int,
+	atomic bool,
+) ([]error, error) {
+
+	log.Printf("INFO: service update %d subscriptions", len(subs))
+
+	if len(subs) > model.MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	var results []error
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		results = make([]error, len(subs))
+		seen := make(map[uuid.UUID]bool, len(subs))
+
+		var (
+			valid    []*model.Subscription
+			versions []*int
+			indexes  []int
+		)
+
+		for i, sub := range subs {
+			if seen[sub.ID] {
+				results[i] = ErrDuplicateID
+				continue
+			}
+			seen[sub.ID] = true
+			results[i] = checkBatchSubscription(ctx, sub)
+		}
+
+		if err := s.resolveServices(ctx, subs); err != nil {
+			return err
+		}
+
+		if err := s.rejectOverlaps(ctx, subs, results); err != nil {
+			return err
+		}
+
+		for i, sub := range subs {
+			if results[i] == nil {
+				valid = append(valid, sub)
+				versions = append(versions, expectedVersions[i])
+				indexes = append(indexes, i)
+			}
+		}
+
+		if len(valid) == 0 || atomic && len(valid) < len(subs) {
+			return nil
+		}
+
+		errs, err := s.repo.UpdateBatch(ctx, valid, versions, allowOwner(ctx), atomic)
+		if err != nil {
+			log.Printf("ERROR: repository batch update failed: %v", err)
+			return err
+		}
+
+		for j, err := range errs {
+			results[indexes[j]] = domainError(err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return results, nil
+}
+
+// DeleteBatch moves several subscriptions to the trash. The result holds the error of each item
+// (nil for deleted ones); a subscription may appear only once. With atomic set, nothing is deleted unless
+// every item succeeds. Non-admin callers may only delete their own subscriptions.
+func (s *subscriptionService) DeleteBatch(ctx context.Context, ids []uuid.UUID, atomic bool) ([]error, error) {
+	log.Printf("INFO: service delete %d subscriptions", len(ids))
+
+	if len(ids) > model.MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	results := make([]error, len(ids))
+	seen := make(map[uuid.UUID]bool, len(ids))
+
+	var (
+		unique  []uuid.UUID
+		indexes []int
+	)
+
+	for i, id := range ids {
+		if seen[id] {
+			results[i] = ErrDuplicateID
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+		indexes = append(indexes, i)
+	}
+
+	if len(unique) == 0 || atomic && len(unique) < len(ids) {
+		return results, nil
+	}
+
+	errs, err := s.repo.DeleteBatch(ctx, unique, allowOwner(ctx), atomic)
+	if err != nil {
+		log.Printf("ERROR: repository batch delete failed: %v", err)
+		return nil, domainError(err)
+	}
+
+	for j, err := range errs {
+		results[indexes[j]] = domainError(err)
+	}
+
+	return results, nil
+}
+
+// rejectOverlaps records in results the overlap errors of the subscriptions that have not failed yet.
+func (s *subscriptionService) rejectOverlaps(ctx context.Context, subs []*model.Subscription, results []error) error {
+	var (
+		pending []*model.Subscription
+		indexes []int
+	)
+	for i, sub := range subs {
+		if results[i] == nil {
+			pending = append(pending, sub)
+			indexes = append(indexes, i)
+		}
+	}
+
+	overlaps, err := s.findOverlaps(ctx, pending)
+	if err != nil {
+		return err
+	}
+
+	for j, err := range overlaps {
+		results[indexes[j]] = err
+	}
+	return nil
+}
+
+// checkBatchSubscription authorizes and validates a single item of a batch write.
+func checkBatchSubscription(ctx context.Context, sub *model.Subscription) error {
+	if err := authorizeUser(ctx, sub.UserID); err != nil {
+		return err
+	}
+	return validateSubscription(sub)
+}
+
+// allowOwner returns the check the repository applies to each stored subscription of a batch write.
+func allowOwner(ctx context.Context) func(before *model.Subscription) error {
+	return func(before *model.Subscription) error {
+		return authorizeUser(ctx, before.UserID)
+	}
+}
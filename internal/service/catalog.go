@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strings"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// CatalogService defines the business logic operations for managing the service catalog.
+type CatalogService interface {
+	Create(ctx context.Context, svc *model.Service) error
+	Get(ctx context.Context, id uuid.UUID) (*model.Service, error)
+	List(ctx context.Context, category *string) ([]*model.Service, error)
+	Update(ctx context.Context, svc *model.Service) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+var (
+	ErrInvalidServiceName = newError(KindValidation, "name", "name must be at least 2 characters")
+	ErrInvalidAlias       = newError(KindValidation, "aliases", "aliases must be at least 2 characters")
+	ErrNegativeDefault    = newError(KindValidation, "default_price", "default_price must be >= 0")
+)
+
+type catalogService struct {
+	repo repository.CatalogRepository
+}
+
+// NewCatalogService creates a new instance of the service catalog service with the given repository.
+func NewCatalogService(repo repository.CatalogRepository) CatalogService {
+	return &catalogService{repo: repo}
+}
+
+// Create validates and stores a catalog entry. Only admins may change the catalog.
+func (s *catalogService) Create(ctx context.Context, svc *model.Service) error {
+	log.Printf("INFO: service create catalog entry %q", svc.Name)
+
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	if err := normalizeService(svc); err != nil {
+		return err
+	}
+
+	if err := s.repo.Create(ctx, svc); err != nil {
+		log.Printf("ERROR: repository create service failed: %v", err)
+		return domainError(err)
+	}
+
+	return nil
+}
+
+// Get retrieves a catalog entry by its ID.
+func (s *catalogService) Get(ctx context.Context, id uuid.UUID) (*model.Service, error) {
+	log.Printf("INFO: service get catalog entry %s", id)
+
+	svc, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return svc, nil
+}
+
+// List returns the catalog entries, optionally of a single category.
+func (s *catalogService) List(ctx context.Context, category *string) ([]*model.Service, error) {
+	log.Printf("INFO: service list catalog entries")
+
+	return s.repo.List(ctx, normalizeCategoryPtr(category))
+}
+
+// Update validates and replaces a catalog entry. Only admins may change the catalog.
+func (s *catalogService) Update(ctx context.Context, svc *model.Service) error {
+	log.Printf("INFO: service update catalog entry %s", svc.ID)
+
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	if err := normalizeService(svc); err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(ctx, svc); err != nil {
+		log.Printf("ERROR: repository update service failed: %v", err)
+		return domainError(err)
+	}
+
+	return nil
+}
+
+// Delete removes a catalog entry; subscriptions linked to it are unlinked. Only admins may change the catalog.
+func (s *catalogService) Delete(ctx context.Context, id uuid.UUID) error {
+	log.Printf("INFO: service delete catalog entry %s", id)
+
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		log.Printf("ERROR: delete service failed: %v", err)
+		return domainError(err)
+	}
+
+	return nil
+}
+
+// normalizeService trims the name, normalizes the aliases and the category, drops aliases repeating the name
+// and defaults an empty currency to RUB. It rejects names and aliases shorter than 2 characters
+// and currencies that are not ISO 4217 codes.
+func normalizeService(svc *model.Service) error {
+	svc.Name = strings.TrimSpace(svc.Name)
+	if len([]rune(svc.Name)) < 2 {
+		return ErrInvalidServiceName
+	}
+
+	for _, alias := range svc.Aliases {
+		if len([]rune(model.NormalizeServiceName(alias))) < 2 {
+			return ErrInvalidAlias
+		}
+	}
+	svc.Aliases = svc.Names()[1:]
+
+	svc.Category = model.NormalizeCategory(svc.Category)
+
+	if svc.DefaultPrice != nil && *svc.DefaultPrice < 0 {
+		return ErrNegativeDefault
+	}
+
+	if svc.Currency == "" {
+		svc.Currency = model.DefaultCurrency
+	}
+	svc.Currency = strings.ToUpper(svc.Currency)
+	if model.Validate.Var(svc.Currency, "iso4217") != nil {
+		return ErrInvalidCurrency
+	}
+
+	return nil
+}
+
+// normalizeCategoryPtr returns a normalized copy of an optional category.
+func normalizeCategoryPtr(category *string) *string {
+	if category == nil {
+		return nil
+	}
+	normalized := model.NormalizeCategory(*category)
+	return &normalized
+}
+
+// resolveServices links subscriptions to the catalog entries their service names resolve to and replaces
+// those names with the canonical ones. Subscriptions with uncatalogued names are unlinked and keep their name.
+func (s *subscriptionService) resolveServices(ctx context.Context, subs []*model.Subscription) error {
+	if len(subs) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(subs))
+	for i, sub := range subs {
+		names[i] = sub.ServiceName
+	}
+
+	catalog, err := s.catalog.Resolve(ctx, names)
+	if err != nil {
+		log.Printf("ERROR: repository resolve service names failed: %v", err)
+		return err
+	}
+
+	for _, sub := range subs {
+		sub.ServiceID = nil
+		if svc, ok := catalog[model.NormalizeServiceName(sub.ServiceName)]; ok {
+			sub.ServiceID = &svc.ID
+			sub.ServiceName = svc.Name
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// CurrencyRateService defines the business logic operations for managing currency conversion rates.
+type CurrencyRateService interface {
+	Set(ctx context.Context, rate *model.CurrencyRate) error
+	List(ctx context.Context, baseCurrency, quoteCurrency *string) ([]*model.CurrencyRate, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+var (
+	ErrSameCurrency = newError(KindValidation, "quote_currency", "base_currency and quote_currency must differ")
+	ErrInvalidRate  = newError(KindValidation, "rate", "rate must be > 0")
+)
+
+type currencyRateService struct {
+	repo repository.CurrencyRateRepository
+}
+
+// NewCurrencyRateService creates a new instance of the currency rate service with the given repository.
+func NewCurrencyRateService(repo repository.CurrencyRateRepository) CurrencyRateService {
+	return &currencyRateService{repo: repo}
+}
+
+// Set validates and stores the rate for its currency pair and month, replacing an existing one.
+// Currency codes are upper-cased; the rate must be positive and the currencies must differ.
+// Only admins may change rates.
+func (s *currencyRateService) Set(ctx context.Context, rate *model.CurrencyRate) error {
+	log.Printf("INFO: service set currency rate %s/%s", rate.BaseCurrency, rate.QuoteCurrency)
+
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	rate.BaseCurrency = strings.ToUpper(rate.BaseCurrency)
+	rate.QuoteCurrency = strings.ToUpper(rate.QuoteCurrency)
+
+	if model.Validate.Var(rate.BaseCurrency, "iso4217") != nil ||
+		model.Validate.Var(rate.QuoteCurrency, "iso4217") != nil {
+		return ErrInvalidCurrency
+	}
+
+	if rate.BaseCurrency == rate.QuoteCurrency {
+		return ErrSameCurrency
+	}
+
+	if rate.Rate <= 0 {
+		return ErrInvalidRate
+	}
+
+	if err := s.repo.Upsert(ctx, rate); err != nil {
+		log.Printf("ERROR: repository upsert rate failed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// List returns currency rates filtered by optional base and quote currencies.
+func (s *currencyRateService) List(ctx context.Context, baseCurrency, quoteCurrency *string) ([]*model.CurrencyRate, error) {
+	log.Printf("INFO: service list currency rates")
+
+	return s.repo.List(ctx, upperPtr(baseCurrency), upperPtr(quoteCurrency))
+}
+
+// Delete removes a currency rate via the repository. Only admins may change rates.
+func (s *currencyRateService) Delete(ctx context.Context, id uuid.UUID) error {
+	log.Printf("INFO: service delete currency rate %s", id)
+
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		log.Printf("ERROR: delete currency rate failed: %v", err)
+		return domainError(err)
+	}
+
+	return nil
+}
+
+// upperPtr returns an upper-cased copy of an optional string.
+func upperPtr(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	upper := strings.ToUpper(*s)
+	return &upper
+}
+
+// rateBook converts amounts into a single target currency during one aggregation.
+// Rates for each source currency are loaded once, in both directions, and the inverse
+// of a quote->base rate is used when no direct base->quote rate is configured.
+type rateBook struct {
+	repo   repository.CurrencyRateRepository
+	target string
+	direct map[string][]*model.CurrencyRate
+	invert map[string][]*model.CurrencyRate
+}
+
+// newRateBook creates a rate book converting into the target currency.
+func newRateBook(repo repository.CurrencyRateRepository, target string) *rateBook {
+	return &rateBook{
+		repo:   repo,
+		target: target,
+		direct: make(map[string][]*model.CurrencyRate),
+		invert: make(map[string][]*model.CurrencyRate),
+	}
+}
+
+// convert turns an amount in minor units of currency into minor units of the target currency
+// using the rate effective for the given month. It returns ErrMissingRate if no rate is in force.
+func (b *rateBook) convert(ctx context.Context, amount float64, currency string, month time.Time) (float64, error) {
+	if currency == b.target {
+		return amount, nil
+	}
+
+	if _, ok := b.direct[currency]; !ok {
+		direct, err := b.repo.List(ctx, &currency, &b.target)
+		if err != nil {
+			return 0, err
+		}
+		inverse, err := b.repo.List(ctx, &b.target, &currency)
+		if err != nil {
+			return 0, err
+		}
+		b.direct[currency] = direct
+		b.invert[currency] = inverse
+	}
+
+	if rate := effectiveRate(b.direct[currency], month); rate != nil {
+		return model.ConvertMinor(amount, currency, b.target, rate.Rate), nil
+	}
+
+	if rate := effectiveRate(b.invert[currency], month); rate != nil {
+		return model.ConvertMinor(amount, currency, b.target, 1/rate.Rate), nil
+	}
+
+	return 0, fmt.Errorf("%w: no %s/%s rate effective for %s", ErrMissingRate, currency, b.target, month.Format("01-2006"))
+}
+
+// effectiveRate returns the latest rate effective on or before the month, or nil if there is none.
+// Rates must be ordered by EffectiveFrom.
+func effectiveRate(rates []*model.CurrencyRate, month time.Time) *model.CurrencyRate {
+	var found *model.CurrencyRate
+	for _, r := range rates {
+		if r.EffectiveFrom.After(month) {
+			break
+		}
+		found = r
+	}
+	return found
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"errors"
+
+	"subscription-service/internal/repository"
+)
+
+// Kind classifies domain errors so that transports can map them to a response status.
+type Kind string
+
+// Kinds of domain errors.
+const (
+	KindValidation Kind = "validation"
+	KindNotFound   Kind = "not_found"
+	KindConflict   Kind = "conflict"
+	KindForbidden  Kind = "forbidden"
+	// KindPrecondition reports a write rejected because the record changed since the version the caller expected.
+	KindPrecondition Kind = "precondition_failed"
+)
+
+// Error is a typed domain error. Field names the input field a validation error refers to, if any.
+// Err is the underlying cause, so errors.Is keeps matching repository errors wrapped by the service.
+type Error struct {
+	Kind    Kind
+	Field   string
+	Message string
+	Err     error
+}
+
+// Error returns the message, or the message of the cause when none is set.
+func (e *Error) Error() string {
+	if e.Message == "" && e.Err != nil {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+// Unwrap returns the cause of the error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError creates a sentinel domain error.
+func newError(kind Kind, field, message string) *Error {
+	return &Error{Kind: kind, Field: field, Message: message}
+}
+
+// invalid wraps err as a validation error of the given input field.
+func invalid(field string, err error) error {
+	return &Error{Kind: KindValidation, Field: field, Err: err}
+}
+
+// KindOf returns the kind of a domain error and false for other (internal) errors.
+func KindOf(err error) (Kind, bool) {
+	var derr *Error
+	if errors.As(err, &derr) {
+		return derr.Kind, true
+	}
+	return "", false
+}
+
+// domainError converts repository errors into typed domain errors; domain and other errors are returned unchanged.
+func domainError(err error) error {
+	var derr *Error
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &derr):
+		return err
+	case errors.Is(err, repository.ErrNotFound),
+		errors.Is(err, repository.ErrRateNotFound),
+		errors.Is(err, repository.ErrAPIKeyNotFound),
+		errors.Is(err, repository.ErrServiceNotFound),
+		errors.Is(err, repository.ErrWebhookNotFound),
+		errors.Is(err, repository.ErrDeliveryNotFound):
+		return &Error{Kind: KindNotFound, Err: err}
+	case errors.Is(err, repository.ErrVersionConflict):
+		return &Error{Kind: KindPrecondition, Err: err}
+	case errors.Is(err, repository.ErrOverlap),
+		errors.Is(err, repository.ErrServiceNameTaken):
+		return &Error{Kind: KindConflict, Err: err}
+	}
+	return err
+}
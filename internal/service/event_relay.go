@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"subscription-service/internal/events"
+	"subscription-service/internal/repository"
+)
+
+// EventRelay publishes the events of the event outbox to the message broker.
+type EventRelay interface {
+	Dispatch(ctx context.Context, now time.Time) (int, error)
+}
+
+// expiryLookbackDays bounds how long after a subscription became inactive its expiry event is still emitted,
+// so that a worker started after a long downtime (or for the first time) does not replay old expiries.
+const expiryLookbackDays = 7
+
+// enqueueExpired writes the expiry events of the subscriptions that became inactive by now to the outbox.
+// Both outbox consumers call it, so expiries are emitted whichever of them is enabled; each is enqueued once.
+func enqueueExpired(ctx context.Context, outbox repository.OutboxRepository, now time.Time) error {
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	_, err := outbox.EnqueueExpired(ctx, today.AddDate(0, 0, -expiryLookbackDays), today)
+	return err
+}
+
+type eventRelay struct {
+	outbox    repository.OutboxRepository
+	publisher events.EventPublisher
+	batchSize int
+	lease     time.Duration
+}
+
+// NewEventRelay creates a relay that publishes up to batchSize outbox events per dispatch with publisher. A claimed
+// event is not published by another relay for lease, which is renewed before each event is published and must
+// exceed the publisher's timeout.
+func NewEventRelay(
+	outbox repository.OutboxRepository,
+	publisher events.EventPublisher,
+	batchSize int,
+	lease time.Duration,
+) EventRelay {
+	return &eventRelay{outbox: outbox, publisher: publisher, batchSize: batchSize, lease: lease}
+}
+
+// Dispatch enqueues the expiry events of subscriptions that became inactive by now and publishes the unpublished
+// outbox events, oldest first. It returns the number of events published; an event the broker rejects is
+// retried, together with the events after it, by the next dispatch.
+func (r *eventRelay) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	if err := enqueueExpired(ctx, r.outbox, now); err != nil {
+		return 0, err
+	}
+
+	published, err := r.outbox.Relay(ctx, r.batchSize, r.lease, r.publisher.Publish)
+	if published > 0 {
+		log.Printf("INFO: published %d outbox events", published)
+	}
+	if err != nil {
+		log.Printf("WARN: publishing outbox events failed after %d events: %v", published, err)
+		return published, err
+	}
+
+	return published, nil
+}
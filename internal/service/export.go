@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"subscription-service/internal/model"
+)
+
+// Export validates the filter like List and calls fn for every matching subscription in the filter's sort order,
+// streaming them from the repository instead of loading them into memory. The filter is checked before fn is
+// called for the first time, so callers can still report a validation error. Non-admin callers only export
+// their own subscriptions.
+func (s *subscriptionService) Export(
+	ctx context.Context,
+	filter model.SubscriptionFilter,
+	fn func(sub *model.Subscription) error,
+) error {
+
+	log.Printf("INFO: service export subscriptions")
+
+	filter, err := scopeFilter(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.Export(ctx, filter, fn)
+}
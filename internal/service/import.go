@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"subscription-service/internal/model"
+)
+
+var (
+	ErrImportTooLarge = newError(KindValidation, "", fmt.Sprintf("an import may contain at most %d rows", model.MaxImportRows))
+	ErrDuplicate      = newError(KindConflict, "", "a subscription with the same user_id, service_name and start_date already exists")
+)
+
+// Import validates imported subscriptions with the Create rules, resolves their service names through the
+// catalog and saves the valid ones that are not duplicates.
+// A row is a duplicate if a live subscription or an earlier row has the same user, service and start month;
+// other rows overlapping a live subscription or an earlier row to the same service are rejected as well.
+// The result holds the error of each row (nil for rows that were, or in dry-run mode would be, created).
+// In dry-run mode nothing is written. Non-admin callers may only import their own subscriptions.
+func (s *subscriptionService) Import(ctx context.Context, subs []*model.Subscription, dryRun bool) ([]error, error) {
+	log.Printf("INFO: service import %d subscriptions (dry run: %t)", len(subs), dryRun)
+
+	if len(subs) > model.MaxImportRows {
+		return nil, ErrImportTooLarge
+	}
+
+	var results []error
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		results = make([]error, len(subs))
+		keys := make([]model.SubscriptionKey, 0, len(subs))
+
+		for i, sub := range subs {
+			results[i] = checkBatchSubscription(ctx, sub)
+		}
+
+		if err := s.resolveServices(ctx, subs); err != nil {
+			return err
+		}
+
+		for i, sub := range subs {
+			if results[i] == nil {
+				keys = append(keys, model.KeyOf(sub))
+			}
+		}
+
+		if len(keys) == 0 {
+			return nil
+		}
+
+		existing, err := s.repo.ExistingKeys(ctx, keys)
+		if err != nil {
+			log.Printf("ERROR: repository lookup of existing subscriptions failed: %v", err)
+			return err
+		}
+
+		valid := make([]*model.Subscription, 0, len(keys))
+		for i, sub := range subs {
+			if results[i] != nil {
+				continue
+			}
+
+			key := model.KeyOf(sub)
+			if existing[key] {
+				results[i] = ErrDuplicate
+				continue
+			}
+			existing[key] = true
+		}
+
+		if err := s.rejectOverlaps(ctx, subs, results); err != nil {
+			return err
+		}
+
+		for i, sub := range subs {
+			if results[i] == nil {
+				valid = append(valid, sub)
+			}
+		}
+
+		if dryRun || len(valid) == 0 {
+			return nil
+		}
+
+		if err := s.repo.CreateBatch(ctx, valid); err != nil {
+			log.Printf("ERROR: repository import failed: %v", err)
+			return err
+		}
+
+		log.Printf("INFO: %d subscriptions imported", len(valid))
+		return nil
+	})
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return results, nil
+}
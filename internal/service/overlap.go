@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+
+	"subscription-service/internal/model"
+
+	"github.com/google/uuid"
+)
+
+// OverlapError reports that a subscription overlaps another subscription of the same user to the same service.
+// ConflictingID is uuid.Nil when the other subscription is an earlier item of the same batch that is not stored yet.
+type OverlapError struct {
+	ConflictingID uuid.UUID
+}
+
+// Error names the conflicting subscription.
+func (e *OverlapError) Error() string {
+	if e.ConflictingID == uuid.Nil {
+		return "subscription overlaps an earlier item of the batch to the same service"
+	}
+	return "subscription overlaps subscription " + e.ConflictingID.String() + " to the same service"
+}
+
+// overlapError returns a conflict error for a subscription overlapping the subscription with the given ID.
+func overlapError(conflictingID uuid.UUID) error {
+	return &Error{Kind: KindConflict, Err: &OverlapError{ConflictingID: conflictingID}}
+}
+
+// findOverlaps returns the overlap error of each subscription about to be written (nil if it does not overlap).
+// A subscription conflicts with a stored live subscription of the same user to the same service, or with an
+// earlier subscription of the same write, whose period overlaps its own. Subscriptions that allow overlaps are
+// neither checked nor conflict with others; an unset override is resolved by the repository from the stored
+// subscription being replaced.
+func (s *subscriptionService) findOverlaps(ctx context.Context, subs []*model.Subscription) ([]error, error) {
+	results := make([]error, len(subs))
+
+	var (
+		pending []*model.Subscription
+		indexes []int
+	)
+
+	for i, sub := range subs {
+		if !sub.OverlapAllowed() {
+			pending = append(pending, sub)
+			indexes = append(indexes, i)
+		}
+	}
+
+	if len(pending) == 0 {
+		return results, nil
+	}
+
+	conflicts, allowed, err := s.repo.FindOverlaps(ctx, pending)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	var checked []*model.Subscription
+
+	for j, sub := range pending {
+		if allowed[j] {
+			continue
+		}
+
+		i := indexes[j]
+		for _, earlier := range checked {
+			if sub.Overlaps(earlier) {
+				results[i] = overlapError(earlier.ID)
+				break
+			}
+		}
+
+		if results[i] == nil {
+			checked = append(checked, sub)
+			if conflicts[j] != uuid.Nil {
+				results[i] = overlapError(conflicts[j])
+			}
+		}
+	}
+
+	return results, nil
+}
+
+// checkOverlap returns a conflict error if the subscription overlaps a stored one.
+func (s *subscriptionService) checkOverlap(ctx context.Context, sub *model.Subscription) error {
+	results, err := s.findOverlaps(ctx, []*model.Subscription{sub})
+	if err != nil {
+		return err
+	}
+	return results[0]
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/notify"
+	"subscription-service/internal/repository"
+)
+
+// ReminderService emits reminders of upcoming charges and subscription ends.
+type ReminderService interface {
+	Dispatch(ctx context.Context, now time.Time) (int, error)
+}
+
+type reminderService struct {
+	subs       repository.SubscriptionRepository
+	reminders  repository.ReminderRepository
+	notifier   notify.Notifier
+	daysBefore int
+}
+
+// NewReminderService creates a reminder service that notifies daysBefore days ahead of charges and
+// subscription ends through the given notifier and records sent reminders in the reminder repository.
+func NewReminderService(
+	subs repository.SubscriptionRepository,
+	reminders repository.ReminderRepository,
+	notifier notify.Notifier,
+	daysBefore int,
+) ReminderService {
+	return &reminderService{subs: subs, reminders: reminders, notifier: notifier, daysBefore: daysBefore}
+}
+
+// Dispatch sends the reminders due at now that have not been sent yet and returns how many were sent.
+// A reminder is due from daysBefore days before a charge of a live subscription, or before the first day
+// a subscription is no longer active, until that day; reminders missed while the scheduler was not running
+// are therefore caught up. Every reminder is claimed in the repository before it is delivered so that it is
+// sent once; reminders whose delivery fails are released and retried by a later dispatch.
+func (s *reminderService) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	log.Printf("INFO: service dispatch reminders")
+
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	until := today.AddDate(0, 0, s.daysBefore)
+
+	var charged []*model.Subscription
+	var reminders []*model.Reminder
+
+	err := s.subs.Export(ctx, model.SubscriptionFilter{Sort: model.Sort{Field: model.SortStartDate}}, func(sub *model.Subscription) error {
+		if len(sub.ChargeDates(today, until)) > 0 {
+			charged = append(charged, sub)
+		}
+		if end := sub.EndsAt(); end != nil && end.After(today) && !end.After(until) {
+			reminders = append(reminders, &model.Reminder{Kind: model.ReminderEnd, Subscription: sub, Date: *end})
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("ERROR: read subscriptions for reminders failed: %v", err)
+		return 0, err
+	}
+
+	charges, err := upcomingCharges(ctx, s.subs, charged, today, until)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, c := range charges {
+		reminders = append(reminders, &model.Reminder{
+			Kind:         model.ReminderCharge,
+			Subscription: c.Subscription,
+			Date:         c.Date,
+			Amount:       c.Amount,
+		})
+	}
+
+	sort.SliceStable(reminders, func(i, j int) bool {
+		return reminders[i].Date.Before(reminders[j].Date)
+	})
+
+	sent := 0
+	for _, reminder := range reminders {
+		reminder.DaysLeft = int(reminder.Date.Sub(today).Hours() / 24)
+
+		claimed, err := s.reminders.Claim(ctx, reminder)
+		if err != nil {
+			return sent, err
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.notifier.Notify(ctx, reminder); err != nil {
+			log.Printf("ERROR: deliver %s reminder of subscription %s failed: %v", reminder.Kind, reminder.Subscription.ID, err)
+			if err := s.reminders.Release(ctx, reminder); err != nil {
+				return sent, err
+			}
+			continue
+		}
+		sent++
+	}
+
+	log.Printf("INFO: sent %d reminders", sent)
+	return sent, nil
+}
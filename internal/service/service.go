@@ -2,9 +2,13 @@ package service
 
 import (
 	"context"
-	"errors"
 	"log"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"subscription-service/internal/model"
 	"subscription-service/internal/repository"
@@ -16,157 +20,679 @@ import (
 type SubscriptionService interface {
 	Create(ctx context.Context, sub *model.Subscription) error
 	Get(ctx context.Context, id uuid.UUID) (*model.Subscription, error)
-	Update(ctx context.Context, sub *model.Subscription) error
-	Delete(ctx context.Context, id uuid.UUID) error
-	List(
-		ctx context.Context,
-		userID *uuid.UUID,
-		serviceName *string,
-		limit, offset int,
-	) ([]*model.Subscription, error)
-
-	Aggregate(
-		ctx context.Context,
-		userID *uuid.UUID,
-		serviceName *string,
-		from time.Time,
-		to time.Time,
-	) (int, error)
+	Update(ctx context.Context, sub *model.Subscription, expectedVersion *int) error
+	Patch(ctx context.Context, id uuid.UUID, patch *model.SubscriptionPatch, expectedVersion *int) (*model.Subscription, error)
+	Delete(ctx context.Context, id uuid.UUID, expectedVersion *int) error
+	Restore(ctx context.Context, id uuid.UUID) (*model.Subscription, error)
+	Purge(ctx context.Context, olderThanDays int) (int64, error)
+
+	CreateBatch(ctx context.Context, subs []*model.Subscription, atomic bool) ([]error, error)
+	UpdateBatch(ctx context.Context, subs []*model.Subscription, expectedVersions []*int, atomic bool) ([]error, error)
+	DeleteBatch(ctx context.Context, ids []uuid.UUID, atomic bool) ([]error, error)
+	Import(ctx context.Context, subs []*model.Subscription, dryRun bool) ([]error, error)
+	Export(ctx context.Context, filter model.SubscriptionFilter, fn func(sub *model.Subscription) error) error
+	List(ctx context.Context, filter model.SubscriptionFilter, page model.Page) (*model.SubscriptionPage, error)
+
+	SchedulePrice(ctx context.Context, change *model.PriceChange) error
+	ListPrices(ctx context.Context, subscriptionID uuid.UUID) ([]*model.PriceChange, error)
+	Aggregate(ctx context.Context, query model.CostQuery) (*model.CostSummary, error)
+	CategorySpend(ctx context.Context, query model.CostQuery) (*model.CategoryAnalytics, error)
+	Upcoming(ctx context.Context, userID uuid.UUID, days int) (*model.Upcoming, error)
 }
 
 var (
-	ErrInvalidPeriod = errors.New("invalid aggregation period")
+	ErrNegativePrice   = newError(KindValidation, "price", "price must be >= 0")
+	ErrEndBeforeStart  = newError(KindValidation, "end_date", "end_date cannot be before start_date")
+	ErrInvalidPurge    = newError(KindValidation, "older_than_days", "older_than_days must be >= 0")
+	ErrInvalidFilter   = newError(KindValidation, "", "invalid filter: range lower bound exceeds upper bound")
+	ErrPastPriceChange = newError(KindValidation, "effective_from", "price changes can only be scheduled for the current or a future month")
+
+	ErrInvalidPeriod  = newError(KindValidation, "from", "invalid aggregation period")
+	ErrInvalidGroupBy = newError(KindValidation, "group_by", "invalid group_by: allowed keys are month, service, user, category")
+
+	ErrInvalidBillingPeriod = newError(KindValidation, "billing_period", "billing_period must be one of monthly, quarterly, yearly, weekly")
+	ErrInvalidCurrency      = newError(KindValidation, "currency", "currency must be an ISO 4217 code")
+	ErrCurrencyRequired     = newError(KindValidation, "currency", "subscriptions are priced in several currencies: specify the target currency")
+	ErrMissingRate          = newError(KindValidation, "currency", "missing currency rate")
 )
 
 type subscriptionService struct {
-	repo repository.SubscriptionRepository
+	repo    repository.SubscriptionRepository
+	rates   repository.CurrencyRateRepository
+	catalog repository.CatalogRepository
+	tx      repository.TxManager
 }
 
-// NewSubscriptionService creates a new instance of the subscription service with the given repository.
-func NewSubscriptionService(repo repository.SubscriptionRepository) SubscriptionService {
-	return &subscriptionService{repo: repo}
+// NewSubscriptionService creates a new instance of the subscription service with the given repositories.
+// The currency rate repository is used to convert aggregated costs into the requested currency
+// and the catalog repository to resolve service names to catalog entries. Operations that read before they
+// write (ownership, catalog and overlap checks) run as a unit of work of the transaction manager.
+func NewSubscriptionService(
+	repo repository.SubscriptionRepository,
+	rates repository.CurrencyRateRepository,
+	catalog repository.CatalogRepository,
+	tx repository.TxManager,
+) SubscriptionService {
+	return &subscriptionService{repo: repo, rates: rates, catalog: catalog, tx: tx}
 }
 
 // Create validates and saves a new subscription.
-// It returns an error if the price is negative or if the end date is before the start date.
+// It returns an error if the price is negative, the currency or billing period is unknown
+// or the end date is before the start date. An empty currency defaults to RUB and an empty billing period to monthly.
+// A service name found in the service catalog (by name or alias) is replaced with the canonical name and the
+// subscription is linked to the catalog entry. Unless sub.AllowOverlap is set, it returns a conflict (*OverlapError) if the user already has a subscription
+// to the same service in an overlapping period. Non-admin callers may only create subscriptions for themselves.
 func (s *subscriptionService) Create(ctx context.Context, sub *model.Subscription) error {
 	log.Printf("INFO: service create subscription for user %s", sub.UserID)
 
-	if sub.Price < 0 {
-		log.Printf("ERROR: negative price")
-		return errors.New("price must be >= 0")
+	if err := authorizeUser(ctx, sub.UserID); err != nil {
+		return err
 	}
 
-	if sub.EndDate != nil && sub.EndDate.Before(sub.StartDate) {
-		log.Printf("ERROR: end_date before start_date")
-		return errors.New("end_date cannot be before start_date")
+	if err := validateSubscription(sub); err != nil {
+		log.Printf("ERROR: %v", err)
+		return err
 	}
 
-	err := s.repo.Create(ctx, sub)
-	if err != nil {
-		log.Printf("ERROR: repository create failed: %v", err)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.resolveServices(ctx, []*model.Subscription{sub}); err != nil {
+			return err
+		}
+
+		if err := s.checkOverlap(ctx, sub); err != nil {
+			log.Printf("ERROR: %v", err)
+			return err
+		}
+
+		err := s.repo.Create(ctx, sub)
+		if err != nil {
+			log.Printf("ERROR: repository create failed: %v", err)
+		}
 		return err
+	})
+	if err != nil {
+		return domainError(err)
 	}
 
 	log.Printf("INFO: subscription created: %s", sub.ID)
 	return nil
 }
 
+// validateSubscription checks the price and dates of a subscription and normalizes its currency and billing period.
+func validateSubscription(sub *model.Subscription) error {
+	if sub.Price < 0 {
+		return ErrNegativePrice
+	}
+
+	if err := normalizeCurrency(sub); err != nil {
+		return err
+	}
+
+	if err := normalizeBillingPeriod(sub); err != nil {
+		return err
+	}
+
+	if sub.EndDate != nil && sub.EndDate.Before(sub.StartDate) {
+		return ErrEndBeforeStart
+	}
+
+	return nil
+}
+
 // Get retrieves a subscription by its ID from the repository.
+// Non-admin callers may only read their own subscriptions.
 func (s *subscriptionService) Get(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
 	log.Printf("INFO: service get subscription %s", id)
 
 	sub, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		log.Printf("ERROR: get subscription failed: %v", err)
+		return nil, domainError(err)
+	}
+
+	if err := authorizeUser(ctx, sub.UserID); err != nil {
 		return nil, err
 	}
 
 	return sub, nil
 }
 
+// authorizeSubscription returns ErrForbidden unless the caller may act on the subscription, live or deleted.
+// The owner is only looked up for callers restricted to their own subscriptions.
+func (s *subscriptionService) authorizeSubscription(ctx context.Context, id uuid.UUID) error {
+	if restrictedUser(ctx) == nil {
+		return nil
+	}
+
+	owner, err := s.repo.Owner(ctx, id)
+	if err != nil {
+		return domainError(err)
+	}
+
+	return authorizeUser(ctx, owner)
+}
+
 // Update validates and updates an existing subscription.
-// It enforces the same validation rules as the Create method (price and dates).
-func (s *subscriptionService) Update(ctx context.Context, sub *model.Subscription) error {
+// It enforces the same validation, service name resolution and overlap rules as the Create method.
+// When expectedVersion is set, the update only succeeds if the stored version matches it.
+// Non-admin callers may only update their own subscriptions and may not hand them to another user.
+func (s *subscriptionService) Update(ctx context.Context, sub *model.Subscription, expectedVersion *int) error {
 	log.Printf("INFO: service update subscription %s", sub.ID)
 
-	if sub.Price < 0 {
-		return errors.New("price must be >= 0")
-	}
+	return s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.authorizeSubscription(ctx, sub.ID); err != nil {
+			return err
+		}
+		if err := authorizeUser(ctx, sub.UserID); err != nil {
+			return err
+		}
 
-	if sub.EndDate != nil && sub.EndDate.Before(sub.StartDate) {
-		return errors.New("end_date cannot be before start_date")
+		return s.update(ctx, sub, expectedVersion)
+	})
+}
+
+// update validates and saves an existing subscription without checking who owns it.
+// It runs as a unit of work, joining the caller's one if there is any.
+func (s *subscriptionService) update(ctx context.Context, sub *model.Subscription, expectedVersion *int) error {
+	if err := validateSubscription(sub); err != nil {
+		return err
 	}
 
-	err := s.repo.Update(ctx, sub)
-	if err != nil {
-		log.Printf("ERROR: repository update failed: %v", err)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.resolveServices(ctx, []*model.Subscription{sub}); err != nil {
+			return err
+		}
+
+		if err := s.checkOverlap(ctx, sub); err != nil {
+			return err
+		}
+
+		err := s.repo.Update(ctx, sub, expectedVersion)
+		if err != nil {
+			log.Printf("ERROR: repository update failed: %v", err)
+		}
 		return err
+	})
+	if err != nil {
+		return domainError(err)
 	}
 
 	log.Printf("INFO: subscription updated %s", sub.ID)
 	return nil
 }
 
-// Delete removes a subscription record via the repository.
-func (s *subscriptionService) Delete(ctx context.Context, id uuid.UUID) error {
+// Patch applies a JSON Merge Patch to an existing subscription and saves it with the Update validation rules.
+// The write is conditional on the version that was read, so concurrent modifications are never overwritten;
+// when expectedVersion is set, it must also match the stored version.
+// It returns repository.ErrVersionConflict if the subscription was modified in the meantime.
+// Non-admin callers may only patch their own subscriptions.
+func (s *subscriptionService) Patch(
+	ctx context.Context,
+	id uuid.UUID,
+	patch *model.SubscriptionPatch,
+	expectedVersion *int,
+) (*model.Subscription, error) {
+
+	log.Printf("INFO: service patch subscription %s", id)
+
+	var sub *model.Subscription
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		sub, err = s.repo.GetByID(ctx, id)
+		if err != nil {
+			log.Printf("ERROR: get subscription failed: %v", err)
+			return domainError(err)
+		}
+
+		if err := authorizeUser(ctx, sub.UserID); err != nil {
+			return err
+		}
+
+		if expectedVersion != nil && sub.Version != *expectedVersion {
+			log.Printf("WARN: subscription %s version %d does not match %d", id, sub.Version, *expectedVersion)
+			return domainError(repository.ErrVersionConflict)
+		}
+
+		if err := patch.Apply(sub); err != nil {
+			return invalid("", err)
+		}
+		if err := authorizeUser(ctx, sub.UserID); err != nil {
+			return err
+		}
+
+		readVersion := sub.Version
+		return s.update(ctx, sub, &readVersion)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// Delete moves a subscription to the trash (soft delete) via the repository.
+// When expectedVersion is set, the record is only deleted if the stored version matches it.
+// Non-admin callers may only delete their own subscriptions.
+func (s *subscriptionService) Delete(ctx context.Context, id uuid.UUID, expectedVersion *int) error {
 	log.Printf("INFO: service delete subscription %s", id)
 
-	err := s.repo.Delete(ctx, id)
-	if err != nil {
-		log.Printf("ERROR: delete failed: %v", err)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.authorizeSubscription(ctx, id); err != nil {
+			return err
+		}
+
+		err := s.repo.Delete(ctx, id, expectedVersion)
+		if err != nil {
+			log.Printf("ERROR: delete failed: %v", err)
+		}
 		return err
+	})
+	if err != nil {
+		return domainError(err)
 	}
 
 	log.Printf("INFO: subscription deleted %s", id)
 	return nil
 }
 
-// List fetches a collection of subscriptions with default values for pagination (limit: 20, offset: 0)
-// if they are not provided or invalid.
+// Restore brings a soft-deleted subscription back from the trash.
+// Non-admin callers may only restore their own subscriptions.
+func (s *subscriptionService) Restore(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	log.Printf("INFO: service restore subscription %s", id)
+
+	var sub *model.Subscription
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.authorizeSubscription(ctx, id); err != nil {
+			return err
+		}
+
+		var err error
+		sub, err = s.repo.Restore(ctx, id)
+		if err != nil {
+			log.Printf("ERROR: restore failed: %v", err)
+		}
+		return err
+	})
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	log.Printf("INFO: subscription restored %s", id)
+	return sub, nil
+}
+
+// Purge permanently removes subscriptions that were soft-deleted more than olderThanDays days ago.
+// It returns ErrInvalidPurge if olderThanDays is negative. Only admins may purge the trash.
+func (s *subscriptionService) Purge(ctx context.Context, olderThanDays int) (int64, error) {
+	log.Printf("INFO: service purge subscriptions deleted more than %d days ago", olderThanDays)
+
+	if err := requireAdmin(ctx); err != nil {
+		return 0, err
+	}
+
+	if olderThanDays < 0 {
+		return 0, ErrInvalidPurge
+	}
+
+	return s.repo.Purge(ctx, time.Now().AddDate(0, 0, -olderThanDays))
+}
+
+// List validates the filter and fetches a page of matching subscriptions with default values for sorting
+// (newest first) and pagination (limit: 20, offset: 0) if they are not provided or invalid.
+// A page that starts after a cursor ignores the offset; the cursor must have been issued for the same sort.
+// Non-admin callers only see their own subscriptions and may not ask for other users'.
 func (s *subscriptionService) List(
 	ctx context.Context,
-	userID *uuid.UUID,
-	serviceName *string,
-	limit, offset int,
-) ([]*model.Subscription, error) {
+	filter model.SubscriptionFilter,
+	page model.Page,
+) (*model.SubscriptionPage, error) {
 
 	log.Printf("INFO: service list subscriptions")
 
-	if limit <= 0 {
-		limit = 20
+	filter, err := scopeFilter(ctx, filter)
+	if err != nil {
+		return nil, err
 	}
 
-	if offset < 0 {
-		offset = 0
+	if page.After != nil && (page.After.Sort != filter.Sort.String() || !validCursorValue(filter.Sort.Field, page.After.Value)) {
+		return nil, invalid("cursor", model.ErrInvalidCursor)
 	}
 
-	return s.repo.List(ctx, userID, serviceName, limit, offset)
+	if page.Limit <= 0 {
+		page.Limit = 20
+	}
+
+	if page.Offset < 0 || page.After != nil {
+		page.Offset = 0
+	}
+
+	return s.repo.List(ctx, filter, page)
 }
 
-// Aggregate calculates the total cost of subscriptions for a specific period.
-// It returns ErrInvalidPeriod if the start time (from) is after the end time (to).
-func (s *subscriptionService) Aggregate(
-	ctx context.Context,
-	userID *uuid.UUID,
-	serviceName *string,
-	from time.Time,
-	to time.Time,
-) (int, error) {
+// validCursorValue reports whether a cursor value, which comes from the client, has the type of the sort field,
+// so that a tampered cursor is rejected instead of failing the query.
+func validCursorValue(field, value string) bool {
+	var err error
+	switch field {
+	case model.SortCreatedAt:
+		_, err = time.Parse("2006-01-02T15:04:05.999999", value)
+	case model.SortPrice:
+		_, err = strconv.ParseInt(value, 10, 64)
+	case model.SortStartDate:
+		_, err = time.Parse("2006-01-02", value)
+	default:
+		return utf8.ValidString(value) && !strings.ContainsRune(value, 0)
+	}
+	return err == nil
+}
 
-	log.Printf("INFO: service aggregate subscriptions")
+// scopeFilter restricts the filter to the caller's own subscriptions, defaults the sort order to newest first,
+// normalizes the category and validates it. Non-admin callers may not ask for other users' subscriptions.
+func scopeFilter(ctx context.Context, filter model.SubscriptionFilter) (model.SubscriptionFilter, error) {
+	if own := restrictedUser(ctx); own != nil {
+		for _, userID := range filter.UserIDs {
+			if userID != *own {
+				return filter, ErrForbidden
+			}
+		}
+		filter.UserIDs = []uuid.UUID{*own}
+	}
 
-	if from.After(to) {
+	if filter.Sort.Field == "" {
+		filter.Sort = model.DefaultSort
+	}
+	filter.Category = normalizeCategoryPtr(filter.Category)
+
+	return filter, validateFilter(filter)
+}
+
+// validateFilter rejects unknown sort fields, negative prices and empty ranges.
+func validateFilter(f model.SubscriptionFilter) error {
+	if _, err := model.ParseSort(f.Sort.String()); err != nil {
+		return invalid("sort", err)
+	}
+
+	if (f.MinPrice != nil && *f.MinPrice < 0) || (f.MaxPrice != nil && *f.MaxPrice < 0) {
+		return ErrNegativePrice
+	}
+
+	if f.MinPrice != nil && f.MaxPrice != nil && *f.MinPrice > *f.MaxPrice {
+		return ErrInvalidFilter
+	}
+
+	if f.StartFrom != nil && f.StartTo != nil && f.StartFrom.After(*f.StartTo) {
+		return ErrInvalidFilter
+	}
+
+	if f.EndFrom != nil && f.EndTo != nil && f.EndFrom.After(*f.EndTo) {
+		return ErrInvalidFilter
+	}
+
+	return nil
+}
+
+// SchedulePrice validates and stores a price change of a subscription effective from the given month.
+// The month must not be in the past so that already charged months keep their price.
+// Non-admin callers may only change the price of their own subscriptions.
+func (s *subscriptionService) SchedulePrice(ctx context.Context, change *model.PriceChange) error {
+	log.Printf("INFO: service schedule price change of subscription %s", change.SubscriptionID)
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.authorizeSubscription(ctx, change.SubscriptionID); err != nil {
+			return err
+		}
+
+		if change.Price < 0 {
+			return ErrNegativePrice
+		}
+
+		now := time.Now()
+		currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if change.EffectiveFrom.Before(currentMonth) {
+			return ErrPastPriceChange
+		}
+
+		err := s.repo.SchedulePrice(ctx, change)
+		if err != nil {
+			log.Printf("ERROR: schedule price change failed: %v", err)
+		}
+		return err
+	})
+
+	return domainError(err)
+}
+
+// ListPrices returns the price history of a subscription, including scheduled changes.
+// Returns repository.ErrNotFound if the subscription does not exist or is soft-deleted.
+func (s *subscriptionService) ListPrices(ctx context.Context, subscriptionID uuid.UUID) ([]*model.PriceChange, error) {
+	log.Printf("INFO: service list prices of subscription %s", subscriptionID)
+
+	if _, err := s.Get(ctx, subscriptionID); err != nil {
+		return nil, err
+	}
+
+	return s.repo.ListPrices(ctx, subscriptionID)
+}
+
+// Aggregate calculates the total cost of subscriptions for the query window in the target currency,
+// optionally broken down by the grouping keys (month, service, user, category).
+// Every subscription is charged according to its billing period for each month it is active within the window;
+// with query.Amortize set, non-monthly prices are spread evenly over the months instead.
+//
+// Costs in other currencies are converted with the rate effective for each month. Without a target currency
+// all subscriptions must share one currency, otherwise ErrCurrencyRequired is returned.
+// It returns ErrInvalidPeriod if From is after To, ErrInvalidGroupBy for unknown or repeated keys
+// and ErrMissingRate if a required rate is not configured.
+// Non-admin callers may only aggregate their own subscriptions.
+func (s *subscriptionService) Aggregate(ctx context.Context, q model.CostQuery) (*model.CostSummary, error) {
+	log.Printf("INFO: service aggregate subscriptions grouped by %v", q.GroupBy)
+
+	if own := restrictedUser(ctx); own != nil {
+		if q.UserID != nil && *q.UserID != *own {
+			return nil, ErrForbidden
+		}
+		q.UserID = own
+	}
+
+	if q.From.After(q.To) {
 		log.Printf("ERROR: invalid aggregation period")
-		return 0, ErrInvalidPeriod
+		return nil, ErrInvalidPeriod
 	}
 
-	total, err := s.repo.AggregateCost(ctx, userID, serviceName, from, to)
+	if err := validateGroupBy(q.GroupBy); err != nil {
+		log.Printf("ERROR: %v", err)
+		return nil, err
+	}
+
+	q.Category = normalizeCategoryPtr(q.Category)
+
+	if q.Currency != "" {
+		q.Currency = strings.ToUpper(q.Currency)
+		if model.Validate.Var(q.Currency, "iso4217") != nil {
+			return nil, ErrInvalidCurrency
+		}
+	}
+
+	raw, err := s.repo.AggregateCost(ctx, q)
 	if err != nil {
 		log.Printf("ERROR: aggregation failed: %v", err)
-		return 0, err
+		return nil, err
+	}
+
+	target, err := targetCurrency(q.Currency, raw)
+	if err != nil {
+		log.Printf("ERROR: %v", err)
+		return nil, err
+	}
+
+	book := newRateBook(s.rates, target)
+
+	type bucketKey struct {
+		period   time.Time
+		service  string
+		user     uuid.UUID
+		category string
+	}
+
+	var (
+		keys    []bucketKey
+		amounts = make(map[bucketKey]float64)
+		buckets = make(map[bucketKey]model.CostBucket)
+		grand   float64
+	)
+
+	for _, b := range raw {
+		amount, err := book.convert(ctx, float64(b.Total), b.Currency, *b.Period)
+		if err != nil {
+			log.Printf("ERROR: conversion failed: %v", err)
+			return nil, err
+		}
+		grand += amount
+
+		if len(q.GroupBy) == 0 {
+			continue
+		}
+
+		var (
+			key    bucketKey
+			bucket = model.CostBucket{Currency: target}
+		)
+		for _, k := range q.GroupBy {
+			switch k {
+			case model.GroupByMonth:
+				key.period = *b.Period
+				bucket.Period = b.Period
+			case model.GroupByService:
+				key.service = *b.ServiceName
+				bucket.ServiceName = b.ServiceName
+			case model.GroupByUser:
+				key.user = *b.UserID
+				bucket.UserID = b.UserID
+			case model.GroupByCategory:
+				key.category = *b.Category
+				bucket.Category = b.Category
+			}
+		}
+
+		if _, ok := buckets[key]; !ok {
+			keys = append(keys, key)
+			buckets[key] = bucket
+		}
+		amounts[key] += amount
+	}
+
+	summary := &model.CostSummary{
+		Currency: target,
+		GroupBy:  q.GroupBy,
+	}
+
+	if len(q.GroupBy) == 0 {
+		summary.Total = int(math.Round(grand))
+		log.Printf("INFO: aggregation result = %d %s", summary.Total, target)
+		return summary, nil
 	}
 
-	log.Printf("INFO: aggregation result = %d", total)
-	return total, nil
+	// Buckets are rounded individually and the grand total is their sum, so the breakdown always adds up.
+	summary.Buckets = make([]model.CostBucket, 0, len(keys))
+	for _, key := range keys {
+		bucket := buckets[key]
+		bucket.Total = int(math.Round(amounts[key]))
+		summary.Total += bucket.Total
+		summary.Buckets = append(summary.Buckets, bucket)
+	}
+	sortBuckets(summary.Buckets, q.GroupBy)
+
+	log.Printf("INFO: aggregation result = %d %s in %d buckets", summary.Total, target, len(summary.Buckets))
+	return summary, nil
+}
+
+// targetCurrency returns the requested currency, or the single currency used by all buckets when none is requested.
+func targetCurrency(requested string, buckets []model.CostBucket) (string, error) {
+	if requested != "" {
+		return requested, nil
+	}
+
+	var currency string
+	for _, b := range buckets {
+		if currency != "" && b.Currency != currency {
+			return "", ErrCurrencyRequired
+		}
+		currency = b.Currency
+	}
+
+	return currency, nil
+}
+
+// sortBuckets orders buckets by the grouping keys in the requested order.
+func sortBuckets(buckets []model.CostBucket, groupBy []string) {
+	sort.SliceStable(buckets, func(i, j int) bool {
+		a, b := buckets[i], buckets[j]
+		for _, key := range groupBy {
+			switch key {
+			case model.GroupByMonth:
+				if !a.Period.Equal(*b.Period) {
+					return a.Period.Before(*b.Period)
+				}
+			case model.GroupByService:
+				if *a.ServiceName != *b.ServiceName {
+					return *a.ServiceName < *b.ServiceName
+				}
+			case model.GroupByUser:
+				if *a.UserID != *b.UserID {
+					return a.UserID.String() < b.UserID.String()
+				}
+			case model.GroupByCategory:
+				if *a.Category != *b.Category {
+					return *a.Category < *b.Category
+				}
+			}
+		}
+		return false
+	})
+}
+
+// normalizeCurrency defaults an empty currency to RUB, upper-cases it and rejects codes that are not ISO 4217.
+func normalizeCurrency(sub *model.Subscription) error {
+	if sub.Currency == "" {
+		sub.Currency = model.DefaultCurrency
+	}
+
+	sub.Currency = strings.ToUpper(sub.Currency)
+	if model.Validate.Var(sub.Currency, "iso4217") != nil {
+		return ErrInvalidCurrency
+	}
+
+	return nil
+}
+
+// normalizeBillingPeriod defaults an empty billing period to monthly and rejects unknown values.
+func normalizeBillingPeriod(sub *model.Subscription) error {
+	if sub.BillingPeriod == "" {
+		sub.BillingPeriod = model.BillingMonthly
+	}
+
+	if !sub.BillingPeriod.IsValid() {
+		return ErrInvalidBillingPeriod
+	}
+
+	return nil
+}
+
+// validateGroupBy ensures that grouping keys are known and not repeated.
+func validateGroupBy(groupBy []string) error {
+	seen := make(map[string]bool, len(groupBy))
+	for _, key := range groupBy {
+		switch key {
+		case model.GroupByMonth, model.GroupByService, model.GroupByUser, model.GroupByCategory:
+		default:
+			return ErrInvalidGroupBy
+		}
+
+		if seen[key] {
+			return ErrInvalidGroupBy
+		}
+		seen[key] = true
+	}
+
+	return nil
 }
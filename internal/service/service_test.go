@@ -2,16 +2,32 @@ package service_test
 
 import (
 	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"testing"
 	"time"
 
+	"subscription-service/internal/events"
 	"subscription-service/internal/model"
+	"subscription-service/internal/repository"
 	"subscription-service/internal/service"
+	"subscription-service/internal/webhook"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // MockRepository is a mock implementation of the SubscriptionRepository interface.
@@ -27,6 +43,11 @@ func (m *MockRepository) Create(ctx context.Context, sub *model.Subscription) er
 	return args.Error(0)
 }
 
+func (m *MockRepository) CreateBatch(ctx context.Context, subs []*model.Subscription) error {
+	args := m.Called(ctx, subs)
+	return args.Error(0)
+}
+
 func (m *MockRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
 	args := m.Called(ctx, id)
 	// Приводим первый аргумент к нужному типу, если он не nil
@@ -36,34 +57,420 @@ func (m *MockRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Subs
 	return args.Get(0).(*model.Subscription), args.Error(1)
 }
 
-func (m *MockRepository) Update(ctx context.Context, sub *model.Subscription) error {
-	args := m.Called(ctx, sub)
+func (m *MockRepository) Update(ctx context.Context, sub *model.Subscription, expectedVersion *int) error {
+	args := m.Called(ctx, sub, expectedVersion)
+	return args.Error(0)
+}
+
+func (m *MockRepository) Delete(ctx context.Context, id uuid.UUID, expectedVersion *int) error {
+	args := m.Called(ctx, id, expectedVersion)
+	return args.Error(0)
+}
+
+func (m *MockRepository) UpdateBatch(
+	ctx context.Context,
+	subs []*model.Subscription,
+	expectedVersions []*int,
+	allow func(before *model.Subscription) error,
+	atomic bool,
+) ([]error, error) {
+	args := m.Called(ctx, subs, expectedVersions, allow, atomic)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]error), args.Error(1)
+}
+
+func (m *MockRepository) DeleteBatch(
+	ctx context.Context,
+	ids []uuid.UUID,
+	allow func(before *model.Subscription) error,
+	atomic bool,
+) ([]error, error) {
+	args := m.Called(ctx, ids, allow, atomic)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]error), args.Error(1)
+}
+
+func (m *MockRepository) Restore(ctx context.Context, id uuid.UUID) (*model.Subscription, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Subscription), args.Error(1)
+}
+
+func (m *MockRepository) Purge(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	args := m.Called(ctx, deletedBefore)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRepository) List(ctx context.Context, filter model.SubscriptionFilter, page model.Page) (*model.SubscriptionPage, error) {
+	args := m.Called(ctx, filter, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SubscriptionPage), args.Error(1)
+}
+
+func (m *MockRepository) Export(ctx context.Context, filter model.SubscriptionFilter, fn func(sub *model.Subscription) error) error {
+	args := m.Called(ctx, filter, fn)
+	return args.Error(0)
+}
+
+func (m *MockRepository) SchedulePrice(ctx context.Context, change *model.PriceChange) error {
+	args := m.Called(ctx, change)
+	return args.Error(0)
+}
+
+func (m *MockRepository) ListPrices(ctx context.Context, subscriptionID uuid.UUID) ([]*model.PriceChange, error) {
+	args := m.Called(ctx, subscriptionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.PriceChange), args.Error(1)
+}
+
+func (m *MockRepository) PriceHistories(ctx context.Context, subscriptionIDs []uuid.UUID) (map[uuid.UUID][]*model.PriceChange, error) {
+	args := m.Called(ctx, subscriptionIDs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[uuid.UUID][]*model.PriceChange), args.Error(1)
+}
+
+func (m *MockRepository) ExistingKeys(ctx context.Context, keys []model.SubscriptionKey) (map[model.SubscriptionKey]bool, error) {
+	args := m.Called(ctx, keys)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[model.SubscriptionKey]bool), args.Error(1)
+}
+
+func (m *MockRepository) FindOverlaps(ctx context.Context, subs []*model.Subscription) ([]uuid.UUID, []bool, error) {
+	args := m.Called(ctx, subs)
+	if fn, ok := args.Get(0).(func([]*model.Subscription) ([]uuid.UUID, []bool)); ok {
+		conflicts, allowed := fn(subs)
+		return conflicts, allowed, args.Error(1)
+	}
+	if args.Get(0) == nil {
+		return nil, nil, args.Error(2)
+	}
+	return args.Get(0).([]uuid.UUID), args.Get(1).([]bool), args.Error(2)
+}
+
+// expectNoOverlaps makes every overlap lookup report that no stored subscription conflicts.
+func expectNoOverlaps(m *MockRepository) {
+	m.On("FindOverlaps", mock.Anything, mock.Anything).Return(func(subs []*model.Subscription) ([]uuid.UUID, []bool) {
+		return make([]uuid.UUID, len(subs)), make([]bool, len(subs))
+	}, nil)
+}
+
+func (m *MockRepository) Owner(ctx context.Context, id uuid.UUID) (uuid.UUID, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(uuid.UUID), args.Error(1)
+}
+
+func (m *MockRepository) AggregateCost(ctx context.Context, q model.CostQuery) ([]model.CostBucket, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]model.CostBucket), args.Error(1)
+}
+
+// MockAuditRepository is a mock implementation of the AuditRepository interface.
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) List(ctx context.Context, q model.AuditQuery) ([]*model.SubscriptionEvent, error) {
+	args := m.Called(ctx, q)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.SubscriptionEvent), args.Error(1)
+}
+
+// MockAPIKeyRepository is a mock implementation of the APIKeyRepository interface.
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *model.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) List(ctx context.Context) ([]*model.APIKey, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) MarkUsed(ctx context.Context, keyHash string) (*model.APIKey, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.APIKey), args.Error(1)
+}
+
+// MockRateRepository is a mock implementation of the CurrencyRateRepository interface.
+type MockRateRepository struct {
+	mock.Mock
+}
+
+func (m *MockRateRepository) Upsert(ctx context.Context, rate *model.CurrencyRate) error {
+	args := m.Called(ctx, rate)
+	return args.Error(0)
+}
+
+func (m *MockRateRepository) List(ctx context.Context, baseCurrency, quoteCurrency *string) ([]*model.CurrencyRate, error) {
+	args := m.Called(ctx, baseCurrency, quoteCurrency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.CurrencyRate), args.Error(1)
+}
+
+func (m *MockRateRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// MockCatalogRepository is a mock implementation of the CatalogRepository interface.
+type MockCatalogRepository struct {
+	mock.Mock
+}
+
+func (m *MockCatalogRepository) Create(ctx context.Context, svc *model.Service) error {
+	args := m.Called(ctx, svc)
+	return args.Error(0)
+}
+
+func (m *MockCatalogRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Service, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Service), args.Error(1)
+}
+
+func (m *MockCatalogRepository) List(ctx context.Context, category *string) ([]*model.Service, error) {
+	args := m.Called(ctx, category)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Service), args.Error(1)
+}
+
+func (m *MockCatalogRepository) Update(ctx context.Context, svc *model.Service) error {
+	args := m.Called(ctx, svc)
+	return args.Error(0)
+}
+
+func (m *MockCatalogRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockCatalogRepository) Resolve(ctx context.Context, names []string) (map[string]*model.Service, error) {
+	args := m.Called(ctx, names)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]*model.Service), args.Error(1)
+}
+
+// MockReminderRepository is a mock implementation of the ReminderRepository interface.
+type MockReminderRepository struct {
+	mock.Mock
+}
+
+func (m *MockReminderRepository) Claim(ctx context.Context, reminder *model.Reminder) (bool, error) {
+	args := m.Called(ctx, reminder)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockReminderRepository) Release(ctx context.Context, reminder *model.Reminder) error {
+	args := m.Called(ctx, reminder)
+	return args.Error(0)
+}
+
+// MockNotifier is a mock implementation of the notify.Notifier interface.
+type MockNotifier struct {
+	mock.Mock
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, reminder *model.Reminder) error {
+	args := m.Called(ctx, reminder)
+	return args.Error(0)
+}
+
+// MockWebhookRepository is a mock implementation of the WebhookRepository interface.
+type MockWebhookRepository struct {
+	mock.Mock
+}
+
+func (m *MockWebhookRepository) Create(ctx context.Context, hook *model.Webhook) error {
+	args := m.Called(ctx, hook)
+	return args.Error(0)
+}
+
+func (m *MockWebhookRepository) GetByID(ctx context.Context, id uuid.UUID) (*model.Webhook, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) List(ctx context.Context) ([]*model.Webhook, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Webhook), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Update(ctx context.Context, hook *model.Webhook) error {
+	args := m.Called(ctx, hook)
 	return args.Error(0)
 }
 
-func (m *MockRepository) Delete(ctx context.Context, id uuid.UUID) error {
+func (m *MockWebhookRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockRepository) List(ctx context.Context, userID *uuid.UUID, serviceName *string, limit, offset int) ([]*model.Subscription, error) {
-	args := m.Called(ctx, userID, serviceName, limit, offset)
+func (m *MockWebhookRepository) ListDeliveries(
+	ctx context.Context,
+	webhookID uuid.UUID,
+	status *string,
+	limit, offset int,
+) ([]*model.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, status, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) Redeliver(ctx context.Context, webhookID, deliveryID uuid.UUID) (*model.WebhookDelivery, error) {
+	args := m.Called(ctx, webhookID, deliveryID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) FanOut(ctx context.Context, limit int) (int64, error) {
+	args := m.Called(ctx, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockWebhookRepository) ClaimDeliveries(ctx context.Context, lease time.Duration, limit int) ([]*model.WebhookDelivery, error) {
+	args := m.Called(ctx, lease, limit)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
-	return args.Get(0).([]*model.Subscription), args.Error(1)
+	return args.Get(0).([]*model.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockWebhookRepository) RenewDelivery(ctx context.Context, delivery *model.WebhookDelivery, lease time.Duration) (bool, error) {
+	args := m.Called(ctx, delivery, lease)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWebhookRepository) CompleteDelivery(ctx context.Context, delivery *model.WebhookDelivery, retryIn time.Duration) (bool, error) {
+	args := m.Called(ctx, delivery, retryIn)
+	return args.Bool(0), args.Error(1)
+}
+
+// MockOutboxRepository is a mock implementation of the OutboxRepository interface.
+type MockOutboxRepository struct {
+	mock.Mock
+}
+
+func (m *MockOutboxRepository) EnqueueExpired(ctx context.Context, since, until time.Time) (int64, error) {
+	args := m.Called(ctx, since, until)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// Relay passes the events returned by the mock to publish in order, stopping at the first failure,
+// as the repository does.
+func (m *MockOutboxRepository) Relay(
+	ctx context.Context,
+	limit int,
+	lease time.Duration,
+	publish func(ctx context.Context, event *model.Event) error,
+) (int, error) {
+	args := m.Called(ctx, limit, lease)
+	if err := args.Error(1); err != nil {
+		return 0, err
+	}
+
+	pending := args.Get(0).([]*model.Event)
+	for i, e := range pending {
+		if err := publish(ctx, e); err != nil {
+			return i, err
+		}
+	}
+	return len(pending), nil
+}
+
+// passThroughTx is a transaction manager that runs units of work without a transaction.
+type passThroughTx struct{}
+
+func (passThroughTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type txCtxKey struct{}
+
+// recordingTx is a transaction manager that marks the context of its units of work and counts them.
+// Like the real one, it joins the enclosing unit of work when called within one.
+type recordingTx struct {
+	units int
+}
+
+func (r *recordingTx) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txCtxKey{}) != nil {
+		return fn(ctx)
+	}
+	r.units++
+	return fn(context.WithValue(ctx, txCtxKey{}, r.units))
+}
+
+// inTx matches contexts of the n-th unit of work of recordingTx.
+func inTx(n int) any {
+	return mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Value(txCtxKey{}) == n
+	})
 }
 
-func (m *MockRepository) AggregateCost(ctx context.Context, userID *uuid.UUID, serviceName *string, from time.Time, to time.Time) (int, error) {
-	args := m.Called(ctx, userID, serviceName, from, to)
-	return args.Int(0), args.Error(1)
+// uncatalogued returns a catalog in which no service name resolves to an entry.
+func uncatalogued() *MockCatalogRepository {
+	m := new(MockCatalogRepository)
+	m.On("Resolve", mock.Anything, mock.Anything).Return(map[string]*model.Service{}, nil).Maybe()
+	return m
 }
 
 // TestCreateSubscription verifies the service-level validation for new subscriptions,
 // ensuring that records are only saved if price and dates are valid.
 func TestCreateSubscription(t *testing.T) {
 	mockRepo := new(MockRepository)
-	svc := service.NewSubscriptionService(mockRepo)
+	svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
 	ctx := context.Background()
 	uid := uuid.New()
 
@@ -76,6 +483,7 @@ func TestCreateSubscription(t *testing.T) {
 		}
 
 		// Setting up Mock: "When Create is called with this sub, return nil (no error)"
+		expectNoOverlaps(mockRepo)
 		mockRepo.On("Create", ctx, sub).Return(nil)
 
 		err := svc.Create(ctx, sub)
@@ -99,6 +507,35 @@ func TestCreateSubscription(t *testing.T) {
 		mockRepo.AssertNotCalled(t, "Create")
 	})
 
+	t.Run("Defaults Billing Period To Monthly", func(t *testing.T) {
+		sub := &model.Subscription{
+			UserID:      uid,
+			ServiceName: "Spotify",
+			Price:       100,
+			StartDate:   time.Now(),
+		}
+
+		expectNoOverlaps(mockRepo)
+		mockRepo.On("Create", ctx, sub).Return(nil)
+
+		err := svc.Create(ctx, sub)
+
+		assert.NoError(t, err)
+		assert.Equal(t, model.BillingMonthly, sub.BillingPeriod)
+	})
+
+	t.Run("Fail Validation Billing Period", func(t *testing.T) {
+		sub := &model.Subscription{
+			Price:         100,
+			BillingPeriod: "daily",
+			StartDate:     time.Now(),
+		}
+
+		err := svc.Create(ctx, sub)
+
+		assert.ErrorIs(t, err, service.ErrInvalidBillingPeriod)
+	})
+
 	t.Run("Fail Validation Dates", func(t *testing.T) {
 		start := time.Now()
 		end := start.Add(-24 * time.Hour) // End date before start
@@ -116,59 +553,1569 @@ func TestCreateSubscription(t *testing.T) {
 	})
 }
 
-// TestListSubscriptions checks the service logic for handling pagination parameters,
-// specifically the assignment of default values for invalid limit and offset inputs.
-func TestListSubscriptions(t *testing.T) {
-	mockRepo := new(MockRepository)
-	svc := service.NewSubscriptionService(mockRepo)
+// TestUnitOfWork verifies that the repository calls of an operation share one transaction,
+// so that a check and the write it guards cannot be interleaved with a concurrent write.
+func TestUnitOfWork(t *testing.T) {
 	ctx := context.Background()
 
-	t.Run("Default Limit/Offset Logic", func(t *testing.T) {
-		// We pass limit=0, offset=-1
-		// The service should turn them into limit=20, offset=0 before calling the repository
+	t.Run("Create", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		tx := &recordingTx{}
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), tx)
+		sub := &model.Subscription{UserID: uuid.New(), ServiceName: "Netflix", Price: 100, StartDate: time.Now()}
 
-		expectedList := []*model.Subscription{}
+		mockRepo.On("FindOverlaps", inTx(1), mock.Anything).Return([]uuid.UUID{uuid.Nil}, []bool{false}, nil)
+		mockRepo.On("Create", inTx(1), sub).Return(nil)
 
-		// Expecting a call with corrected parameters (20, 0)
-		mockRepo.On("List", ctx, (*uuid.UUID)(nil), (*string)(nil), 20, 0).Return(expectedList, nil)
+		assert.NoError(t, svc.Create(ctx, sub))
+		assert.Equal(t, 1, tx.units)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Patch", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		tx := &recordingTx{}
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), tx)
+		id := uuid.New()
+		price := 1200
+
+		mockRepo.On("GetByID", inTx(1), id).Return(&model.Subscription{
+			ID: id, ServiceName: "Netflix", Price: 1000, Currency: "RUB",
+			BillingPeriod: model.BillingMonthly, StartDate: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		}, nil)
+		mockRepo.On("FindOverlaps", inTx(1), mock.Anything).Return([]uuid.UUID{uuid.Nil}, []bool{false}, nil)
+		mockRepo.On("Update", inTx(1), mock.Anything, mock.Anything).Return(nil)
 
-		res, err := svc.List(ctx, nil, nil, 0, -1)
+		_, err := svc.Patch(ctx, id, &model.SubscriptionPatch{Price: &price}, nil)
 
 		assert.NoError(t, err)
-		assert.Equal(t, expectedList, res)
+		assert.Equal(t, 1, tx.units)
 		mockRepo.AssertExpectations(t)
 	})
-}
-
-// TestAggregate ensures the cost calculation logic correctly handles date ranges
-// and prevents repository calls when the aggregation period is invalid.
-func TestAggregate(t *testing.T) {
-	mockRepo := new(MockRepository)
-	svc := service.NewSubscriptionService(mockRepo)
-	ctx := context.Background()
 
-	t.Run("Success", func(t *testing.T) {
-		from := time.Now()
-		to := from.Add(24 * time.Hour)
+	t.Run("Failed Check Aborts Unit", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		tx := &recordingTx{}
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), tx)
+		sub := &model.Subscription{UserID: uuid.New(), ServiceName: "Netflix", Price: 100, StartDate: time.Now()}
 
-		// Mok must return 500 rubles
-		mockRepo.On("AggregateCost", ctx, (*uuid.UUID)(nil), (*string)(nil), from, to).Return(500, nil)
+		mockRepo.On("FindOverlaps", inTx(1), mock.Anything).Return([]uuid.UUID{uuid.New()}, []bool{false}, nil)
 
-		total, err := svc.Aggregate(ctx, nil, nil, from, to)
+		err := svc.Create(ctx, sub)
 
-		assert.NoError(t, err)
-		assert.Equal(t, 500, total)
+		kind, _ := service.KindOf(err)
+		assert.Equal(t, service.KindConflict, kind)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+// TestTracedSubscriptionService verifies that service calls run in spans that parent the repository calls
+// and that only unexpected errors mark a span failed.
+func TestTracedSubscriptionService(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	mockRepo := new(MockRepository)
+	svc := service.NewTracedSubscriptionService(
+		service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{}),
+	)
+	ctx := context.Background()
+	found, missing, broken := uuid.New(), uuid.New(), uuid.New()
+
+	inSpan := mock.MatchedBy(func(ctx context.Context) bool {
+		return trace.SpanContextFromContext(ctx).IsValid()
+	})
+	mockRepo.On("GetByID", inSpan, found).Return(&model.Subscription{ID: found}, nil)
+	mockRepo.On("GetByID", inSpan, missing).Return(nil, repository.ErrNotFound)
+	mockRepo.On("GetByID", inSpan, broken).Return(nil, errors.New("connection reset"))
+
+	_, err := svc.Get(ctx, found)
+	require.NoError(t, err)
+	_, err = svc.Get(ctx, missing)
+	require.Error(t, err)
+	_, err = svc.Get(ctx, broken)
+	require.Error(t, err)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	for _, span := range spans {
+		assert.Equal(t, "SubscriptionService.Get", span.Name())
+	}
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Unset, spans[1].Status().Code, "not found is a domain error")
+	assert.Len(t, spans[1].Events(), 1)
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestPatchSubscription verifies that a merge patch is applied to the stored subscription
+// and written back conditionally on the version that was read.
+func TestPatchSubscription(t *testing.T) {
+	ctx := context.Background()
+	id := uuid.New()
+	end := time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)
+
+	stored := func() *model.Subscription {
+		return &model.Subscription{
+			ID:            id,
+			ServiceName:   "Netflix",
+			Price:         1000,
+			Currency:      "RUB",
+			BillingPeriod: model.BillingMonthly,
+			StartDate:     time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			EndDate:       &end,
+			Version:       3,
+		}
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		price := 1200
+		patch := &model.SubscriptionPatch{Price: &price, ClearEndDate: true}
+		version := 3
+
+		mockRepo.On("GetByID", ctx, id).Return(stored(), nil)
+		expectNoOverlaps(mockRepo)
+		mockRepo.On("Update", ctx, mock.MatchedBy(func(sub *model.Subscription) bool {
+			return sub.Price == 1200 && sub.EndDate == nil && sub.ServiceName == "Netflix"
+		}), &version).Return(nil)
+
+		sub, err := svc.Patch(ctx, id, patch, &version)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1200, sub.Price)
+		assert.Nil(t, sub.EndDate)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Stale If-Match Version", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+		version := 2
+
+		mockRepo.On("GetByID", ctx, id).Return(stored(), nil)
+
+		_, err := svc.Patch(ctx, id, &model.SubscriptionPatch{}, &version)
+
+		assert.ErrorIs(t, err, repository.ErrVersionConflict)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Validation After Merge", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+		start := "01-2026"
+
+		mockRepo.On("GetByID", ctx, id).Return(stored(), nil)
+
+		_, err := svc.Patch(ctx, id, &model.SubscriptionPatch{StartDate: &start}, nil)
+
+		assert.ErrorIs(t, err, service.ErrEndBeforeStart)
+		mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestListSubscriptions checks the service logic for handling pagination parameters,
+// specifically the assignment of default values for invalid limit and offset inputs.
+func TestListSubscriptions(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+	ctx := context.Background()
+
+	t.Run("Default Limit/Offset Logic", func(t *testing.T) {
+		// We pass limit=0, offset=-1
+		// The service should turn them into limit=20, offset=0 before calling the repository
+
+		expectedPage := &model.SubscriptionPage{}
+
+		// Expecting a call with corrected parameters (20, 0)
+		mockRepo.On("List", ctx, model.SubscriptionFilter{Sort: model.DefaultSort}, model.Page{Limit: 20, Offset: 0}).Return(expectedPage, nil)
+
+		res, err := svc.List(ctx, model.SubscriptionFilter{}, model.Page{Limit: 0, Offset: -1})
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedPage, res)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Cursor Ignores Offset", func(t *testing.T) {
+		filter := model.SubscriptionFilter{Sort: model.Sort{Field: model.SortPrice}}
+		after := &model.Cursor{Sort: "price", Value: "500", ID: uuid.New()}
+		expectedPage := &model.SubscriptionPage{}
+
+		mockRepo.On("List", ctx, filter, model.Page{Limit: 5, After: after}).Return(expectedPage, nil)
+
+		res, err := svc.List(ctx, filter, model.Page{Limit: 5, Offset: 40, After: after})
+
+		assert.NoError(t, err)
+		assert.Equal(t, expectedPage, res)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Filters", func(t *testing.T) {
+		minPrice, maxPrice := 500, 100
+		from, to := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		cursor := &model.Cursor{Sort: "-created_at", Value: "2025-01-01T00:00:00", ID: uuid.New()}
+
+		tests := []struct {
+			name   string
+			filter model.SubscriptionFilter
+			page   model.Page
+			err    error
+		}{
+			{"Price Range", model.SubscriptionFilter{MinPrice: &minPrice, MaxPrice: &maxPrice}, model.Page{}, service.ErrInvalidFilter},
+			{"Start Range", model.SubscriptionFilter{StartFrom: &from, StartTo: &to}, model.Page{}, service.ErrInvalidFilter},
+			{"Unknown Sort", model.SubscriptionFilter{Sort: model.Sort{Field: "user_id"}}, model.Page{}, model.ErrInvalidSort},
+			{"Cursor Of Other Sort", model.SubscriptionFilter{Sort: model.Sort{Field: model.SortPrice}}, model.Page{After: cursor}, model.ErrInvalidCursor},
+			{"Forged Price Cursor", model.SubscriptionFilter{Sort: model.Sort{Field: model.SortPrice}}, model.Page{After: &model.Cursor{Sort: "price", Value: "1; DROP", ID: uuid.New()}}, model.ErrInvalidCursor},
+			{"Forged Date Cursor", model.SubscriptionFilter{Sort: model.Sort{Field: model.SortStartDate, Desc: true}}, model.Page{After: &model.Cursor{Sort: "-start_date", Value: "2025-13-01", ID: uuid.New()}}, model.ErrInvalidCursor},
+			{"Forged Created Cursor", model.SubscriptionFilter{}, model.Page{After: &model.Cursor{Sort: "-created_at", Value: "yesterday", ID: uuid.New()}}, model.ErrInvalidCursor},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				_, err := svc.List(ctx, tt.filter, tt.page)
+				assert.ErrorIs(t, err, tt.err)
+			})
+		}
+	})
+}
+
+// TestPurgeSubscriptions checks that the retention window is converted into a cutoff time
+// and that negative windows are rejected before reaching the repository.
+func TestPurgeSubscriptions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Cutoff From Days", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		expected := time.Now().AddDate(0, 0, -30)
+		mockRepo.On("Purge", ctx, mock.MatchedBy(func(cutoff time.Time) bool {
+			return cutoff.Sub(expected).Abs() < time.Minute
+		})).Return(int64(2), nil)
+
+		purged, err := svc.Purge(ctx, 30)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), purged)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Negative Days", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		_, err := svc.Purge(ctx, -1)
+
+		assert.ErrorIs(t, err, service.ErrInvalidPurge)
+		mockRepo.AssertNotCalled(t, "Purge", mock.Anything, mock.Anything)
+	})
+}
+
+// TestSchedulePrice checks that price changes can be scheduled for the current or a future month
+// but never rewrite months that were already charged.
+func TestSchedulePrice(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Current Month", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		change := &model.PriceChange{SubscriptionID: uuid.New(), Price: 900, EffectiveFrom: currentMonth}
+		mockRepo.On("SchedulePrice", ctx, change).Return(nil)
+
+		assert.NoError(t, svc.SchedulePrice(ctx, change))
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Past Month", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		change := &model.PriceChange{SubscriptionID: uuid.New(), Price: 900, EffectiveFrom: currentMonth.AddDate(0, -1, 0)}
+
+		assert.ErrorIs(t, svc.SchedulePrice(ctx, change), service.ErrPastPriceChange)
+		mockRepo.AssertNotCalled(t, "SchedulePrice", mock.Anything, mock.Anything)
+	})
+}
+
+// TestAggregate ensures the cost calculation logic correctly handles date ranges
+// and prevents repository calls when the aggregation period is invalid.
+func TestAggregate(t *testing.T) {
+	mockRepo := new(MockRepository)
+	svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+	ctx := context.Background()
+
+	t.Run("Success", func(t *testing.T) {
+		from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+		q := model.CostQuery{From: from, To: to}
+
+		// Mok must return 500 rubles
+		mockRepo.On("AggregateCost", ctx, q).Return([]model.CostBucket{
+			{Period: &from, Currency: "RUB", Total: 300},
+			{Period: &to, Currency: "RUB", Total: 200},
+		}, nil)
+
+		summary, err := svc.Aggregate(ctx, q)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 500, summary.Total)
+		assert.Equal(t, "RUB", summary.Currency)
+		assert.Nil(t, summary.Buckets)
 	})
 
 	t.Run("Invalid Period", func(t *testing.T) {
 		from := time.Now()
 		to := from.Add(-24 * time.Hour) // 'to' before 'from'
 
-		total, err := svc.Aggregate(ctx, nil, nil, from, to)
+		summary, err := svc.Aggregate(ctx, model.CostQuery{From: from, To: to})
 
 		assert.ErrorIs(t, err, service.ErrInvalidPeriod)
-		assert.Equal(t, 0, total)
+		assert.Nil(t, summary)
 		// Make sure that the request is not sent to the database
 		mockRepo.AssertNotCalled(t, "AggregateCost")
 	})
 }
+
+// TestAggregateGrouped checks validation of grouping keys, merging of per-month buckets
+// and that the grand total is the sum of the returned buckets.
+func TestAggregateGrouped(t *testing.T) {
+	ctx := context.Background()
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Success", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		netflix := "Netflix"
+		spotify := "Spotify"
+		q := model.CostQuery{From: from, To: to, Amortize: true, GroupBy: []string{model.GroupByService}}
+
+		// The repository always breaks buckets down by month; the service merges them per service.
+		mockRepo.On("AggregateCost", ctx, q).Return([]model.CostBucket{
+			{Period: &from, ServiceName: &spotify, Currency: "RUB", Total: 150},
+			{Period: &from, ServiceName: &netflix, Currency: "RUB", Total: 400},
+			{Period: &to, ServiceName: &netflix, Currency: "RUB", Total: 400},
+		}, nil)
+
+		summary, err := svc.Aggregate(ctx, q)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 950, summary.Total)
+		assert.Equal(t, []model.CostBucket{
+			{ServiceName: &netflix, Currency: "RUB", Total: 800},
+			{ServiceName: &spotify, Currency: "RUB", Total: 150},
+		}, summary.Buckets)
+		mockRepo.AssertExpectations(t)
+	})
+
+	tests := []struct {
+		name    string
+		groupBy []string
+	}{
+		{name: "Unknown key", groupBy: []string{"currency"}},
+		{name: "Repeated key", groupBy: []string{model.GroupByMonth, model.GroupByMonth}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockRepository)
+			svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+			summary, err := svc.Aggregate(ctx, model.CostQuery{From: from, To: to, GroupBy: tt.groupBy})
+
+			assert.ErrorIs(t, err, service.ErrInvalidGroupBy)
+			assert.Nil(t, summary)
+			mockRepo.AssertNotCalled(t, "AggregateCost")
+		})
+	}
+}
+
+// TestAggregateCurrencyConversion verifies that buckets in other currencies are converted
+// with the rate effective for their month, using inverse rates when needed.
+func TestAggregateCurrencyConversion(t *testing.T) {
+	ctx := context.Background()
+	jan := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	usd, rub, eur := "USD", "RUB", "EUR"
+
+	raw := []model.CostBucket{
+		{Period: &jan, Currency: "RUB", Total: 40000},
+		{Period: &jan, Currency: "USD", Total: 1000},
+		{Period: &feb, Currency: "USD", Total: 1000},
+	}
+
+	t.Run("Converts with the rate effective per month", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRates := new(MockRateRepository)
+		svc := service.NewSubscriptionService(mockRepo, mockRates, uncatalogued(), passThroughTx{})
+		q := model.CostQuery{From: jan, To: feb, Currency: "rub"}
+
+		mockRepo.On("AggregateCost", ctx, mock.Anything).Return(raw, nil)
+		mockRates.On("List", ctx, &usd, &rub).Return([]*model.CurrencyRate{
+			{BaseCurrency: "USD", QuoteCurrency: "RUB", Rate: 90, EffectiveFrom: jan},
+			{BaseCurrency: "USD", QuoteCurrency: "RUB", Rate: 100, EffectiveFrom: feb},
+		}, nil)
+		mockRates.On("List", ctx, &rub, &usd).Return([]*model.CurrencyRate{}, nil)
+
+		summary, err := svc.Aggregate(ctx, q)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "RUB", summary.Currency)
+		// 400.00 RUB + 10.00 USD * 90 + 10.00 USD * 100
+		assert.Equal(t, 40000+90000+100000, summary.Total)
+	})
+
+	t.Run("Uses inverse rate", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRates := new(MockRateRepository)
+		svc := service.NewSubscriptionService(mockRepo, mockRates, uncatalogued(), passThroughTx{})
+
+		mockRepo.On("AggregateCost", ctx, mock.Anything).Return(raw[:1], nil)
+		mockRates.On("List", ctx, &rub, &eur).Return([]*model.CurrencyRate{}, nil)
+		mockRates.On("List", ctx, &eur, &rub).Return([]*model.CurrencyRate{
+			{BaseCurrency: "EUR", QuoteCurrency: "RUB", Rate: 100, EffectiveFrom: jan},
+		}, nil)
+
+		summary, err := svc.Aggregate(ctx, model.CostQuery{From: jan, To: jan, Currency: "EUR"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 400, summary.Total)
+	})
+
+	t.Run("Missing rate", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRates := new(MockRateRepository)
+		svc := service.NewSubscriptionService(mockRepo, mockRates, uncatalogued(), passThroughTx{})
+
+		mockRepo.On("AggregateCost", ctx, mock.Anything).Return(raw, nil)
+		mockRates.On("List", ctx, &usd, &rub).Return([]*model.CurrencyRate{
+			{BaseCurrency: "USD", QuoteCurrency: "RUB", Rate: 100, EffectiveFrom: feb},
+		}, nil)
+		mockRates.On("List", ctx, &rub, &usd).Return([]*model.CurrencyRate{}, nil)
+
+		_, err := svc.Aggregate(ctx, model.CostQuery{From: jan, To: feb, Currency: "RUB"})
+
+		assert.ErrorIs(t, err, service.ErrMissingRate)
+		assert.Contains(t, err.Error(), "USD/RUB rate effective for 01-2025")
+	})
+
+	t.Run("Currency required for mixed currencies", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		mockRepo.On("AggregateCost", ctx, mock.Anything).Return(raw, nil)
+
+		_, err := svc.Aggregate(ctx, model.CostQuery{From: jan, To: feb})
+
+		assert.ErrorIs(t, err, service.ErrCurrencyRequired)
+	})
+
+	t.Run("Invalid currency", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		_, err := svc.Aggregate(ctx, model.CostQuery{From: jan, To: feb, Currency: "XXXX"})
+
+		assert.ErrorIs(t, err, service.ErrInvalidCurrency)
+		mockRepo.AssertNotCalled(t, "AggregateCost")
+	})
+}
+
+// TestAuditList checks the default pagination of audit queries and the rejection
+// of unknown actions and empty time ranges before the repository is called.
+func TestAuditList(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("History Defaults", func(t *testing.T) {
+		mockRepo := new(MockAuditRepository)
+		svc := service.NewAuditService(mockRepo)
+		id := uuid.New()
+
+		events := []*model.SubscriptionEvent{{SubscriptionID: id, Action: model.EventCreated}}
+		mockRepo.On("List", ctx, model.AuditQuery{SubscriptionID: &id, Limit: 20, Offset: 0}).Return(events, nil)
+
+		res, err := svc.History(ctx, id, 0, -5)
+
+		assert.NoError(t, err)
+		assert.Equal(t, events, res)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Filters", func(t *testing.T) {
+		mockRepo := new(MockAuditRepository)
+		svc := service.NewAuditService(mockRepo)
+
+		action := model.EventAction("renamed")
+		_, err := svc.List(ctx, model.AuditQuery{Action: &action})
+		assert.ErrorIs(t, err, service.ErrInvalidAuditAction)
+
+		from := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+		to := from.Add(-time.Hour)
+		_, err = svc.List(ctx, model.AuditQuery{From: &from, To: &to})
+		assert.ErrorIs(t, err, service.ErrInvalidAuditRange)
+
+		mockRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+	})
+}
+
+// TestAuthorization checks that authenticated non-admin callers are confined to their own subscriptions
+// while admins keep access to all users and to maintenance operations.
+func TestAuthorization(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	userCtx := model.WithIdentity(context.Background(), model.Identity{Subject: owner.String(), UserID: owner})
+	adminCtx := model.WithIdentity(context.Background(), model.Identity{Subject: "ops", Admin: true})
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("Create For Other User", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		sub := &model.Subscription{UserID: other, Price: 100, StartDate: start}
+
+		assert.ErrorIs(t, svc.Create(userCtx, sub), service.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Get Foreign Subscription", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		id := uuid.New()
+		mockRepo.On("GetByID", userCtx, id).Return(&model.Subscription{ID: id, UserID: other}, nil)
+
+		_, err := svc.Get(userCtx, id)
+		assert.ErrorIs(t, err, service.ErrForbidden)
+	})
+
+	t.Run("Delete Foreign Subscription", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		id := uuid.New()
+		mockRepo.On("Owner", userCtx, id).Return(other, nil)
+
+		assert.ErrorIs(t, svc.Delete(userCtx, id, nil), service.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("List Scoped To Caller", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		expected := model.SubscriptionFilter{UserIDs: []uuid.UUID{owner}, Sort: model.DefaultSort}
+		mockRepo.On("List", userCtx, expected, model.Page{Limit: 20}).Return(&model.SubscriptionPage{}, nil)
+
+		_, err := svc.List(userCtx, model.SubscriptionFilter{}, model.Page{})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+
+		_, err = svc.List(userCtx, model.SubscriptionFilter{UserIDs: []uuid.UUID{other}}, model.Page{})
+		assert.ErrorIs(t, err, service.ErrForbidden)
+	})
+
+	t.Run("Aggregate For Other User", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		_, err := svc.Aggregate(userCtx, model.CostQuery{UserID: &other, From: start, To: start.AddDate(0, 11, 0)})
+
+		assert.ErrorIs(t, err, service.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "AggregateCost", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Purge Requires Admin", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		_, err := svc.Purge(userCtx, 30)
+		assert.ErrorIs(t, err, service.ErrForbidden)
+
+		mockRepo.On("Purge", adminCtx, mock.Anything).Return(int64(0), nil)
+		_, err = svc.Purge(adminCtx, 30)
+		assert.NoError(t, err)
+	})
+}
+
+// TestAPIKeys checks that minted keys are stored by hash only, that the secret authenticates the key
+// and that only admins manage keys.
+func TestAPIKeys(t *testing.T) {
+	adminCtx := model.WithIdentity(context.Background(), model.Identity{Subject: "ops", Admin: true})
+
+	t.Run("Mint And Authenticate", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		svc := service.NewAPIKeyService(mockRepo)
+
+		var stored *model.APIKey
+		mockRepo.On("Create", adminCtx, mock.Anything).Run(func(args mock.Arguments) {
+			stored = args.Get(1).(*model.APIKey)
+		}).Return(nil)
+
+		scopes := []model.Scope{model.ScopeSummaryRead, model.ScopeSubscriptionsRead, model.ScopeSummaryRead}
+		key, secret, err := svc.Create(adminCtx, "billing-batch", scopes)
+
+		require.NoError(t, err)
+		assert.Equal(t, []model.Scope{model.ScopeSubscriptionsRead, model.ScopeSummaryRead}, key.Scopes)
+		assert.True(t, strings.HasPrefix(secret, key.Prefix))
+		assert.NotEqual(t, secret, stored.KeyHash)
+		assert.Len(t, stored.KeyHash, 64)
+
+		ctx := context.Background()
+		mockRepo.On("MarkUsed", ctx, stored.KeyHash).Return(stored, nil)
+		mockRepo.On("MarkUsed", ctx, mock.Anything).Return(nil, repository.ErrAPIKeyNotFound)
+
+		found, err := svc.Authenticate(ctx, secret)
+		require.NoError(t, err)
+		assert.Equal(t, stored, found)
+
+		_, err = svc.Authenticate(ctx, secret+"x")
+		assert.ErrorIs(t, err, service.ErrInvalidAPIKey)
+	})
+
+	t.Run("Invalid Scopes", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		svc := service.NewAPIKeyService(mockRepo)
+
+		_, _, err := svc.Create(adminCtx, "batch", nil)
+		assert.ErrorIs(t, err, service.ErrInvalidScope)
+
+		_, _, err = svc.Create(adminCtx, "batch", []model.Scope{"subscriptions:delete"})
+		assert.ErrorIs(t, err, service.ErrInvalidScope)
+
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Admin Only", func(t *testing.T) {
+		mockRepo := new(MockAPIKeyRepository)
+		svc := service.NewAPIKeyService(mockRepo)
+
+		user := uuid.New()
+		userCtx := model.WithIdentity(context.Background(), model.Identity{Subject: user.String(), UserID: user})
+		keyCtx := model.WithIdentity(context.Background(), model.Identity{
+			Subject: "api-key:batch",
+			APIKey:  &model.APIKey{Scopes: []model.Scope{model.ScopeSubscriptionsWrite}},
+		})
+
+		for _, ctx := range []context.Context{userCtx, keyCtx} {
+			_, _, err := svc.Create(ctx, "batch", []model.Scope{model.ScopeSubscriptionsRead})
+			assert.ErrorIs(t, err, service.ErrForbidden)
+			assert.ErrorIs(t, svc.Revoke(ctx, uuid.New()), service.ErrForbidden)
+		}
+	})
+}
+
+// TestErrorKinds checks that service errors carry the kind used to choose the response status,
+// while repository errors stay matchable with errors.Is.
+func TestErrorKinds(t *testing.T) {
+	ctx := context.Background()
+	mockRepo := new(MockRepository)
+	svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+	missing, broken := uuid.New(), uuid.New()
+	mockRepo.On("GetByID", ctx, missing).Return(nil, repository.ErrNotFound)
+	mockRepo.On("GetByID", ctx, broken).Return(nil, errors.New("connection refused"))
+
+	_, err := svc.Get(ctx, missing)
+	kind, ok := service.KindOf(err)
+	assert.True(t, ok)
+	assert.Equal(t, service.KindNotFound, kind)
+	assert.ErrorIs(t, err, repository.ErrNotFound)
+
+	_, err = svc.Get(ctx, broken)
+	_, ok = service.KindOf(err)
+	assert.False(t, ok, "infrastructure errors are not domain errors")
+
+	err = svc.Create(ctx, &model.Subscription{Price: -1})
+	kind, _ = service.KindOf(err)
+	assert.Equal(t, service.KindValidation, kind)
+
+	var derr *service.Error
+	require.ErrorAs(t, err, &derr)
+	assert.Equal(t, "price", derr.Field)
+
+	stale := 0
+	mockRepo.On("GetByID", ctx, mock.Anything).Return(&model.Subscription{Version: 3}, nil)
+	_, err = svc.Patch(ctx, uuid.New(), &model.SubscriptionPatch{}, &stale)
+	kind, _ = service.KindOf(err)
+	assert.Equal(t, service.KindPrecondition, kind)
+	assert.ErrorIs(t, err, repository.ErrVersionConflict)
+}
+
+// TestBatch checks per-item validation and authorization of batch writes and the difference between
+// atomic and partial batches.
+func TestBatch(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	userCtx := model.WithIdentity(context.Background(), model.Identity{Subject: owner.String(), UserID: owner})
+	ctx := context.Background()
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	newBatch := func() []*model.Subscription {
+		return []*model.Subscription{
+			{UserID: owner, ServiceName: "Netflix", Price: 100, StartDate: start},
+			{UserID: owner, ServiceName: "Spotify", Price: -1, StartDate: start},
+			{UserID: other, ServiceName: "Yandex", Price: 200, StartDate: start},
+		}
+	}
+
+	t.Run("Create Atomic Rejects Invalid Batch", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+		expectNoOverlaps(mockRepo)
+
+		results, err := svc.CreateBatch(userCtx, newBatch(), true)
+
+		require.NoError(t, err)
+		assert.NoError(t, results[0])
+		assert.ErrorIs(t, results[1], service.ErrNegativePrice)
+		assert.ErrorIs(t, results[2], service.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Create Partial Saves Valid Items", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		subs := newBatch()
+		expectNoOverlaps(mockRepo)
+		mockRepo.On("CreateBatch", userCtx, []*model.Subscription{subs[0]}).Return(nil)
+
+		results, err := svc.CreateBatch(userCtx, subs, false)
+
+		require.NoError(t, err)
+		assert.NoError(t, results[0])
+		assert.Error(t, results[1])
+		assert.Error(t, results[2])
+		assert.Equal(t, model.DefaultCurrency, subs[0].Currency)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Create Too Large", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		_, err := svc.CreateBatch(ctx, make([]*model.Subscription, model.MaxBatchSize+1), true)
+		assert.ErrorIs(t, err, service.ErrBatchTooLarge)
+	})
+
+	t.Run("Update Maps Repository Errors", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		id, missing := uuid.New(), uuid.New()
+		subs := []*model.Subscription{
+			{ID: id, UserID: owner, ServiceName: "Netflix", Price: 100, StartDate: start},
+			{ID: missing, UserID: owner, ServiceName: "Spotify", Price: 100, StartDate: start},
+			{ID: id, UserID: owner, ServiceName: "Netflix", Price: 200, StartDate: start},
+		}
+		version := 1
+		versions := []*int{&version, nil, nil}
+
+		expectNoOverlaps(mockRepo)
+		mockRepo.On("UpdateBatch", ctx, subs[:2], versions[:2], mock.Anything, false).
+			Return([]error{nil, repository.ErrNotFound}, nil)
+
+		results, err := svc.UpdateBatch(ctx, subs, versions, false)
+
+		require.NoError(t, err)
+		assert.NoError(t, results[0])
+		kind, _ := service.KindOf(results[1])
+		assert.Equal(t, service.KindNotFound, kind)
+		assert.ErrorIs(t, results[2], service.ErrDuplicateID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Delete Checks Owner Of Stored Subscription", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		ids := []uuid.UUID{uuid.New()}
+		mockRepo.On("DeleteBatch", userCtx, ids, mock.Anything, true).Run(func(args mock.Arguments) {
+			allow := args.Get(2).(func(*model.Subscription) error)
+			assert.NoError(t, allow(&model.Subscription{UserID: owner}))
+			assert.ErrorIs(t, allow(&model.Subscription{UserID: other}), service.ErrForbidden)
+		}).Return([]error{nil}, nil)
+
+		results, err := svc.DeleteBatch(userCtx, ids, true)
+
+		require.NoError(t, err)
+		assert.NoError(t, results[0])
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// TestImport checks that imported rows are validated, de-duplicated against stored subscriptions and
+// earlier rows, and only written outside dry-run mode.
+func TestImport(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	newRows := func() []*model.Subscription {
+		return []*model.Subscription{
+			{UserID: userID, ServiceName: "Netflix", Price: 500, StartDate: start},
+			{UserID: userID, ServiceName: "Spotify", Price: 300, StartDate: start},
+			{UserID: userID, ServiceName: "Yandex", Price: 100, StartDate: start, Currency: "XX"},
+			{UserID: userID, ServiceName: "Netflix", Price: 700, StartDate: start},
+		}
+	}
+	existing := map[model.SubscriptionKey]bool{
+		{UserID: userID, ServiceName: "Spotify", StartDate: start}: true,
+	}
+
+	t.Run("Dry Run", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		rows := newRows()
+		expectNoOverlaps(mockRepo)
+		mockRepo.On("ExistingKeys", ctx, mock.Anything).Return(existing, nil)
+
+		results, err := svc.Import(ctx, rows, true)
+
+		require.NoError(t, err)
+		assert.NoError(t, results[0])
+		assert.ErrorIs(t, results[1], service.ErrDuplicate)
+		assert.ErrorIs(t, results[2], service.ErrInvalidCurrency)
+		assert.ErrorIs(t, results[3], service.ErrDuplicate, "rows are de-duplicated within the file")
+		mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		rows := newRows()
+		expectNoOverlaps(mockRepo)
+		mockRepo.On("ExistingKeys", ctx, mock.Anything).Return(map[model.SubscriptionKey]bool{}, nil)
+		mockRepo.On("CreateBatch", ctx, []*model.Subscription{rows[0], rows[1]}).Return(nil)
+
+		results, err := svc.Import(ctx, rows, false)
+
+		require.NoError(t, err)
+		assert.NoError(t, results[1])
+		kind, _ := service.KindOf(results[3])
+		assert.Equal(t, service.KindConflict, kind)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Too Many Rows", func(t *testing.T) {
+		svc := service.NewSubscriptionService(new(MockRepository), new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		_, err := svc.Import(ctx, make([]*model.Subscription, model.MaxImportRows+1), true)
+		assert.ErrorIs(t, err, service.ErrImportTooLarge)
+	})
+}
+
+// TestExport checks that exports are scoped and validated like List before any row is read.
+func TestExport(t *testing.T) {
+	owner, other := uuid.New(), uuid.New()
+	userCtx := model.WithIdentity(context.Background(), model.Identity{Subject: owner.String(), UserID: owner})
+	noop := func(*model.Subscription) error { return nil }
+
+	t.Run("Scoped To Caller", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		expected := model.SubscriptionFilter{UserIDs: []uuid.UUID{owner}, Sort: model.DefaultSort}
+		mockRepo.On("Export", userCtx, expected, mock.Anything).Return(nil)
+
+		assert.NoError(t, svc.Export(userCtx, model.SubscriptionFilter{}, noop))
+		mockRepo.AssertExpectations(t)
+
+		err := svc.Export(userCtx, model.SubscriptionFilter{UserIDs: []uuid.UUID{other}}, noop)
+		assert.ErrorIs(t, err, service.ErrForbidden)
+	})
+
+	t.Run("Invalid Filter", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		minPrice, maxPrice := 500, 100
+		err := svc.Export(context.Background(), model.SubscriptionFilter{MinPrice: &minPrice, MaxPrice: &maxPrice}, noop)
+
+		assert.ErrorIs(t, err, service.ErrInvalidFilter)
+		mockRepo.AssertNotCalled(t, "Export", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestOverlap checks that writes overlapping a stored subscription or each other are rejected unless allowed.
+func TestOverlap(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	allow := true
+
+	t.Run("Create Conflicts With Stored Subscription", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		conflictingID := uuid.New()
+		sub := &model.Subscription{UserID: userID, ServiceName: "Netflix", Price: 500, StartDate: start}
+		mockRepo.On("FindOverlaps", ctx, []*model.Subscription{sub}).Return([]uuid.UUID{conflictingID}, []bool{false}, nil)
+
+		err := svc.Create(ctx, sub)
+
+		var overlap *service.OverlapError
+		require.ErrorAs(t, err, &overlap)
+		assert.Equal(t, conflictingID, overlap.ConflictingID)
+		kind, _ := service.KindOf(err)
+		assert.Equal(t, service.KindConflict, kind)
+		mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Allow Overlap Skips Check", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		sub := &model.Subscription{UserID: userID, ServiceName: "Netflix", Price: 500, StartDate: start, AllowOverlap: &allow}
+		mockRepo.On("Create", ctx, sub).Return(nil)
+
+		assert.NoError(t, svc.Create(ctx, sub))
+		mockRepo.AssertNotCalled(t, "FindOverlaps", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Constraint Violation Is A Conflict", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		sub := &model.Subscription{UserID: userID, ServiceName: "Netflix", Price: 500, StartDate: start}
+		expectNoOverlaps(mockRepo)
+		mockRepo.On("Create", ctx, sub).Return(repository.ErrOverlap)
+
+		err := svc.Create(ctx, sub)
+
+		kind, _ := service.KindOf(err)
+		assert.Equal(t, service.KindConflict, kind)
+	})
+
+	t.Run("Batch Items Overlapping Each Other", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		subs := []*model.Subscription{
+			{UserID: userID, ServiceName: "Netflix", Price: 500, StartDate: start},
+			{UserID: userID, ServiceName: "netflix", Price: 700, StartDate: start.AddDate(0, 2, 0)},
+			{UserID: userID, ServiceName: "Spotify", Price: 300, StartDate: start},
+		}
+		expectNoOverlaps(mockRepo)
+		mockRepo.On("CreateBatch", ctx, []*model.Subscription{subs[0], subs[2]}).Return(nil)
+
+		results, err := svc.CreateBatch(ctx, subs, false)
+
+		require.NoError(t, err)
+		assert.NoError(t, results[0])
+		assert.NoError(t, results[2])
+		var overlap *service.OverlapError
+		require.ErrorAs(t, results[1], &overlap)
+		assert.Equal(t, subs[0].ID, overlap.ConflictingID)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Stored Override Is Kept", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		subs := []*model.Subscription{
+			{ID: uuid.New(), UserID: userID, ServiceName: "Netflix", Price: 500, StartDate: start},
+			{ID: uuid.New(), UserID: userID, ServiceName: "Netflix", Price: 700, StartDate: start},
+		}
+		mockRepo.On("FindOverlaps", ctx, subs).Return([]uuid.UUID{uuid.Nil, uuid.Nil}, []bool{true, false}, nil)
+		mockRepo.On("UpdateBatch", ctx, subs, mock.Anything, mock.Anything, false).Return([]error{nil, nil}, nil)
+
+		results, err := svc.UpdateBatch(ctx, subs, make([]*int, len(subs)), false)
+
+		require.NoError(t, err)
+		assert.NoError(t, results[0])
+		assert.NoError(t, results[1], "an item whose stored override allows overlaps does not conflict")
+		assert.Nil(t, subs[0].AllowOverlap, "an unset override is left to the update")
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// TestCatalog checks that catalog entries are normalized and admin-only, and that subscriptions are linked
+// to the catalog entry their service name resolves to.
+func TestCatalog(t *testing.T) {
+	admin := model.WithIdentity(context.Background(), model.Identity{Subject: "admin", Admin: true})
+	userID := uuid.New()
+	userCtx := model.WithIdentity(context.Background(), model.Identity{Subject: userID.String(), UserID: userID})
+
+	t.Run("Create Normalizes Entry", func(t *testing.T) {
+		repo := new(MockCatalogRepository)
+		svc := service.NewCatalogService(repo)
+
+		entry := &model.Service{Name: " Netflix ", Aliases: []string{"NETFLIX", " Нетфликс"}, Category: "Video", Currency: "usd"}
+		repo.On("Create", admin, entry).Return(nil)
+
+		require.NoError(t, svc.Create(admin, entry))
+		assert.Equal(t, "Netflix", entry.Name)
+		assert.Equal(t, []string{"нетфликс"}, entry.Aliases)
+		assert.Equal(t, "video", entry.Category)
+		assert.Equal(t, "USD", entry.Currency)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Entries", func(t *testing.T) {
+		svc := service.NewCatalogService(new(MockCatalogRepository))
+		negative := -1
+
+		assert.ErrorIs(t, svc.Create(admin, &model.Service{Name: " N "}), service.ErrInvalidServiceName)
+		assert.ErrorIs(t, svc.Create(admin, &model.Service{Name: "Netflix", Aliases: []string{" x"}}), service.ErrInvalidAlias)
+		assert.ErrorIs(t, svc.Create(admin, &model.Service{Name: "Netflix", DefaultPrice: &negative}), service.ErrNegativeDefault)
+		assert.ErrorIs(t, svc.Create(admin, &model.Service{Name: "Netflix", Currency: "XX"}), service.ErrInvalidCurrency)
+	})
+
+	t.Run("Admin Only", func(t *testing.T) {
+		svc := service.NewCatalogService(new(MockCatalogRepository))
+
+		assert.ErrorIs(t, svc.Create(userCtx, &model.Service{Name: "Netflix"}), service.ErrForbidden)
+		assert.ErrorIs(t, svc.Update(userCtx, &model.Service{ID: uuid.New(), Name: "Netflix"}), service.ErrForbidden)
+		assert.ErrorIs(t, svc.Delete(userCtx, uuid.New()), service.ErrForbidden)
+	})
+
+	t.Run("Name Taken Is A Conflict", func(t *testing.T) {
+		repo := new(MockCatalogRepository)
+		svc := service.NewCatalogService(repo)
+
+		repo.On("Create", admin, mock.Anything).Return(repository.ErrServiceNameTaken)
+
+		kind, _ := service.KindOf(svc.Create(admin, &model.Service{Name: "Netflix"}))
+		assert.Equal(t, service.KindConflict, kind)
+	})
+
+	t.Run("Subscription Linked To Entry", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		catalog := new(MockCatalogRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), catalog, passThroughTx{})
+
+		entry := &model.Service{ID: uuid.New(), Name: "Netflix", Aliases: []string{"нетфликс"}}
+		catalog.On("Resolve", userCtx, []string{" НЕТФЛИКС"}).Return(map[string]*model.Service{
+			"netflix":  entry,
+			"нетфликс": entry,
+		}, nil)
+		expectNoOverlaps(mockRepo)
+		mockRepo.On("Create", userCtx, mock.Anything).Return(nil)
+
+		sub := &model.Subscription{UserID: userID, ServiceName: " НЕТФЛИКС", Price: 500, StartDate: time.Now()}
+		require.NoError(t, svc.Create(userCtx, sub))
+
+		assert.Equal(t, "Netflix", sub.ServiceName)
+		require.NotNil(t, sub.ServiceID)
+		assert.Equal(t, entry.ID, *sub.ServiceID)
+	})
+
+	t.Run("Uncatalogued Subscription Is Unlinked", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		stale := uuid.New()
+		sub := &model.Subscription{ID: uuid.New(), UserID: userID, ServiceName: "Kinopoisk", Price: 300, StartDate: time.Now(), ServiceID: &stale}
+		expectNoOverlaps(mockRepo)
+		mockRepo.On("Owner", userCtx, sub.ID).Return(userID, nil)
+		mockRepo.On("Update", userCtx, sub, (*int)(nil)).Return(nil)
+
+		require.NoError(t, svc.Update(userCtx, sub, nil))
+		assert.Equal(t, "Kinopoisk", sub.ServiceName)
+		assert.Nil(t, sub.ServiceID)
+	})
+
+	t.Run("Category Filter Is Normalized", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		category := " Video"
+		expected := "video"
+		mockRepo.On("List", admin, model.SubscriptionFilter{Category: &expected, Sort: model.DefaultSort}, mock.Anything).
+			Return(&model.SubscriptionPage{}, nil)
+
+		_, err := svc.List(admin, model.SubscriptionFilter{Category: &category}, model.Page{})
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// TestCategorySpend checks that the spend is aggregated per category after currency conversion and that the
+// categories are ordered by spend with their share of the total.
+func TestCategorySpend(t *testing.T) {
+	ctx := context.Background()
+	jan := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	usd, rub := "USD", "RUB"
+	streaming, music, none := "streaming", "music", ""
+
+	t.Run("Shares Of Converted Total", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		mockRates := new(MockRateRepository)
+		svc := service.NewSubscriptionService(mockRepo, mockRates, uncatalogued(), passThroughTx{})
+
+		mockRepo.On("AggregateCost", ctx, mock.MatchedBy(func(q model.CostQuery) bool {
+			return len(q.GroupBy) == 1 && q.GroupBy[0] == model.GroupByCategory
+		})).Return([]model.CostBucket{
+			{Period: &jan, Category: &none, Currency: "RUB", Total: 10000},
+			{Period: &jan, Category: &music, Currency: "RUB", Total: 30000},
+			{Period: &jan, Category: &streaming, Currency: "USD", Total: 300},
+			{Period: &feb, Category: &streaming, Currency: "RUB", Total: 15000},
+		}, nil)
+		mockRates.On("List", ctx, &usd, &rub).Return([]*model.CurrencyRate{
+			{BaseCurrency: "USD", QuoteCurrency: "RUB", Rate: 100, EffectiveFrom: jan},
+		}, nil)
+		mockRates.On("List", ctx, &rub, &usd).Return([]*model.CurrencyRate{}, nil)
+
+		analytics, err := svc.CategorySpend(ctx, model.CostQuery{From: jan, To: feb, Currency: "RUB", GroupBy: []string{model.GroupByUser}})
+
+		require.NoError(t, err)
+		assert.Equal(t, 85000, analytics.Total)
+		assert.Equal(t, "RUB", analytics.Currency)
+		assert.Equal(t, []model.CategorySpend{
+			{Category: "streaming", Total: 45000, Share: 52.94},
+			{Category: "music", Total: 30000, Share: 35.29},
+			{Category: "", Total: 10000, Share: 11.76},
+		}, analytics.Categories)
+
+		resp := model.ToCategoryAnalyticsResponse(analytics)
+		assert.Nil(t, resp.Categories[2].Category, "uncategorised spend has a null category")
+	})
+
+	t.Run("No Spend", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		mockRepo.On("AggregateCost", ctx, mock.Anything).Return([]model.CostBucket{}, nil)
+
+		analytics, err := svc.CategorySpend(ctx, model.CostQuery{From: jan, To: feb})
+
+		require.NoError(t, err)
+		assert.Zero(t, analytics.Total)
+		assert.Empty(t, analytics.Categories)
+	})
+
+	t.Run("Invalid Period", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		_, err := svc.CategorySpend(ctx, model.CostQuery{From: feb, To: jan})
+
+		assert.ErrorIs(t, err, service.ErrInvalidPeriod)
+		mockRepo.AssertNotCalled(t, "AggregateCost", mock.Anything, mock.Anything)
+	})
+}
+
+// exportSubscriptions makes the mocked Export call fn for each of the subscriptions.
+func exportSubscriptions(subs ...*model.Subscription) func(mock.Arguments) {
+	return func(args mock.Arguments) {
+		fn := args.Get(2).(func(*model.Subscription) error)
+		for _, sub := range subs {
+			_ = fn(sub)
+		}
+	}
+}
+
+// TestUpcoming checks that upcoming charges are listed by date and priced according to the price history.
+func TestUpcoming(t *testing.T) {
+	owner := uuid.New()
+	userCtx := model.WithIdentity(context.Background(), model.Identity{Subject: owner.String(), UserID: owner})
+
+	now := time.Now().UTC()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	nextMonth := thisMonth.AddDate(0, 1, 0)
+	ended := thisMonth.AddDate(-1, 0, 0)
+
+	t.Run("Priced By History", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		monthly := &model.Subscription{ID: uuid.New(), UserID: owner, Price: 500, BillingPeriod: model.BillingMonthly, StartDate: thisMonth.AddDate(-1, 0, 0)}
+		weekly := &model.Subscription{ID: uuid.New(), UserID: owner, Price: 100, BillingPeriod: model.BillingWeekly, StartDate: thisMonth}
+		old := &model.Subscription{ID: uuid.New(), UserID: owner, Price: 300, BillingPeriod: model.BillingMonthly, StartDate: ended, EndDate: &ended}
+
+		mockRepo.On("Export", userCtx, mock.MatchedBy(func(f model.SubscriptionFilter) bool {
+			return len(f.UserIDs) == 1 && f.UserIDs[0] == owner
+		}), mock.Anything).Run(exportSubscriptions(monthly, weekly, old)).Return(nil)
+		mockRepo.On("PriceHistories", userCtx, []uuid.UUID{monthly.ID, weekly.ID}).Return(map[uuid.UUID][]*model.PriceChange{
+			monthly.ID: {
+				{Price: 500, EffectiveFrom: monthly.StartDate},
+				{Price: 900, EffectiveFrom: nextMonth},
+			},
+		}, nil)
+
+		upcoming, err := svc.Upcoming(userCtx, owner, 40)
+
+		require.NoError(t, err)
+		assert.Equal(t, upcoming.From.AddDate(0, 0, 40), upcoming.To)
+		require.NotEmpty(t, upcoming.Charges)
+
+		var monthlyCharge *model.UpcomingCharge
+		for i, c := range upcoming.Charges {
+			assert.False(t, c.Date.Before(upcoming.From) || c.Date.After(upcoming.To))
+			assert.NotEqual(t, old, c.Subscription, "ended subscriptions are not charged")
+			if i > 0 {
+				assert.False(t, c.Date.Before(upcoming.Charges[i-1].Date), "charges are ordered by date")
+			}
+			if c.Subscription == weekly {
+				assert.Equal(t, 100, c.Amount)
+			}
+			if c.Subscription == monthly && c.Date.Equal(nextMonth) {
+				monthlyCharge = &upcoming.Charges[i]
+			}
+		}
+		require.NotNil(t, monthlyCharge)
+		assert.Equal(t, 900, monthlyCharge.Amount, "the scheduled price applies from its month")
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		mockRepo := new(MockRepository)
+		svc := service.NewSubscriptionService(mockRepo, new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		_, err := svc.Upcoming(userCtx, uuid.New(), 30)
+
+		assert.ErrorIs(t, err, service.ErrForbidden)
+		mockRepo.AssertNotCalled(t, "Export", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invalid Window", func(t *testing.T) {
+		svc := service.NewSubscriptionService(new(MockRepository), new(MockRateRepository), uncatalogued(), passThroughTx{})
+
+		for _, days := range []int{0, service.MaxUpcomingDays + 1} {
+			_, err := svc.Upcoming(userCtx, owner, days)
+			assert.ErrorIs(t, err, service.ErrInvalidWithin)
+		}
+	})
+}
+
+// TestReminders checks which reminders are due and that each is delivered once.
+func TestReminders(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 2, 26, 9, 30, 0, 0, time.UTC)
+	jan := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	renewing := &model.Subscription{ID: uuid.New(), Price: 500, BillingPeriod: model.BillingMonthly, StartDate: jan}
+	ending := &model.Subscription{ID: uuid.New(), Price: 300, BillingPeriod: model.BillingMonthly, StartDate: jan, EndDate: &feb}
+	later := &model.Subscription{ID: uuid.New(), Price: 900, BillingPeriod: model.BillingYearly, StartDate: feb}
+
+	setup := func() (*MockRepository, *MockReminderRepository, *MockNotifier, service.ReminderService) {
+		mockRepo := new(MockRepository)
+		mockReminders := new(MockReminderRepository)
+		mockNotifier := new(MockNotifier)
+
+		mockRepo.On("Export", ctx, mock.Anything, mock.Anything).Run(exportSubscriptions(renewing, ending, later)).Return(nil)
+		mockRepo.On("PriceHistories", ctx, []uuid.UUID{renewing.ID}).Return(map[uuid.UUID][]*model.PriceChange{}, nil)
+
+		return mockRepo, mockReminders, mockNotifier, service.NewReminderService(mockRepo, mockReminders, mockNotifier, 3)
+	}
+	isCharge := mock.MatchedBy(func(r *model.Reminder) bool {
+		return r.Kind == model.ReminderCharge && r.Subscription == renewing && r.Date.Equal(mar) && r.Amount == 500 && r.DaysLeft == 3
+	})
+	isEnd := mock.MatchedBy(func(r *model.Reminder) bool {
+		return r.Kind == model.ReminderEnd && r.Subscription == ending && r.Date.Equal(mar) && r.DaysLeft == 3
+	})
+
+	t.Run("Sent Once", func(t *testing.T) {
+		_, mockReminders, mockNotifier, svc := setup()
+
+		mockReminders.On("Claim", ctx, isCharge).Return(true, nil)
+		mockReminders.On("Claim", ctx, isEnd).Return(false, nil)
+		mockNotifier.On("Notify", ctx, isCharge).Return(nil)
+
+		sent, err := svc.Dispatch(ctx, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		mockReminders.AssertExpectations(t)
+		mockNotifier.AssertExpectations(t)
+		mockNotifier.AssertNotCalled(t, "Notify", ctx, isEnd)
+	})
+
+	t.Run("Failed Delivery Is Released", func(t *testing.T) {
+		_, mockReminders, mockNotifier, svc := setup()
+
+		mockReminders.On("Claim", ctx, mock.Anything).Return(true, nil)
+		mockNotifier.On("Notify", ctx, isCharge).Return(nil)
+		mockNotifier.On("Notify", ctx, isEnd).Return(errors.New("webhook unavailable"))
+		mockReminders.On("Release", ctx, isEnd).Return(nil)
+
+		sent, err := svc.Dispatch(ctx, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, sent)
+		mockReminders.AssertExpectations(t)
+		mockReminders.AssertNumberOfCalls(t, "Release", 1)
+	})
+}
+
+// TestWebhooks verifies the validation and normalization of webhooks, the generated secret and that only
+// admins manage them.
+func TestWebhooks(t *testing.T) {
+	admin := model.WithIdentity(context.Background(), model.Identity{Subject: "admin", Admin: true})
+	userID := uuid.New()
+	userCtx := model.WithIdentity(context.Background(), model.Identity{Subject: userID.String(), UserID: userID})
+
+	t.Run("Create Generates Secret", func(t *testing.T) {
+		repo := new(MockWebhookRepository)
+		svc := service.NewWebhookService(repo)
+
+		hook := &model.Webhook{
+			URL:    "https://example.com/hooks",
+			Events: []string{model.EventTypeUpdated, model.EventTypeCreated, model.EventTypeUpdated},
+			Active: true,
+		}
+		repo.On("Create", admin, hook).Return(nil)
+
+		require.NoError(t, svc.Create(admin, hook))
+		assert.True(t, strings.HasPrefix(hook.Secret, "whsec_"))
+		assert.Greater(t, len(hook.Secret), 32)
+		assert.Equal(t, []string{model.EventTypeCreated, model.EventTypeUpdated}, hook.Events)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Webhooks", func(t *testing.T) {
+		svc := service.NewWebhookService(new(MockWebhookRepository))
+
+		assert.ErrorIs(t, svc.Create(admin, &model.Webhook{URL: "ftp://example.com"}), service.ErrInvalidWebhookURL)
+		assert.ErrorIs(t, svc.Create(admin, &model.Webhook{URL: "/hooks"}), service.ErrInvalidWebhookURL)
+		assert.ErrorIs(t, svc.Create(admin, &model.Webhook{URL: "https://example.com", Secret: "short"}), service.ErrInvalidWebhookSecret)
+		assert.ErrorIs(t, svc.Create(admin, &model.Webhook{URL: "https://example.com", Events: []string{"subscription.renamed"}}), service.ErrInvalidEventType)
+
+		status := "failed"
+		repo := new(MockWebhookRepository)
+		repo.On("GetByID", admin, mock.Anything).Return(&model.Webhook{}, nil)
+		_, err := service.NewWebhookService(repo).ListDeliveries(admin, uuid.New(), &status, 0, 0)
+		assert.ErrorIs(t, err, service.ErrInvalidDeliveryStatus)
+	})
+
+	t.Run("Admin Only", func(t *testing.T) {
+		repo := new(MockWebhookRepository)
+		svc := service.NewWebhookService(repo)
+
+		assert.ErrorIs(t, svc.Create(userCtx, &model.Webhook{URL: "https://example.com"}), service.ErrForbidden)
+		assert.ErrorIs(t, svc.Delete(userCtx, uuid.New()), service.ErrForbidden)
+
+		_, err := svc.Redeliver(userCtx, uuid.New(), uuid.New())
+		assert.ErrorIs(t, err, service.ErrForbidden)
+		repo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+		repo.AssertNotCalled(t, "Redeliver", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Redeliver Unknown Delivery", func(t *testing.T) {
+		repo := new(MockWebhookRepository)
+		repo.On("Redeliver", admin, mock.Anything, mock.Anything).Return(nil, repository.ErrDeliveryNotFound)
+
+		_, err := service.NewWebhookService(repo).Redeliver(admin, uuid.New(), uuid.New())
+		kind, _ := service.KindOf(err)
+		assert.Equal(t, service.KindNotFound, kind)
+	})
+}
+
+// TestWebhookDispatch verifies signed deliveries to an httptest receiver, retries with exponential backoff and
+// dead-lettering after the last attempt.
+func TestWebhookDispatch(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+	today := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	policy := service.RetryPolicy{MaxAttempts: 3, Backoff: 30 * time.Second, MaxBackoff: time.Hour}
+
+	t.Run("Retry Delay", func(t *testing.T) {
+		assert.Equal(t, 30*time.Second, policy.Delay(1))
+		assert.Equal(t, time.Minute, policy.Delay(2))
+		assert.Equal(t, 4*time.Minute, policy.Delay(4))
+		assert.Equal(t, time.Hour, policy.Delay(20))
+	})
+
+	var failing atomic.Bool
+	var received atomic.Int32
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		if !webhook.Verify("whsec_receiver-secret", timestamp, body, r.Header.Get(webhook.HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if failing.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received.Add(1)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	newDelivery := func(attempts int) *model.WebhookDelivery {
+		return &model.WebhookDelivery{
+			ID:        uuid.New(),
+			WebhookID: uuid.New(),
+			Event:     model.Event{ID: 7, Type: model.EventTypeCreated, Data: []byte(`{}`)},
+			Status:    model.DeliveryPending,
+			Attempts:  attempts,
+			URL:       receiver.URL,
+			Secret:    "whsec_receiver-secret",
+		}
+	}
+	setup := func(deliveries ...*model.WebhookDelivery) (*MockWebhookRepository, service.WebhookDispatcher) {
+		repo := new(MockWebhookRepository)
+		outbox := new(MockOutboxRepository)
+		outbox.On("EnqueueExpired", ctx, today.AddDate(0, 0, -7), today).Return(int64(0), nil)
+		repo.On("FanOut", ctx, 10).Return(int64(len(deliveries)), nil)
+		repo.On("ClaimDeliveries", ctx, time.Minute, 10).Return(deliveries, nil)
+		repo.On("RenewDelivery", ctx, mock.Anything, time.Minute).Return(true, nil).Maybe()
+		return repo, service.NewWebhookDispatcher(repo, outbox, webhook.NewHTTPSender(time.Second), policy, 10, time.Minute)
+	}
+
+	t.Run("Delivered", func(t *testing.T) {
+		failing.Store(false)
+		delivery := newDelivery(0)
+		repo, dispatcher := setup(delivery)
+		repo.On("CompleteDelivery", ctx, delivery, time.Duration(0)).Return(true, nil)
+
+		delivered, err := dispatcher.Dispatch(ctx, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 1, delivered)
+		assert.Equal(t, int32(1), received.Load())
+		assert.Equal(t, model.DeliveryDelivered, delivery.Status)
+		assert.Equal(t, 1, delivery.Attempts)
+		require.NotNil(t, delivery.LastStatus)
+		assert.Equal(t, http.StatusNoContent, *delivery.LastStatus)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Failure Is Retried With Backoff", func(t *testing.T) {
+		failing.Store(true)
+		delivery := newDelivery(1)
+		repo, dispatcher := setup(delivery)
+		repo.On("CompleteDelivery", ctx, delivery, time.Minute).Return(true, nil)
+
+		delivered, err := dispatcher.Dispatch(ctx, now)
+
+		require.NoError(t, err)
+		assert.Zero(t, delivered)
+		assert.Equal(t, model.DeliveryPending, delivery.Status)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Contains(t, delivery.LastError, "503")
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Dead Lettered After Max Attempts", func(t *testing.T) {
+		failing.Store(true)
+		delivery := newDelivery(2)
+		repo, dispatcher := setup(delivery)
+		repo.On("CompleteDelivery", ctx, delivery, time.Duration(0)).Return(true, nil)
+
+		_, err := dispatcher.Dispatch(ctx, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, model.DeliveryDead, delivery.Status)
+		assert.Equal(t, 3, delivery.Attempts)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("Expired Lease Is Not Attempted", func(t *testing.T) {
+		failing.Store(false)
+		sent := received.Load()
+		delivery := newDelivery(0)
+		repo := new(MockWebhookRepository)
+		outbox := new(MockOutboxRepository)
+		outbox.On("EnqueueExpired", ctx, today.AddDate(0, 0, -7), today).Return(int64(0), nil)
+		repo.On("FanOut", ctx, 10).Return(int64(0), nil)
+		repo.On("ClaimDeliveries", ctx, time.Minute, 10).Return([]*model.WebhookDelivery{delivery}, nil)
+		repo.On("RenewDelivery", ctx, delivery, time.Minute).Return(false, nil)
+		dispatcher := service.NewWebhookDispatcher(repo, outbox, webhook.NewHTTPSender(time.Second), policy, 10, time.Minute)
+
+		delivered, err := dispatcher.Dispatch(ctx, now)
+
+		require.NoError(t, err)
+		assert.Zero(t, delivered)
+		assert.Equal(t, sent, received.Load(), "another dispatcher owns the delivery")
+		assert.Zero(t, delivery.Attempts)
+		repo.AssertNotCalled(t, "CompleteDelivery", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Lease Lost During Attempt", func(t *testing.T) {
+		failing.Store(false)
+		first, second := newDelivery(0), newDelivery(0)
+		repo, dispatcher := setup(first, second)
+		repo.On("CompleteDelivery", ctx, first, time.Duration(0)).Return(false, nil)
+		repo.On("CompleteDelivery", ctx, second, time.Duration(0)).Return(true, nil)
+
+		delivered, err := dispatcher.Dispatch(ctx, now)
+
+		require.NoError(t, err, "a lost lease is skipped, not an error")
+		assert.Equal(t, 2, delivered)
+		repo.AssertExpectations(t)
+	})
+}
+
+// failingPublisher accepts the first events and rejects the following ones.
+type failingPublisher struct {
+	*events.MemoryPublisher
+	accept int
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, event *model.Event) error {
+	if len(p.Events()) >= p.accept {
+		return errors.New("broker unavailable")
+	}
+	return p.MemoryPublisher.Publish(ctx, event)
+}
+
+// TestEventRelay verifies that expiries are enqueued before outbox events are published in order and that
+// publishing stops at the first rejected event.
+func TestEventRelay(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 3, 10, 8, 0, 0, 0, time.UTC)
+	today := time.Date(2025, 3, 10, 0, 0, 0, 0, time.UTC)
+	pending := []*model.Event{
+		{ID: 1, Type: model.EventTypeCreated, SubscriptionID: uuid.New()},
+		{ID: 2, Type: model.EventTypeUpdated, SubscriptionID: uuid.New()},
+		{ID: 3, Type: model.EventTypeDeleted, SubscriptionID: uuid.New()},
+	}
+
+	setup := func() *MockOutboxRepository {
+		outbox := new(MockOutboxRepository)
+		outbox.On("EnqueueExpired", ctx, today.AddDate(0, 0, -7), today).Return(int64(1), nil)
+		outbox.On("Relay", ctx, 50, 10*time.Second).Return(pending, nil)
+		return outbox
+	}
+
+	t.Run("Published In Order", func(t *testing.T) {
+		outbox := setup()
+		publisher := events.NewMemoryPublisher()
+
+		published, err := service.NewEventRelay(outbox, publisher, 50, 10*time.Second).Dispatch(ctx, now)
+
+		require.NoError(t, err)
+		assert.Equal(t, 3, published)
+		assert.Equal(t, pending, publisher.Events())
+		outbox.AssertExpectations(t)
+	})
+
+	t.Run("Stops At Rejected Event", func(t *testing.T) {
+		outbox := setup()
+		publisher := &failingPublisher{MemoryPublisher: events.NewMemoryPublisher(), accept: 1}
+
+		published, err := service.NewEventRelay(outbox, publisher, 50, 10*time.Second).Dispatch(ctx, now)
+
+		assert.EqualError(t, err, "broker unavailable")
+		assert.Equal(t, 1, published)
+		assert.Equal(t, pending[:1], publisher.Events())
+	})
+
+	t.Run("Expiry Failure", func(t *testing.T) {
+		outbox := new(MockOutboxRepository)
+		outbox.On("EnqueueExpired", ctx, mock.Anything, mock.Anything).Return(int64(0), errors.New("db down"))
+
+		_, err := service.NewEventRelay(outbox, events.NewMemoryPublisher(), 50, 10*time.Second).Dispatch(ctx, now)
+
+		assert.Error(t, err)
+		outbox.AssertNotCalled(t, "Relay", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
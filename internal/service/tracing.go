@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+
+	"subscription-service/internal/model"
+
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentationName identifies the spans created by this package.
+const instrumentationName = "subscription-service/internal/service"
+
+// Span attributes holding the ID of the subscription an operation applies to and the size of a batch.
+const (
+	attrSubscriptionID = attribute.Key("subscription.id")
+	attrBatchSize      = attribute.Key("subscription.batch_size")
+)
+
+type tracedSubscriptionService struct {
+	next SubscriptionService
+}
+
+// NewTracedSubscriptionService wraps a subscription service so that every call runs in a span named after the
+// method, such as "SubscriptionService.Aggregate". Repository queries made by the call become its child spans.
+func NewTracedSubscriptionService(next SubscriptionService) SubscriptionService {
+	return &tracedSubscriptionService{next: next}
+}
+
+// startSpan starts the span of a call to the named method.
+func startSpan(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, "SubscriptionService."+method, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends the span of a call that returned err. Domain errors are the caller's fault: they are recorded
+// with their kind but only other errors mark the span failed.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if kind, ok := KindOf(err); ok {
+			span.SetAttributes(semconv.ErrorTypeKey.String(string(kind)))
+		} else {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+func (s *tracedSubscriptionService) Create(ctx context.Context, sub *model.Subscription) (err error) {
+	ctx, span := startSpan(ctx, "Create")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.Create(ctx, sub)
+}
+
+func (s *tracedSubscriptionService) Get(ctx context.Context, id uuid.UUID) (_ *model.Subscription, err error) {
+	ctx, span := startSpan(ctx, "Get", attrSubscriptionID.String(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.Get(ctx, id)
+}
+
+func (s *tracedSubscriptionService) Update(ctx context.Context, sub *model.Subscription, expectedVersion *int) (err error) {
+	ctx, span := startSpan(ctx, "Update", attrSubscriptionID.String(sub.ID.String()))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.Update(ctx, sub, expectedVersion)
+}
+
+func (s *tracedSubscriptionService) Patch(
+	ctx context.Context,
+	id uuid.UUID,
+	patch *model.SubscriptionPatch,
+	expectedVersion *int,
+) (_ *model.Subscription, err error) {
+	ctx, span := startSpan(ctx, "Patch", attrSubscriptionID.String(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.Patch(ctx, id, patch, expectedVersion)
+}
+
+func (s *tracedSubscriptionService) Delete(ctx context.Context, id uuid.UUID, expectedVersion *int) (err error) {
+	ctx, span := startSpan(ctx, "Delete", attrSubscriptionID.String(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.Delete(ctx, id, expectedVersion)
+}
+
+func (s *tracedSubscriptionService) Restore(ctx context.Context, id uuid.UUID) (_ *model.Subscription, err error) {
+	ctx, span := startSpan(ctx, "Restore", attrSubscriptionID.String(id.String()))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.Restore(ctx, id)
+}
+
+func (s *tracedSubscriptionService) Purge(ctx context.Context, olderThanDays int) (_ int64, err error) {
+	ctx, span := startSpan(ctx, "Purge")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.Purge(ctx, olderThanDays)
+}
+
+func (s *tracedSubscriptionService) CreateBatch(
+	ctx context.Context,
+	subs []*model.Subscription,
+	atomic bool,
+) (_ []error, err error) {
+	ctx, span := startSpan(ctx, "CreateBatch", attrBatchSize.Int(len(subs)))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.CreateBatch(ctx, subs, atomic)
+}
+
+func (s *tracedSubscriptionService) UpdateBatch(
+	ctx context.Context,
+	subs []*model.Subscription,
+	expectedVersions []*int,
+	atomic bool,
+) (_ []error, err error) {
+	ctx, span := startSpan(ctx, "UpdateBatch", attrBatchSize.Int(len(subs)))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.UpdateBatch(ctx, subs, expectedVersions, atomic)
+}
+
+func (s *tracedSubscriptionService) DeleteBatch(ctx context.Context, ids []uuid.UUID, atomic bool) (_ []error, err error) {
+	ctx, span := startSpan(ctx, "DeleteBatch", attrBatchSize.Int(len(ids)))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.DeleteBatch(ctx, ids, atomic)
+}
+
+func (s *tracedSubscriptionService) Import(ctx context.Context, subs []*model.Subscription, dryRun bool) (_ []error, err error) {
+	ctx, span := startSpan(ctx, "Import", attrBatchSize.Int(len(subs)))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.Import(ctx, subs, dryRun)
+}
+
+func (s *tracedSubscriptionService) Export(
+	ctx context.Context,
+	filter model.SubscriptionFilter,
+	fn func(sub *model.Subscription) error,
+) (err error) {
+	ctx, span := startSpan(ctx, "Export")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.Export(ctx, filter, fn)
+}
+
+func (s *tracedSubscriptionService) List(
+	ctx context.Context,
+	filter model.SubscriptionFilter,
+	page model.Page,
+) (_ *model.SubscriptionPage, err error) {
+	ctx, span := startSpan(ctx, "List")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.List(ctx, filter, page)
+}
+
+func (s *tracedSubscriptionService) SchedulePrice(ctx context.Context, change *model.PriceChange) (err error) {
+	ctx, span := startSpan(ctx, "SchedulePrice", attrSubscriptionID.String(change.SubscriptionID.String()))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.SchedulePrice(ctx, change)
+}
+
+func (s *tracedSubscriptionService) ListPrices(ctx context.Context, subscriptionID uuid.UUID) (_ []*model.PriceChange, err error) {
+	ctx, span := startSpan(ctx, "ListPrices", attrSubscriptionID.String(subscriptionID.String()))
+	defer func() { endSpan(span, err) }()
+
+	return s.next.ListPrices(ctx, subscriptionID)
+}
+
+func (s *tracedSubscriptionService) Aggregate(ctx context.Context, query model.CostQuery) (_ *model.CostSummary, err error) {
+	ctx, span := startSpan(ctx, "Aggregate")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.Aggregate(ctx, query)
+}
+
+func (s *tracedSubscriptionService) CategorySpend(
+	ctx context.Context,
+	query model.CostQuery,
+) (_ *model.CategoryAnalytics, err error) {
+	ctx, span := startSpan(ctx, "CategorySpend")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.CategorySpend(ctx, query)
+}
+
+func (s *tracedSubscriptionService) Upcoming(ctx context.Context, userID uuid.UUID, days int) (_ *model.Upcoming, err error) {
+	ctx, span := startSpan(ctx, "Upcoming")
+	defer func() { endSpan(span, err) }()
+
+	return s.next.Upcoming(ctx, userID, days)
+}
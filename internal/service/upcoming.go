@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"time"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// MaxUpcomingDays is the longest window Upcoming looks ahead.
+const MaxUpcomingDays = 366
+
+var ErrInvalidWithin = newError(KindValidation, "within", "within must be between 1d and 366d")
+
+// Upcoming returns the charges of a user's live subscriptions dated from today until days days later (inclusive),
+// ordered by date. Charge dates follow the billing period from the start date and stop after the end month;
+// each amount is the price in force in the month of the charge, including scheduled price changes.
+// Non-admin callers may only see their own upcoming charges.
+func (s *subscriptionService) Upcoming(ctx context.Context, userID uuid.UUID, days int) (*model.Upcoming, error) {
+	log.Printf("INFO: service upcoming charges of user %s within %d days", userID, days)
+
+	if err := authorizeUser(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	if days < 1 || days > MaxUpcomingDays {
+		return nil, ErrInvalidWithin
+	}
+
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, days)
+
+	var subs []*model.Subscription
+	filter := model.SubscriptionFilter{UserIDs: []uuid.UUID{userID}, Sort: model.Sort{Field: model.SortStartDate}}
+	err := s.repo.Export(ctx, filter, func(sub *model.Subscription) error {
+		if len(sub.ChargeDates(from, to)) > 0 {
+			subs = append(subs, sub)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("ERROR: read subscriptions of user %s failed: %v", userID, err)
+		return nil, err
+	}
+
+	charges, err := upcomingCharges(ctx, s.repo, subs, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.Upcoming{From: from, To: to, Charges: charges}, nil
+}
+
+// upcomingCharges expands the subscriptions into their charges dated within [from, to], priced according to
+// their price histories, and orders them by date.
+func upcomingCharges(
+	ctx context.Context,
+	repo repository.SubscriptionRepository,
+	subs []*model.Subscription,
+	from, to time.Time,
+) ([]model.UpcomingCharge, error) {
+
+	if len(subs) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]uuid.UUID, len(subs))
+	for i, sub := range subs {
+		ids[i] = sub.ID
+	}
+
+	prices, err := repo.PriceHistories(ctx, ids)
+	if err != nil {
+		log.Printf("ERROR: repository price histories failed: %v", err)
+		return nil, err
+	}
+
+	var charges []model.UpcomingCharge
+	for _, sub := range subs {
+		for _, date := range sub.ChargeDates(from, to) {
+			charges = append(charges, model.UpcomingCharge{
+				Subscription: sub,
+				Date:         date,
+				Amount:       model.PriceAt(prices[sub.ID], date, sub.Price),
+			})
+		}
+	}
+
+	sort.SliceStable(charges, func(i, j int) bool {
+		return charges[i].Date.Before(charges[j].Date)
+	})
+
+	return charges, nil
+}
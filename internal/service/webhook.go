@@ -0,0 +1,218 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"log"
+	"net/url"
+	"slices"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// WebhookService defines the operations for managing webhooks and inspecting and retrying their deliveries.
+type WebhookService interface {
+	Create(ctx context.Context, hook *model.Webhook) error
+	Get(ctx context.Context, id uuid.UUID) (*model.Webhook, error)
+	List(ctx context.Context) ([]*model.Webhook, error)
+	Update(ctx context.Context, hook *model.Webhook) error
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	ListDeliveries(ctx context.Context, webhookID uuid.UUID, status *string, limit, offset int) ([]*model.WebhookDelivery, error)
+	Redeliver(ctx context.Context, webhookID, deliveryID uuid.UUID) (*model.WebhookDelivery, error)
+}
+
+var (
+	ErrInvalidWebhookURL     = newError(KindValidation, "url", "url must be an absolute http or https URL")
+	ErrInvalidWebhookSecret  = newError(KindValidation, "secret", "secret must be at least 16 characters")
+	ErrInvalidEventType      = newError(KindValidation, "events", "events must be subscription.created, subscription.updated, subscription.deleted, subscription.restored, subscription.purged or subscription.expired")
+	ErrInvalidDeliveryStatus = newError(KindValidation, "status", "status must be one of pending, delivered, dead")
+)
+
+// webhookSecretPrefix marks the signing secrets generated by this service.
+const webhookSecretPrefix = "whsec_"
+
+type webhookService struct {
+	repo repository.WebhookRepository
+}
+
+// NewWebhookService creates a new instance of the webhook service with the given repository.
+func NewWebhookService(repo repository.WebhookRepository) WebhookService {
+	return &webhookService{repo: repo}
+}
+
+// Create validates and registers a webhook, generating its signing secret if none is given.
+// Only admins may manage webhooks.
+func (s *webhookService) Create(ctx context.Context, hook *model.Webhook) error {
+	log.Printf("INFO: service create webhook for %s", hook.URL)
+
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	if hook.Secret == "" {
+		raw := make([]byte, 32)
+		if _, err := rand.Read(raw); err != nil {
+			return err
+		}
+		hook.Secret = webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(raw)
+	}
+
+	if err := normalizeWebhook(hook); err != nil {
+		return err
+	}
+
+	if err := s.repo.Create(ctx, hook); err != nil {
+		log.Printf("ERROR: repository create webhook failed: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// Get retrieves a webhook by its ID. Only admins may manage webhooks.
+func (s *webhookService) Get(ctx context.Context, id uuid.UUID) (*model.Webhook, error) {
+	log.Printf("INFO: service get webhook %s", id)
+
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	hook, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return hook, nil
+}
+
+// List returns all webhooks. Only admins may manage webhooks.
+func (s *webhookService) List(ctx context.Context) ([]*model.Webhook, error) {
+	log.Printf("INFO: service list webhooks")
+
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	return s.repo.List(ctx)
+}
+
+// Update validates and replaces a webhook; an empty secret keeps the current one. Only admins may manage webhooks.
+func (s *webhookService) Update(ctx context.Context, hook *model.Webhook) error {
+	log.Printf("INFO: service update webhook %s", hook.ID)
+
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	if err := normalizeWebhook(hook); err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(ctx, hook); err != nil {
+		log.Printf("ERROR: repository update webhook failed: %v", err)
+		return domainError(err)
+	}
+
+	return nil
+}
+
+// Delete removes a webhook and its deliveries. Only admins may manage webhooks.
+func (s *webhookService) Delete(ctx context.Context, id uuid.UUID) error {
+	log.Printf("INFO: service delete webhook %s", id)
+
+	if err := requireAdmin(ctx); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		log.Printf("ERROR: delete webhook failed: %v", err)
+		return domainError(err)
+	}
+
+	return nil
+}
+
+// ListDeliveries returns the deliveries of a webhook, optionally with the given status (dead deliveries form its
+// dead-letter queue), with default values for pagination (limit: 20, offset: 0) if they are not provided or
+// invalid. Only admins may manage webhooks.
+func (s *webhookService) ListDeliveries(
+	ctx context.Context,
+	webhookID uuid.UUID,
+	status *string,
+	limit, offset int,
+) ([]*model.WebhookDelivery, error) {
+
+	log.Printf("INFO: service list deliveries of webhook %s", webhookID)
+
+	if _, err := s.Get(ctx, webhookID); err != nil {
+		return nil, err
+	}
+
+	if status != nil {
+		switch *status {
+		case model.DeliveryPending, model.DeliveryDelivered, model.DeliveryDead:
+		default:
+			return nil, ErrInvalidDeliveryStatus
+		}
+	}
+
+	if limit <= 0 {
+		limit = 20
+	}
+
+	if offset < 0 {
+		offset = 0
+	}
+
+	return s.repo.ListDeliveries(ctx, webhookID, status, limit, offset)
+}
+
+// Redeliver schedules a delivery, typically a dead-lettered one, for a new series of attempts starting
+// right away. Only admins may manage webhooks.
+func (s *webhookService) Redeliver(ctx context.Context, webhookID, deliveryID uuid.UUID) (*model.WebhookDelivery, error) {
+	log.Printf("INFO: service redeliver %s of webhook %s", deliveryID, webhookID)
+
+	if err := requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+
+	delivery, err := s.repo.Redeliver(ctx, webhookID, deliveryID)
+	if err != nil {
+		return nil, domainError(err)
+	}
+
+	return delivery, nil
+}
+
+// normalizeWebhook checks the URL, secret and event filter of a webhook and sorts and de-duplicates the filter.
+// An empty secret is accepted for updates, which keep the stored one.
+func normalizeWebhook(hook *model.Webhook) error {
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return ErrInvalidWebhookURL
+	}
+
+	if hook.Secret != "" && len(hook.Secret) < 16 {
+		return ErrInvalidWebhookSecret
+	}
+
+	for _, e := range hook.Events {
+		switch e {
+		case model.EventTypeCreated, model.EventTypeUpdated, model.EventTypeDeleted,
+			model.EventTypeRestored, model.EventTypePurged, model.EventTypeExpired:
+		default:
+			return ErrInvalidEventType
+		}
+	}
+
+	hook.Events = slices.Clone(hook.Events)
+	slices.Sort(hook.Events)
+	hook.Events = slices.Compact(hook.Events)
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/repository"
+	"subscription-service/internal/webhook"
+)
+
+// WebhookDispatcher delivers the events of the event outbox to webhooks.
+type WebhookDispatcher interface {
+	Dispatch(ctx context.Context, now time.Time) (int, error)
+}
+
+// RetryPolicy controls the attempts of a webhook delivery. A failed attempt is retried after Backoff, doubled
+// for every earlier failed attempt and capped at MaxBackoff; after MaxAttempts failed attempts the delivery
+// is dead-lettered.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+// Delay returns how long to wait after the given number of failed attempts.
+func (p RetryPolicy) Delay(attempts int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < attempts && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxBackoff)
+}
+
+type webhookDispatcher struct {
+	repo      repository.WebhookRepository
+	outbox    repository.OutboxRepository
+	sender    webhook.Sender
+	policy    RetryPolicy
+	batchSize int
+	lease     time.Duration
+}
+
+// NewWebhookDispatcher creates a dispatcher that handles up to batchSize events and deliveries per dispatch,
+// sends them with sender and retries them according to policy. A claimed delivery is not attempted by another
+// dispatcher for lease, which is renewed before each attempt and must exceed the sender's timeout.
+func NewWebhookDispatcher(
+	repo repository.WebhookRepository,
+	outbox repository.OutboxRepository,
+	sender webhook.Sender,
+	policy RetryPolicy,
+	batchSize int,
+	lease time.Duration,
+) WebhookDispatcher {
+	return &webhookDispatcher{repo: repo, outbox: outbox, sender: sender, policy: policy, batchSize: batchSize, lease: lease}
+}
+
+// Dispatch enqueues the expiry events of subscriptions that became inactive by now, fans the outbox events out
+// into deliveries to the subscribed webhooks and attempts the deliveries that are due. It returns the number of
+// deliveries that succeeded. Failed deliveries are rescheduled with exponential backoff and dead-lettered once
+// they run out of attempts. Deliveries whose lease expired while earlier ones were sent are left to the
+// dispatcher that claims them next, so that no delivery is sent twice.
+func (d *webhookDispatcher) Dispatch(ctx context.Context, now time.Time) (int, error) {
+	if err := enqueueExpired(ctx, d.outbox, now); err != nil {
+		return 0, err
+	}
+
+	if _, err := d.repo.FanOut(ctx, d.batchSize); err != nil {
+		return 0, err
+	}
+
+	deliveries, err := d.repo.ClaimDeliveries(ctx, d.lease, d.batchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, delivery := range deliveries {
+		renewed, err := d.repo.RenewDelivery(ctx, delivery, d.lease)
+		if err != nil {
+			return delivered, err
+		}
+		if !renewed {
+			log.Printf("WARN: lease of delivery %s expired before it was attempted", delivery.ID)
+			continue
+		}
+
+		status, err := d.sender.Send(ctx, delivery)
+
+		delivery.Attempts++
+		delivery.LastStatus = nil
+		if status != 0 {
+			delivery.LastStatus = &status
+		}
+
+		var retryIn time.Duration
+		switch {
+		case err == nil:
+			delivery.Status = model.DeliveryDelivered
+			delivery.LastError = ""
+			delivered++
+		case delivery.Attempts >= d.policy.MaxAttempts:
+			delivery.Status = model.DeliveryDead
+			delivery.LastError = err.Error()
+			log.Printf("WARN: delivery %s of event %d to webhook %s dead-lettered after %d attempts: %v",
+				delivery.ID, delivery.Event.ID, delivery.WebhookID, delivery.Attempts, err)
+		default:
+			delivery.LastError = err.Error()
+			retryIn = d.policy.Delay(delivery.Attempts)
+			log.Printf("WARN: delivery %s of event %d to webhook %s failed, retrying in %s: %v",
+				delivery.ID, delivery.Event.ID, delivery.WebhookID, retryIn, err)
+		}
+
+		completed, err := d.repo.CompleteDelivery(ctx, delivery, retryIn)
+		if err != nil {
+			return delivered, err
+		}
+		if !completed {
+			log.Printf("WARN: lease of delivery %s expired while it was attempted, outcome not recorded", delivery.ID)
+		}
+	}
+
+	if len(deliveries) > 0 {
+		log.Printf("INFO: delivered %d of %d webhook deliveries", delivered, len(deliveries))
+	}
+	return delivered, nil
+}
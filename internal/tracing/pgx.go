@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+	"strings"
+
+	"subscription-service/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.39.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// QueryTracer is a pgx tracer creating a client span for every query, batch and copy. The span is named after
+// the repository method that issued it, such as "subscriptionRepo.Create", and carries the SQL text (queries are
+// parameterised, so it holds no values).
+type QueryTracer struct{}
+
+// NewQueryTracer creates a query tracer to be set on the connection configuration of the pool.
+func NewQueryTracer() *QueryTracer {
+	return &QueryTracer{}
+}
+
+// TraceQueryStart implements pgx.QueryTracer.
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	operation := operationName(data.SQL)
+	return start(ctx, operation, semconv.DBOperationName(operation), semconv.DBQueryText(data.SQL))
+}
+
+// TraceQueryEnd implements pgx.QueryTracer.
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	end(ctx, data.Err)
+}
+
+// TraceBatchStart implements pgx.BatchTracer.
+func (t *QueryTracer) TraceBatchStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	return start(ctx, "BATCH", semconv.DBOperationName("BATCH"), semconv.DBOperationBatchSize(data.Batch.Len()))
+}
+
+// TraceBatchQuery implements pgx.BatchTracer. The queries of a batch are recorded as events of its span.
+func (t *QueryTracer) TraceBatchQuery(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchQueryData) {
+	span := trace.SpanFromContext(ctx)
+	if data.Err != nil {
+		span.RecordError(data.Err, trace.WithAttributes(semconv.DBQueryText(data.SQL)))
+		return
+	}
+	span.AddEvent("query", trace.WithAttributes(semconv.DBQueryText(data.SQL)))
+}
+
+// TraceBatchEnd implements pgx.BatchTracer.
+func (t *QueryTracer) TraceBatchEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceBatchEndData) {
+	end(ctx, data.Err)
+}
+
+// TraceCopyFromStart implements pgx.CopyFromTracer.
+func (t *QueryTracer) TraceCopyFromStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	return start(ctx, "COPY", semconv.DBOperationName("COPY"), semconv.DBCollectionName(data.TableName.Sanitize()))
+}
+
+// TraceCopyFromEnd implements pgx.CopyFromTracer.
+func (t *QueryTracer) TraceCopyFromEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	end(ctx, data.Err)
+}
+
+// start starts the span of a database operation, named after the repository method that issued it or, outside of
+// one, after the operation.
+func start(ctx context.Context, operation string, attrs ...attribute.KeyValue) context.Context {
+	name := operation
+	if method := repository.QueryName(ctx); method != "" {
+		name = method
+		attrs = append(attrs, semconv.CodeFunctionName(method))
+	}
+
+	ctx, _ = otel.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(append(attrs, semconv.DBSystemNamePostgreSQL)...),
+	)
+	return ctx
+}
+
+func end(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// operationName returns the first keyword of a statement, such as "SELECT".
+func operationName(sql string) string {
+	keyword, _, _ := strings.Cut(strings.TrimSpace(sql), " ")
+	keyword, _, _ = strings.Cut(keyword, "\n")
+	return strings.ToUpper(keyword)
+}
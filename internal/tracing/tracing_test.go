@@ -0,0 +1,146 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"subscription-service/internal/config"
+	"subscription-service/internal/tracing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// record installs a global tracer provider recording the spans ended during the test.
+func record(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	_, err := tracing.Setup(context.Background(), config.TracingConfig{})
+	require.NoError(t, err)
+	return recorder
+}
+
+// attr returns the value of the attribute key of span.
+func attr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := record(t)
+
+	r := chi.NewRouter()
+	r.Use(tracing.Middleware)
+	r.Get("/subscriptions/{id}", func(w http.ResponseWriter, r *http.Request) {
+		assert.True(t, trace.SpanContextFromContext(r.Context()).IsValid(), "the handler runs in the request span")
+		_, _ = w.Write([]byte("{}"))
+	})
+	r.Get("/subscriptions/summary", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/subscriptions/42", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/subscriptions/summary", nil))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+
+	span := spans[0]
+	assert.Equal(t, "GET /subscriptions/{id}", span.Name())
+	assert.Equal(t, trace.SpanKindServer, span.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	assert.True(t, span.Parent().IsRemote())
+	assert.Equal(t, "/subscriptions/{id}", attr(span, "http.route").AsString())
+	assert.Equal(t, int64(http.StatusOK), attr(span, "http.response.status_code").AsInt64())
+	assert.Equal(t, codes.Unset, span.Status().Code)
+
+	failed := spans[1]
+	assert.Equal(t, "GET /subscriptions/summary", failed.Name())
+	assert.False(t, failed.Parent().IsValid(), "a request without traceparent starts a new trace")
+	assert.Equal(t, codes.Error, failed.Status().Code)
+}
+
+func TestQueryTracer(t *testing.T) {
+	recorder := record(t)
+	tracer := tracing.NewQueryTracer()
+
+	ctx, parent := otel.Tracer("test").Start(context.Background(), "parent")
+	queryCtx := tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "\n\t\tSELECT id FROM subscriptions WHERE id = $1"})
+	tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{})
+	copyCtx := tracer.TraceCopyFromStart(ctx, nil, pgx.TraceCopyFromStartData{TableName: pgx.Identifier{"subscriptions"}})
+	tracer.TraceCopyFromEnd(copyCtx, nil, pgx.TraceCopyFromEndData{Err: errors.New("connection reset")})
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+
+	// Queries issued outside the repository package are named after the operation.
+	query := spans[0]
+	assert.Equal(t, "SELECT", query.Name())
+	assert.Equal(t, trace.SpanKindClient, query.SpanKind())
+	assert.Equal(t, parent.SpanContext().SpanID(), query.Parent().SpanID())
+	assert.Equal(t, "postgresql", attr(query, "db.system.name").AsString())
+	assert.Contains(t, attr(query, "db.query.text").AsString(), "SELECT id FROM subscriptions")
+	assert.Equal(t, codes.Unset, query.Status().Code)
+
+	copied := spans[1]
+	assert.Equal(t, "COPY", copied.Name())
+	assert.Equal(t, `"subscriptions"`, attr(copied, "db.collection.name").AsString())
+	assert.Equal(t, codes.Error, copied.Status().Code)
+	assert.Equal(t, "connection reset", copied.Status().Description)
+}
+
+func TestSetup(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	t.Run("Disabled", func(t *testing.T) {
+		shutdown, err := tracing.Setup(context.Background(), config.TracingConfig{})
+		require.NoError(t, err)
+		assert.NoError(t, shutdown(context.Background()))
+		assert.Contains(t, otel.GetTextMapPropagator().Fields(), "traceparent")
+	})
+
+	t.Run("OTLP", func(t *testing.T) {
+		// The exporter connects on the first export, so no collector is needed.
+		shutdown, err := tracing.Setup(context.Background(), config.TracingConfig{
+			Enabled:     true,
+			Exporter:    "otlp",
+			Endpoint:    "http://127.0.0.1:4318",
+			ServiceName: "subscription-service",
+			SampleRatio: 1,
+		})
+		require.NoError(t, err)
+		assert.IsType(t, &sdktrace.TracerProvider{}, otel.GetTracerProvider())
+		assert.NoError(t, shutdown(context.Background()))
+	})
+
+	t.Run("Unknown Exporter", func(t *testing.T) {
+		_, err := tracing.Setup(context.Background(), config.TracingConfig{Enabled: true, Exporter: "jaeger"})
+		assert.EqualError(t, err, `unknown trace exporter "jaeger"`)
+	})
+}
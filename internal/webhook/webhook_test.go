@@ -0,0 +1,96 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"subscription-service/internal/model"
+	"subscription-service/internal/webhook"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSign checks that signatures depend on the secret, the timestamp and the body.
+func TestSign(t *testing.T) {
+	body := []byte(`{"id":1}`)
+	signature := webhook.Sign("secret-secret-secret", 1700000000, body)
+
+	assert.Regexp(t, "^sha256=[0-9a-f]{64}$", signature)
+	assert.True(t, webhook.Verify("secret-secret-secret", 1700000000, body, signature))
+	assert.False(t, webhook.Verify("other-secret-secret", 1700000000, body, signature))
+	assert.False(t, webhook.Verify("secret-secret-secret", 1700000001, body, signature))
+	assert.False(t, webhook.Verify("secret-secret-secret", 1700000000, []byte(`{"id":2}`), signature))
+}
+
+// TestHTTPSender checks that events are posted as signed JSON and that rejections are reported with their status.
+func TestHTTPSender(t *testing.T) {
+	delivery := &model.WebhookDelivery{
+		ID:     uuid.New(),
+		Secret: "whsec_test-secret-value",
+		Event: model.Event{
+			ID:        42,
+			Type:      model.EventTypeCreated,
+			CreatedAt: time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC),
+			Data:      json.RawMessage(`{"subscription":{"service_name":"Netflix"}}`),
+		},
+	}
+
+	t.Run("Signed Delivery", func(t *testing.T) {
+		var received model.Event
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+
+			timestamp, err := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+			require.NoError(t, err)
+			assert.True(t, webhook.Verify(delivery.Secret, timestamp, body, r.Header.Get(webhook.HeaderSignature)))
+			assert.Equal(t, delivery.ID.String(), r.Header.Get(webhook.HeaderDelivery))
+			assert.Equal(t, model.EventTypeCreated, r.Header.Get(webhook.HeaderEvent))
+			assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+			require.NoError(t, json.Unmarshal(body, &received))
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer receiver.Close()
+
+		delivery.URL = receiver.URL
+		status, err := webhook.NewHTTPSender(time.Second).Send(context.Background(), delivery)
+
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, int64(42), received.ID)
+		assert.JSONEq(t, `{"subscription":{"service_name":"Netflix"}}`, string(received.Data))
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer receiver.Close()
+
+		delivery.URL = receiver.URL
+		status, err := webhook.NewHTTPSender(time.Second).Send(context.Background(), delivery)
+
+		assert.Error(t, err)
+		assert.Equal(t, http.StatusGone, status)
+	})
+
+	t.Run("Unreachable", func(t *testing.T) {
+		receiver := httptest.NewServer(http.NotFoundHandler())
+		receiver.Close()
+
+		delivery.URL = receiver.URL
+		status, err := webhook.NewHTTPSender(time.Second).Send(context.Background(), delivery)
+
+		assert.Error(t, err)
+		assert.Zero(t, status)
+	})
+}
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE subscriptions
+    ADD COLUMN billing_period VARCHAR(16) NOT NULL DEFAULT 'monthly'
+        CHECK (billing_period IN ('monthly', 'quarterly', 'yearly', 'weekly'));
+
+-- +goose Down
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS billing_period;
@@ -0,0 +1,27 @@
+-- +goose Up
+-- Prices become minor units (kopecks, cents) of the subscription currency.
+ALTER TABLE subscriptions
+    ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    ALTER COLUMN price TYPE BIGINT;
+
+UPDATE subscriptions SET price = price * 100;
+
+CREATE TABLE currency_rates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    base_currency CHAR(3) NOT NULL,
+    quote_currency CHAR(3) NOT NULL,
+    rate NUMERIC(20, 10) NOT NULL CHECK (rate > 0),
+    effective_from DATE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CHECK (base_currency <> quote_currency),
+    UNIQUE (base_currency, quote_currency, effective_from)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS currency_rates;
+
+UPDATE subscriptions SET price = price / 100;
+
+ALTER TABLE subscriptions
+    DROP COLUMN IF EXISTS currency,
+    ALTER COLUMN price TYPE INTEGER;
@@ -0,0 +1,6 @@
+-- +goose Up
+ALTER TABLE subscriptions
+    ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+
+-- +goose Down
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS version;
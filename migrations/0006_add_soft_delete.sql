@@ -0,0 +1,12 @@
+-- +goose Up
+ALTER TABLE subscriptions
+    ADD COLUMN deleted_at TIMESTAMP;
+
+CREATE INDEX idx_subscriptions_deleted_at
+    ON subscriptions(deleted_at)
+    WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_subscriptions_deleted_at;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE subscription_events (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    action VARCHAR(16) NOT NULL
+        CHECK (action IN ('created', 'updated', 'deleted', 'restored', 'purged')),
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(128) NOT NULL DEFAULT '',
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_subscription_events_subscription
+    ON subscription_events(subscription_id, created_at);
+
+CREATE INDEX idx_subscription_events_created_at
+    ON subscription_events(created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS subscription_events;
@@ -0,0 +1,16 @@
+-- +goose Up
+CREATE TABLE subscription_prices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    price BIGINT NOT NULL CHECK (price >= 0),
+    effective_from DATE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    UNIQUE (subscription_id, effective_from)
+);
+
+INSERT INTO subscription_prices (subscription_id, price, effective_from)
+SELECT id, price, date_trunc('month', start_date)::date
+FROM subscriptions;
+
+-- +goose Down
+DROP TABLE IF EXISTS subscription_prices;
@@ -0,0 +1,33 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS btree_gist;
+
+ALTER TABLE subscriptions
+    ADD COLUMN allow_overlap BOOLEAN NOT NULL DEFAULT false;
+
+-- Overlaps that already exist are kept: the later subscription is marked as an explicit override.
+UPDATE subscriptions s
+SET allow_overlap = true
+WHERE s.deleted_at IS NULL
+  AND EXISTS (
+    SELECT 1
+    FROM subscriptions o
+    WHERE o.deleted_at IS NULL
+      AND o.user_id = s.user_id
+      AND lower(btrim(o.service_name)) = lower(btrim(s.service_name))
+      AND daterange(o.start_date, o.end_date, '[]') && daterange(s.start_date, s.end_date, '[]')
+      AND (o.created_at, o.id) < (s.created_at, s.id)
+  );
+
+ALTER TABLE subscriptions
+    ADD CONSTRAINT subscriptions_no_overlap
+    EXCLUDE USING gist (
+        user_id WITH =,
+        lower(btrim(service_name)) WITH =,
+        daterange(start_date, end_date, '[]') WITH &&
+    )
+    WHERE (deleted_at IS NULL AND NOT allow_overlap);
+
+-- +goose Down
+ALTER TABLE subscriptions DROP CONSTRAINT IF EXISTS subscriptions_no_overlap;
+
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS allow_overlap;
@@ -0,0 +1,32 @@
+-- +goose Up
+CREATE TABLE services (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    category TEXT,
+    default_price BIGINT CHECK (default_price >= 0),
+    currency CHAR(3) NOT NULL DEFAULT 'RUB',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_services_category ON services(category);
+
+-- Every name a service is known by (the canonical name and its aliases), trimmed and lower-cased,
+-- so that a name resolves to at most one service.
+CREATE TABLE service_aliases (
+    alias TEXT PRIMARY KEY,
+    service_id UUID NOT NULL REFERENCES services(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_service_aliases_service_id ON service_aliases(service_id);
+
+ALTER TABLE subscriptions
+    ADD COLUMN service_id UUID REFERENCES services(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_subscriptions_service_id ON subscriptions(service_id);
+
+-- +goose Down
+ALTER TABLE subscriptions DROP COLUMN IF EXISTS service_id;
+
+DROP TABLE IF EXISTS service_aliases;
+DROP TABLE IF EXISTS services;
@@ -0,0 +1,13 @@
+-- +goose Up
+-- Reminders already emitted by the reminder scheduler, so that each one is delivered once across ticks,
+-- restarts and instances.
+CREATE TABLE sent_reminders (
+    subscription_id UUID NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL CHECK (kind IN ('charge', 'end')),
+    due_date DATE NOT NULL,
+    sent_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (subscription_id, kind, due_date)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS sent_reminders;
@@ -0,0 +1,11 @@
+-- +goose Up
+-- Events are relayed to the message broker independently of the webhook fan-out. Events written before the
+-- relay existed are considered published so that enabling it does not replay the whole history.
+ALTER TABLE event_outbox ADD COLUMN published_at TIMESTAMP;
+UPDATE event_outbox SET published_at = created_at;
+
+CREATE INDEX idx_event_outbox_unpublished ON event_outbox(id) WHERE published_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_event_outbox_unpublished;
+ALTER TABLE event_outbox DROP COLUMN IF EXISTS published_at;
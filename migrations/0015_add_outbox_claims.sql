@@ -0,0 +1,11 @@
+-- +goose Up
+-- Relays claim unpublished events for a lease and publish them outside of any transaction, instead of keeping
+-- the rows locked while the broker is slow. claim_id identifies the relay that holds the claim.
+ALTER TABLE event_outbox
+    ADD COLUMN claim_id UUID,
+    ADD COLUMN claimed_until TIMESTAMP;
+
+-- +goose Down
+ALTER TABLE event_outbox
+    DROP COLUMN IF EXISTS claimed_until,
+    DROP COLUMN IF EXISTS claim_id;
@@ -3,27 +3,47 @@ package tests
 import (
 	"bytes"
 	"context"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"maps"
 	"os"
 
 	"net/http"
 	"net/http/httptest"
 
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"subscription-service/internal/auth"
 	"subscription-service/internal/config"
 	"subscription-service/internal/db"
 	"subscription-service/internal/handler"
+	"subscription-service/internal/metrics"
+	"subscription-service/internal/model"
 	"subscription-service/internal/repository"
 	"subscription-service/internal/service"
+	"subscription-service/internal/tracing"
+	"subscription-service/internal/webhook"
 
 	"github.com/joho/godotenv"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/xuri/excelize/v2"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -89,35 +109,116 @@ func getTestConfig() *config.Config {
 	return cfg
 }
 
-// setupTestServer initializes a test HTTP server with all dependencies.
+// setupTestServer initializes a test HTTP server with all dependencies and authentication disabled.
 // It returns the test server instance and a cleanup function.
 func setupTestServer(t *testing.T) (*httptest.Server, func()) {
+	return setupServer(t, nil)
+}
+
+// setupServer initializes a test HTTP server with all dependencies. When verifier is set, requests
+// must be authenticated with a bearer token or an API key, as in production.
+// It returns the test server instance and a cleanup function.
+func setupServer(t *testing.T, verifier *auth.Verifier) (*httptest.Server, func()) {
 
 	// Load the config with the path RELATIVE to db_test.go
 	cfg := getTestConfig()
 
 	// Connecting to the database
 	ctx := context.Background()
-	database, err := db.Connect(ctx, cfg)
+	queryTracer := metrics.NewQueryTracer()
+	database, err := db.Connect(ctx, cfg, queryTracer, tracing.NewQueryTracer())
 	require.NoError(t, err, "Couldn't connect to the database")
 
 	// Cleaning the table before testing
-	_, err = database.Pool.Exec(ctx, "TRUNCATE subscriptions RESTART IDENTITY CASCADE")
+	_, err = database.Pool.Exec(ctx, "TRUNCATE subscriptions, currency_rates, subscription_events, api_keys, services, webhooks, event_outbox RESTART IDENTITY CASCADE")
 	require.NoError(t, err)
 
 	// Collecting layers
 	repo := repository.NewSubscriptionRepository(database.Pool)
-	svc := service.NewSubscriptionService(repo)
+	rateRepo := repository.NewCurrencyRateRepository(database.Pool)
+	catalogRepo := repository.NewCatalogRepository(database.Pool)
+	txManager := repository.NewTxManager(database.Pool, pgx.TxIsoLevel(cfg.Database.IsolationLevel), cfg.Database.TxRetries)
+	svc := service.NewTracedSubscriptionService(service.NewSubscriptionService(repo, rateRepo, catalogRepo, txManager))
+	keyService := service.NewAPIKeyService(repository.NewAPIKeyRepository(database.Pool))
 	h := handler.NewSubscriptionHandler(svc)
+	rh := handler.NewCurrencyRateHandler(service.NewCurrencyRateService(rateRepo))
+	ah := handler.NewAuditHandler(service.NewAuditService(repository.NewAuditRepository(database.Pool)))
+	kh := handler.NewAPIKeyHandler(keyService)
+	ch := handler.NewCatalogHandler(service.NewCatalogService(catalogRepo))
+	wh := handler.NewWebhookHandler(service.NewWebhookService(repository.NewWebhookRepository(database.Pool)))
+
+	httpMetrics := metrics.NewHTTPMetrics()
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		httpMetrics,
+		queryTracer,
+		metrics.NewPoolCollector(database.Pool),
+		metrics.NewBusinessCollector(repository.NewStatsRepository(database.Pool)),
+	)
 
 	// Router (as in main.go)
 	r := chi.NewRouter()
-	r.Post("/subscriptions", h.Create)
-	r.Get("/subscriptions/{id}", h.Get)
-	r.Put("/subscriptions/{id}", h.Update)
-	r.Delete("/subscriptions/{id}", h.Delete)
-	r.Get("/subscriptions", h.List)
-	r.Get("/subscriptions/summary", h.Summary)
+	r.Use(tracing.Middleware)
+	r.Use(httpMetrics.Middleware)
+	if verifier != nil {
+		r.Use(handler.AuthMiddleware(verifier, keyService))
+	}
+	r.Use(handler.AuditContextMiddleware)
+	r.Method(http.MethodGet, "/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+
+	r.Group(func(r chi.Router) {
+		r.Use(handler.RequireScope(model.ScopeSubscriptionsRead))
+		r.Get("/subscriptions/{id}", h.Get)
+		r.Get("/subscriptions/{id}/prices", h.Prices)
+		r.Get("/subscriptions", h.List)
+		r.Get("/subscriptions/export", h.Export)
+		r.Get("/subscriptions/{id}/history", ah.History)
+		r.Get("/services", ch.List)
+		r.Get("/services/{id}", ch.Get)
+		r.Get("/users/{user_id}/upcoming", h.Upcoming)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(handler.RequireScope(model.ScopeSubscriptionsWrite))
+		r.Post("/subscriptions", h.Create)
+		r.Post("/subscriptions/batch", h.CreateBatch)
+		r.Put("/subscriptions/batch", h.UpdateBatch)
+		r.Post("/subscriptions/batch/delete", h.DeleteBatch)
+		r.Post("/subscriptions/import", h.Import)
+		r.Put("/subscriptions/{id}", h.Update)
+		r.Patch("/subscriptions/{id}", h.Patch)
+		r.Delete("/subscriptions/{id}", h.Delete)
+		r.Post("/subscriptions/{id}/restore", h.Restore)
+		r.Post("/subscriptions/{id}/prices", h.SchedulePrice)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(handler.RequireScope(model.ScopeSummaryRead))
+		r.Get("/subscriptions/summary", h.Summary)
+		r.Get("/analytics/categories", h.Categories)
+	})
+
+	r.Group(func(r chi.Router) {
+		r.Use(handler.DenyAPIKeys)
+		r.Post("/subscriptions/purge", h.Purge)
+		r.Get("/audit", ah.List)
+		r.Post("/currency-rates", rh.Set)
+		r.Get("/currency-rates", rh.List)
+		r.Delete("/currency-rates/{id}", rh.Delete)
+		r.Post("/api-keys", kh.Create)
+		r.Get("/api-keys", kh.List)
+		r.Delete("/api-keys/{id}", kh.Revoke)
+		r.Post("/services", ch.Create)
+		r.Put("/services/{id}", ch.Update)
+		r.Delete("/services/{id}", ch.Delete)
+		r.Post("/webhooks", wh.Create)
+		r.Get("/webhooks", wh.List)
+		r.Get("/webhooks/{id}", wh.Get)
+		r.Put("/webhooks/{id}", wh.Update)
+		r.Delete("/webhooks/{id}", wh.Delete)
+		r.Get("/webhooks/{id}/deliveries", wh.Deliveries)
+		r.Post("/webhooks/{id}/deliveries/{delivery_id}/redeliver", wh.Redeliver)
+	})
 
 	// Starting the test HTTP server
 	ts := httptest.NewServer(r)
@@ -134,6 +235,20 @@ func setupTestServer(t *testing.T) (*httptest.Server, func()) {
 // request sends an HTTP request to the specified URL and returns the response body and status code.
 // It handles JSON payload serialization and sets appropriate headers.
 func request(t *testing.T, url string, method string, payload any) ([]byte, int) {
+	return requestAs(t, "", url, method, payload)
+}
+
+// requestAs works like request but authenticates with the given bearer token when it is not empty.
+func requestAs(t *testing.T, token string, url string, method string, payload any) ([]byte, int) {
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	return requestWithHeader(t, header, url, method, payload)
+}
+
+// requestWithHeader works like request but adds the given headers.
+func requestWithHeader(t *testing.T, header http.Header, url string, method string, payload any) ([]byte, int) {
 	var body io.Reader
 
 	if payload != nil {
@@ -144,6 +259,7 @@ func request(t *testing.T, url string, method string, payload any) ([]byte, int)
 
 	req, err := http.NewRequest(method, url, body)
 	require.NoError(t, err)
+	req.Header = header.Clone()
 	req.Header.Set("Content-Type", "application/json")
 
 	client := &http.Client{}
@@ -197,6 +313,8 @@ func TestSubscriptionLifecycle(t *testing.T) {
 		assert.NotEmpty(t, resp["id"])
 		assert.Equal(t, "Netflix", resp["service_name"])
 		assert.Equal(t, "01-2025", resp["start_date"])
+		assert.Equal(t, "monthly", resp["billing_period"])
+		assert.Equal(t, "RUB", resp["currency"])
 
 		createdID := resp["id"].(string)
 		//Successful subscription creation
@@ -251,7 +369,11 @@ func TestSubscriptionLifecycle(t *testing.T) {
 		}
 		resp, status := postJSON(t, baseURL, badDate)
 		assert.Equal(t, http.StatusBadRequest, status)
-		assert.Contains(t, fmt.Sprint(resp["error"]), "mmYYYY") // Validator error
+		assert.Equal(t, float64(http.StatusBadRequest), resp["status"])
+		assert.Equal(t, []any{map[string]any{
+			"field":   "start_date",
+			"message": "must be a month in MM-YYYY format",
+		}}, resp["errors"]) // Field-level validator details
 
 		// Error: the price is less than 0
 		badPrice := map[string]any{
@@ -300,16 +422,1408 @@ func TestListAndSummary(t *testing.T) {
 
 	t.Run("Summary", func(t *testing.T) {
 		// Amount for user1 for the period 01-2025 to 03-2025
-		// Should be 300 + 200 = 500
+		// Yandex is active 3 months, Google 2 months: 300*3 + 200*2 = 1300
 		u := fmt.Sprintf("%s/summary?user_id=%s&from=01-2025&to=03-2025", baseURL, user1)
 
 		body, status := request(t, u, http.MethodGet, nil)
 		assert.Equal(t, http.StatusOK, status)
 
-		var summary map[string]int
+		var summary struct {
+			Total    int    `json:"total"`
+			Currency string `json:"currency"`
+		}
+		err := json.Unmarshal(body, &summary)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1300, summary.Total)
+		assert.Equal(t, "RUB", summary.Currency)
+	})
+	t.Run("Summary Grouped By Month", func(t *testing.T) {
+		u := fmt.Sprintf("%s/summary?user_id=%s&from=01-2025&to=03-2025&group_by=month", baseURL, user1)
+
+		body, status := request(t, u, http.MethodGet, nil)
+		assert.Equal(t, http.StatusOK, status)
+
+		var summary struct {
+			Total   int `json:"total"`
+			Buckets []struct {
+				Period string `json:"period"`
+				Total  int    `json:"total"`
+			} `json:"buckets"`
+		}
 		err := json.Unmarshal(body, &summary)
 		require.NoError(t, err)
 
-		assert.Equal(t, 500, summary["total"])
+		assert.Equal(t, 1300, summary.Total)
+		require.Len(t, summary.Buckets, 3)
+		assert.Equal(t, "01-2025", summary.Buckets[0].Period)
+		assert.Equal(t, 300, summary.Buckets[0].Total)
+		assert.Equal(t, 500, summary.Buckets[1].Total)
+	})
+
+	t.Run("Summary Invalid Group By", func(t *testing.T) {
+		u := fmt.Sprintf("%s/summary?from=01-2025&to=03-2025&group_by=currency", baseURL)
+
+		_, status := request(t, u, http.MethodGet, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+// TestSummaryCurrencyConversion checks that /subscriptions/summary converts prices
+// with rates managed through /currency-rates and fails clearly when a rate is missing.
+func TestSummaryCurrencyConversion(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	baseURL := ts.URL + "/subscriptions"
+	userID := uuid.New().String()
+
+	create := func(name string, price int, currency string) {
+		payload := map[string]any{
+			"user_id": userID, "service_name": name, "price": price, "currency": currency, "start_date": "01-2025",
+		}
+		_, status := postJSON(t, baseURL, payload)
+		require.Equal(t, http.StatusCreated, status)
+	}
+
+	create("Yandex", 30000, "RUB")
+	create("Netflix", 1000, "USD")
+
+	summaryURL := fmt.Sprintf("%s/summary?user_id=%s&from=01-2025&to=02-2025", baseURL, userID)
+
+	t.Run("Mixed currencies require target", func(t *testing.T) {
+		_, status := request(t, summaryURL, http.MethodGet, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("Missing rate", func(t *testing.T) {
+		resp, status := postJSON(t, ts.URL+"/currency-rates", map[string]any{
+			"base_currency": "USD", "quote_currency": "RUB", "rate": 90, "effective_from": "02-2025",
+		})
+		require.Equal(t, http.StatusCreated, status, resp)
+
+		body, status := request(t, summaryURL+"&currency=RUB", http.MethodGet, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+		assert.Contains(t, string(body), "01-2025")
+	})
+
+	t.Run("Converted total", func(t *testing.T) {
+		_, status := postJSON(t, ts.URL+"/currency-rates", map[string]any{
+			"base_currency": "USD", "quote_currency": "RUB", "rate": 80, "effective_from": "01-2025",
+		})
+		require.Equal(t, http.StatusCreated, status)
+
+		body, status := request(t, summaryURL+"&currency=RUB", http.MethodGet, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		var summary struct {
+			Total    int    `json:"total"`
+			Currency string `json:"currency"`
+		}
+		require.NoError(t, json.Unmarshal(body, &summary))
+
+		// 2 * 300.00 RUB + 10.00 USD * 80 + 10.00 USD * 90
+		assert.Equal(t, 60000+80000+90000, summary.Total)
+		assert.Equal(t, "RUB", summary.Currency)
+	})
+}
+
+// TestPatchAndConcurrency checks PATCH merge semantics, ETag headers and If-Match preconditions.
+func TestPatchAndConcurrency(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	baseURL := ts.URL + "/subscriptions"
+
+	resp, status := postJSON(t, baseURL, map[string]any{
+		"user_id":      uuid.New().String(),
+		"service_name": "Netflix",
+		"price":        1500,
+		"start_date":   "01-2025",
+		"end_date":     "12-2025",
+	})
+	require.Equal(t, http.StatusCreated, status)
+	itemURL := baseURL + "/" + resp["id"].(string)
+
+	send := func(method, ifMatch, body string) *http.Response {
+		req, err := http.NewRequest(method, itemURL, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/merge-patch+json")
+		if ifMatch != "" {
+			req.Header.Set("If-Match", ifMatch)
+		}
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+
+	get := send(http.MethodGet, "", "")
+	require.Equal(t, http.StatusOK, get.StatusCode)
+	etag := get.Header.Get("ETag")
+	require.Equal(t, `"1"`, etag)
+
+	t.Run("Patch clears end_date", func(t *testing.T) {
+		res := send(http.MethodPatch, etag, `{"price": 1700, "end_date": null}`)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, `"2"`, res.Header.Get("ETag"))
+
+		var sub map[string]any
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&sub))
+		assert.Equal(t, float64(1700), sub["price"])
+		assert.Equal(t, "Netflix", sub["service_name"])
+		assert.NotContains(t, sub, "end_date")
+	})
+
+	t.Run("Stale If-Match is rejected", func(t *testing.T) {
+		res := send(http.MethodPatch, etag, `{"price": 1}`)
+		assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+		res = send(http.MethodDelete, etag, "")
+		assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	})
+
+	t.Run("Weak If-Match is rejected", func(t *testing.T) {
+		res := send(http.MethodPatch, `W/"2"`, `{"price": 1}`)
+		assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+		res = send(http.MethodDelete, `W/"2"`, "")
+		assert.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	})
+
+	t.Run("Invalid patch", func(t *testing.T) {
+		res := send(http.MethodPatch, "", `{"user_id": null}`)
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("Delete with current version", func(t *testing.T) {
+		res := send(http.MethodDelete, `"2"`, "")
+		assert.Equal(t, http.StatusNoContent, res.StatusCode)
+	})
+}
+
+// TestTrashRestoreAndPurge checks that deleted subscriptions move to the trash,
+// can be restored through the API and are permanently removed by purge.
+func TestTrashRestoreAndPurge(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	baseURL := ts.URL + "/subscriptions"
+	userID := uuid.New().String()
+
+	resp, status := postJSON(t, baseURL, map[string]any{
+		"user_id":      userID,
+		"service_name": "Spotify",
+		"price":        300,
+		"start_date":   "01-2025",
+	})
+	require.Equal(t, http.StatusCreated, status)
+	id := resp["id"].(string)
+
+	_, status = request(t, baseURL+"/"+id, http.MethodDelete, nil)
+	require.Equal(t, http.StatusNoContent, status)
+
+	listLen := func(query string) int {
+		body, status := request(t, baseURL+"?user_id="+userID+query, http.MethodGet, nil)
+		require.Equal(t, http.StatusOK, status)
+		var list []map[string]any
+		require.NoError(t, json.Unmarshal(body, &list))
+		return len(list)
+	}
+
+	t.Run("Trash listing", func(t *testing.T) {
+		assert.Equal(t, 0, listLen(""))
+		assert.Equal(t, 1, listLen("&deleted=true"))
+
+		_, status := request(t, baseURL+"?deleted=maybe", http.MethodGet, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		body, status := request(t, baseURL+"/"+id+"/restore", http.MethodPost, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		var sub map[string]any
+		require.NoError(t, json.Unmarshal(body, &sub))
+		assert.NotContains(t, sub, "deleted_at")
+		assert.Equal(t, 1, listLen(""))
+
+		_, status = request(t, baseURL+"/"+id+"/restore", http.MethodPost, nil)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		_, status := request(t, baseURL+"/purge?older_than_days=-1", http.MethodPost, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		_, status = request(t, baseURL+"/"+id, http.MethodDelete, nil)
+		require.Equal(t, http.StatusNoContent, status)
+
+		body, status := request(t, baseURL+"/purge?older_than_days=0", http.MethodPost, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		var purged model.PurgeResponse
+		require.NoError(t, json.Unmarshal(body, &purged))
+		assert.Equal(t, int64(1), purged.Purged)
+		assert.Equal(t, 0, listLen("&deleted=true"))
+	})
+}
+
+// TestAuditLog checks that changes made through the API are attributed to the X-Actor header
+// and request ID and are exposed through the history and audit endpoints.
+func TestAuditLog(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	baseURL := ts.URL + "/subscriptions"
+
+	send := func(method, url, body string) *http.Response {
+		req, err := http.NewRequest(method, url, bytes.NewBufferString(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Actor", "support@example.com")
+		req.Header.Set("X-Request-ID", "req-"+method)
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+
+	res := send(http.MethodPost, baseURL, `{"user_id": "`+uuid.New().String()+`", "service_name": "Netflix", "price": 500, "start_date": "01-2025"}`)
+	require.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Equal(t, "req-POST", res.Header.Get("X-Request-ID"))
+
+	var created map[string]any
+	require.NoError(t, json.NewDecoder(res.Body).Decode(&created))
+	id := created["id"].(string)
+
+	res = send(http.MethodPatch, baseURL+"/"+id, `{"price": 700}`)
+	require.Equal(t, http.StatusOK, res.StatusCode)
+
+	t.Run("History", func(t *testing.T) {
+		body, status := request(t, baseURL+"/"+id+"/history", http.MethodGet, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		var events []model.SubscriptionEventResponse
+		require.NoError(t, json.Unmarshal(body, &events))
+		require.Len(t, events, 2)
+
+		assert.Equal(t, "updated", events[0].Action)
+		assert.Equal(t, "support@example.com", events[0].Actor)
+		assert.Equal(t, "req-PATCH", events[0].RequestID)
+		assert.Contains(t, string(events[0].Before), `"price":500`)
+		assert.Contains(t, string(events[0].After), `"price":700`)
+		assert.Equal(t, "created", events[1].Action)
+	})
+
+	t.Run("Audit filters", func(t *testing.T) {
+		body, status := request(t, ts.URL+"/audit?action=created&actor=support@example.com", http.MethodGet, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		var events []model.SubscriptionEventResponse
+		require.NoError(t, json.Unmarshal(body, &events))
+		require.Len(t, events, 1)
+		assert.Equal(t, id, events[0].SubscriptionID.String())
+
+		_, status = request(t, ts.URL+"/audit?action=renamed", http.MethodGet, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+
+		_, status = request(t, ts.URL+"/audit?from=01-2025", http.MethodGet, nil)
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+}
+
+// TestPriceSchedule checks that future price changes can be scheduled and listed
+// while changes for past months are rejected.
+func TestPriceSchedule(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	baseURL := ts.URL + "/subscriptions"
+
+	resp, status := postJSON(t, baseURL, map[string]any{
+		"user_id":      uuid.New().String(),
+		"service_name": "Netflix",
+		"price":        500,
+		"start_date":   "01-2025",
+	})
+	require.Equal(t, http.StatusCreated, status)
+	pricesURL := baseURL + "/" + resp["id"].(string) + "/prices"
+
+	next := time.Now().AddDate(0, 1, 0).Format("01-2006")
+
+	t.Run("Schedule future change", func(t *testing.T) {
+		change, status := postJSON(t, pricesURL, map[string]any{"price": 700, "effective_from": next})
+		require.Equal(t, http.StatusCreated, status)
+		assert.Equal(t, next, change["effective_from"])
+
+		body, status := request(t, pricesURL, http.MethodGet, nil)
+		require.Equal(t, http.StatusOK, status)
+
+		var prices []model.PriceChangeResponse
+		require.NoError(t, json.Unmarshal(body, &prices))
+		require.Len(t, prices, 2)
+		assert.Equal(t, 500, prices[0].Price)
+		assert.Equal(t, "01-2025", prices[0].EffectiveFrom)
+		assert.Equal(t, 700, prices[1].Price)
+	})
+
+	t.Run("Reject past month", func(t *testing.T) {
+		_, status := postJSON(t, pricesURL, map[string]any{"price": 700, "effective_from": "01-2020"})
+		assert.Equal(t, http.StatusBadRequest, status)
+	})
+
+	t.Run("Unknown subscription", func(t *testing.T) {
+		_, status := postJSON(t, baseURL+"/"+uuid.New().String()+"/prices", map[string]any{"price": 700, "effective_from": next})
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+// TestListCursorPagination checks the cursor envelope, the total-count header
+// and that offset mode keeps returning a plain array.
+func TestListCursorPagination(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	baseURL := ts.URL + "/subscriptions"
+	userID := uuid.New().String()
+
+	for i := 0; i < 3; i++ {
+		_, status := postJSON(t, baseURL+"?allow_overlap=true", map[string]any{
+			"user_id":      userID,
+			"service_name": "Netflix",
+			"price":        100,
+			"start_date":   "01-2025",
+		})
+		require.Equal(t, http.StatusCreated, status)
+	}
+
+	get := func(url string) (*http.Response, []byte) {
+		res, err := http.Get(url)
+		require.NoError(t, err)
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, body
+	}
+
+	t.Run("Cursor mode", func(t *testing.T) {
+		res, body := get(baseURL + "?user_id=" + userID + "&limit=2&cursor=")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "3", res.Header.Get("X-Total-Count"))
+
+		var first model.SubscriptionListResponse
+		require.NoError(t, json.Unmarshal(body, &first))
+		require.Len(t, first.Items, 2)
+		require.NotNil(t, first.NextCursor)
+
+		res, body = get(baseURL + "?user_id=" + userID + "&limit=2&cursor=" + *first.NextCursor)
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		var second model.SubscriptionListResponse
+		require.NoError(t, json.Unmarshal(body, &second))
+		require.Len(t, second.Items, 1)
+		assert.Nil(t, second.NextCursor)
+		assert.NotEqual(t, first.Items[1].ID, second.Items[0].ID)
+	})
+
+	t.Run("Offset mode", func(t *testing.T) {
+		res, body := get(baseURL + "?user_id=" + userID + "&limit=2&offset=2")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "3", res.Header.Get("X-Total-Count"))
+
+		var list []model.SubscriptionResponse
+		require.NoError(t, json.Unmarshal(body, &list))
+		assert.Len(t, list, 1)
+	})
+
+	t.Run("Invalid cursor", func(t *testing.T) {
+		res, _ := get(baseURL + "?cursor=garbage!")
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+
+	t.Run("Forged cursor", func(t *testing.T) {
+		forged := model.Cursor{Sort: "-created_at", Value: "not a time", ID: uuid.New()}
+		res, _ := get(baseURL + "?cursor=" + forged.Encode())
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
+
+// TestListFilters checks that list filters and sorting are taken from query parameters
+// and that invalid values are rejected.
+func TestListFilters(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	baseURL := ts.URL + "/subscriptions"
+	user1, user2 := uuid.New().String(), uuid.New().String()
+
+	for _, sub := range []map[string]any{
+		{"user_id": user1, "service_name": "Yandex Plus", "price": 300, "start_date": "01-2025"},
+		{"user_id": user1, "service_name": "Netflix", "price": 900, "start_date": "02-2025", "end_date": "04-2025"},
+		{"user_id": user2, "service_name": "yandex music", "price": 200, "start_date": "03-2025"},
+	} {
+		_, status := postJSON(t, baseURL, sub)
+		require.Equal(t, http.StatusCreated, status)
+	}
+
+	names := func(query string) []string {
+		body, status := request(t, baseURL+"?"+query, http.MethodGet, nil)
+		require.Equal(t, http.StatusOK, status, string(body))
+
+		var list []model.SubscriptionResponse
+		require.NoError(t, json.Unmarshal(body, &list))
+
+		result := make([]string, 0, len(list))
+		for _, sub := range list {
+			result = append(result, sub.ServiceName)
+		}
+		return result
+	}
+
+	assert.Equal(t, []string{"yandex music", "Netflix", "Yandex Plus"}, names("user_id="+user1+","+user2+"&sort=-start_date"))
+	assert.Equal(t, []string{"Yandex Plus", "yandex music"}, names("service_name_prefix=yandex&sort=-price"))
+	assert.Equal(t, []string{"Netflix", "Yandex Plus"}, names("min_price=250&sort=-price"))
+	assert.Equal(t, []string{"Yandex Plus", "yandex music"}, names("active_at=05-2025&sort=-price"))
+	assert.Equal(t, []string{"Netflix"}, names("has_end_date=true"))
+
+	for _, query := range []string{"sort=user_id", "min_price=abc", "active_at=2025-05", "min_price=500&max_price=100", "user_id=nope"} {
+		_, status := request(t, baseURL+"?"+query, http.MethodGet, nil)
+		assert.Equal(t, http.StatusBadRequest, status, query)
+	}
+}
+
+// TestAuthentication checks that requests need a valid bearer token and that regular users
+// only see and change their own subscriptions while admins may act for anyone.
+func TestAuthentication(t *testing.T) {
+	const secret = "handler-test-secret"
+
+	verifier, err := auth.NewVerifier(config.AuthConfig{HMACSecret: secret, AdminRole: "admin"})
+	require.NoError(t, err)
+
+	ts, cleanup := setupServer(t, verifier)
+	defer cleanup()
+
+	issue := func(subject string, roles ...string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   subject,
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+			},
+			Roles: roles,
+		}).SignedString([]byte(secret))
+		require.NoError(t, err)
+		return token
+	}
+
+	alice, bob := uuid.New(), uuid.New()
+	aliceToken, bobToken, adminToken := issue(alice.String()), issue(bob.String()), issue("ops", "admin")
+
+	t.Run("Missing Or Invalid Token", func(t *testing.T) {
+		_, status := request(t, ts.URL+"/subscriptions", http.MethodGet, nil)
+		assert.Equal(t, http.StatusUnauthorized, status)
+
+		_, status = requestAs(t, "garbage", ts.URL+"/subscriptions", http.MethodGet, nil)
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+
+	var id string
+	t.Run("Own Subscriptions", func(t *testing.T) {
+		body, status := requestAs(t, aliceToken, ts.URL+"/subscriptions", http.MethodPost, map[string]any{
+			"service_name": "Netflix",
+			"price":        500,
+			"user_id":      alice.String(),
+			"start_date":   "01-2025",
+		})
+		require.Equal(t, http.StatusCreated, status, string(body))
+
+		var created map[string]any
+		require.NoError(t, json.Unmarshal(body, &created))
+		id = created["id"].(string)
+
+		_, status = requestAs(t, aliceToken, ts.URL+"/subscriptions/"+id, http.MethodGet, nil)
+		assert.Equal(t, http.StatusOK, status)
+
+		_, status = requestAs(t, aliceToken, ts.URL+"/subscriptions/summary?from=01-2025&to=12-2025", http.MethodGet, nil)
+		assert.Equal(t, http.StatusOK, status)
+	})
+
+	t.Run("Other User", func(t *testing.T) {
+		_, status := requestAs(t, bobToken, ts.URL+"/subscriptions/"+id, http.MethodGet, nil)
+		assert.Equal(t, http.StatusForbidden, status)
+
+		_, status = requestAs(t, bobToken, ts.URL+"/subscriptions/"+id, http.MethodDelete, nil)
+		assert.Equal(t, http.StatusForbidden, status)
+
+		_, status = requestAs(t, bobToken, ts.URL+"/subscriptions?user_id="+alice.String(), http.MethodGet, nil)
+		assert.Equal(t, http.StatusForbidden, status)
+
+		body, status := requestAs(t, bobToken, ts.URL+"/subscriptions", http.MethodGet, nil)
+		require.Equal(t, http.StatusOK, status)
+		assert.JSONEq(t, "[]", string(body))
+
+		_, status = requestAs(t, bobToken, ts.URL+"/subscriptions/purge?older_than_days=0", http.MethodPost, nil)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("Admin", func(t *testing.T) {
+		_, status := requestAs(t, adminToken, ts.URL+"/subscriptions/"+id, http.MethodGet, nil)
+		assert.Equal(t, http.StatusOK, status)
+
+		_, status = requestAs(t, adminToken, ts.URL+"/subscriptions/purge?older_than_days=0", http.MethodPost, nil)
+		assert.Equal(t, http.StatusOK, status)
+	})
+}
+
+// TestAPIKeyAccess checks that admins mint and revoke API keys and that a key only reaches
+// the routes covered by its scopes.
+func TestAPIKeyAccess(t *testing.T) {
+	const secret = "handler-test-secret"
+
+	verifier, err := auth.NewVerifier(config.AuthConfig{HMACSecret: secret, AdminRole: "admin"})
+	require.NoError(t, err)
+
+	ts, cleanup := setupServer(t, verifier)
+	defer cleanup()
+
+	adminToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, auth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "ops",
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Roles: []string{"admin"},
+	}).SignedString([]byte(secret))
+	require.NoError(t, err)
+
+	body, status := requestAs(t, adminToken, ts.URL+"/api-keys", http.MethodPost, map[string]any{
+		"name":   "reporting",
+		"scopes": []string{"subscriptions:read"},
 	})
+	require.Equal(t, http.StatusCreated, status, string(body))
+
+	var created model.CreatedAPIKeyResponse
+	require.NoError(t, json.Unmarshal(body, &created))
+	require.NotEmpty(t, created.Key)
+
+	withKey := func(key string) http.Header {
+		return http.Header{handler.HeaderAPIKey: []string{key}}
+	}
+
+	t.Run("Scoped Routes", func(t *testing.T) {
+		_, status := requestWithHeader(t, withKey(created.Key), ts.URL+"/subscriptions", http.MethodGet, nil)
+		assert.Equal(t, http.StatusOK, status)
+
+		_, status = requestWithHeader(t, withKey(created.Key), ts.URL+"/subscriptions", http.MethodPost, map[string]any{
+			"service_name": "Netflix",
+			"price":        500,
+			"user_id":      uuid.NewString(),
+			"start_date":   "01-2025",
+		})
+		assert.Equal(t, http.StatusForbidden, status)
+
+		_, status = requestWithHeader(t, withKey(created.Key), ts.URL+"/subscriptions/summary?from=01-2025&to=12-2025", http.MethodGet, nil)
+		assert.Equal(t, http.StatusForbidden, status)
+
+		_, status = requestWithHeader(t, withKey(created.Key), ts.URL+"/api-keys", http.MethodGet, nil)
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("Listing Hides Secrets", func(t *testing.T) {
+		body, status := requestAs(t, adminToken, ts.URL+"/api-keys", http.MethodGet, nil)
+		require.Equal(t, http.StatusOK, status)
+		assert.NotContains(t, string(body), created.Key)
+		assert.Contains(t, string(body), created.Prefix)
+	})
+
+	t.Run("Revoked Or Unknown Key", func(t *testing.T) {
+		_, status := requestWithHeader(t, withKey("sk_unknown"), ts.URL+"/subscriptions", http.MethodGet, nil)
+		assert.Equal(t, http.StatusUnauthorized, status)
+
+		_, status = requestAs(t, adminToken, ts.URL+"/api-keys/"+created.ID.String(), http.MethodDelete, nil)
+		require.Equal(t, http.StatusNoContent, status)
+
+		_, status = requestWithHeader(t, withKey(created.Key), ts.URL+"/subscriptions", http.MethodGet, nil)
+		assert.Equal(t, http.StatusUnauthorized, status)
+	})
+}
+
+// TestProblemDetails checks that errors are returned as RFC 7807 problem documents with
+// status codes derived from the error type.
+func TestProblemDetails(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	baseURL := ts.URL + "/subscriptions"
+
+	created, status := postJSON(t, baseURL, map[string]any{
+		"service_name": "Netflix",
+		"price":        500,
+		"user_id":      uuid.NewString(),
+		"start_date":   "03-2025",
+	})
+	require.Equal(t, http.StatusCreated, status)
+	id := created["id"].(string)
+
+	problem := func(t *testing.T, method, url string, payload any) (*http.Response, map[string]any) {
+		var body io.Reader
+		if payload != nil {
+			data, err := json.Marshal(payload)
+			require.NoError(t, err)
+			body = bytes.NewReader(data)
+		}
+
+		req, err := http.NewRequest(method, url, body)
+		require.NoError(t, err)
+
+		res, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer func() { _ = res.Body.Close() }()
+
+		var doc map[string]any
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&doc))
+		return res, doc
+	}
+
+	t.Run("Update Of Missing Subscription", func(t *testing.T) {
+		res, doc := problem(t, http.MethodPut, baseURL+"/"+uuid.NewString(), map[string]any{
+			"service_name": "Netflix",
+			"price":        500,
+			"user_id":      uuid.NewString(),
+			"start_date":   "03-2025",
+		})
+
+		assert.Equal(t, http.StatusNotFound, res.StatusCode)
+		assert.Equal(t, "application/problem+json", res.Header.Get("Content-Type"))
+		assert.Equal(t, "Not Found", doc["title"])
+		assert.Equal(t, float64(http.StatusNotFound), doc["status"])
+	})
+
+	t.Run("End Before Start", func(t *testing.T) {
+		res, doc := problem(t, http.MethodPatch, baseURL+"/"+id, map[string]any{"end_date": "01-2025"})
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, "end_date cannot be before start_date", doc["detail"])
+		assert.Equal(t, []any{map[string]any{
+			"field":   "end_date",
+			"message": "end_date cannot be before start_date",
+		}}, doc["errors"])
+	})
+
+	t.Run("Invalid Patch Field", func(t *testing.T) {
+		res, doc := problem(t, http.MethodPatch, baseURL+"/"+id, map[string]any{"billing_period": "daily"})
+
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+		assert.Equal(t, []any{map[string]any{
+			"field":   "billing_period",
+			"message": "must be one of monthly, quarterly, yearly, weekly",
+		}}, doc["errors"])
+	})
+}
+
+// TestBatchEndpoints verifies bulk create, update and delete in atomic and partial mode.
+func TestBatchEndpoints(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	baseURL := ts.URL + "/subscriptions/batch"
+	userID := uuid.NewString()
+
+	item := func(name string, price int) map[string]any {
+		return map[string]any{"service_name": name, "price": price, "user_id": userID, "start_date": "03-2025"}
+	}
+
+	type batchResult struct {
+		Index        int            `json:"index"`
+		Status       int            `json:"status"`
+		ID           string         `json:"id"`
+		Version      int            `json:"version"`
+		Subscription map[string]any `json:"subscription"`
+		Error        map[string]any `json:"error"`
+	}
+	decode := func(t *testing.T, body []byte) []batchResult {
+		var resp struct {
+			Results []batchResult `json:"results"`
+		}
+		require.NoError(t, json.Unmarshal(body, &resp), string(body))
+		return resp.Results
+	}
+
+	t.Run("Atomic Create Rejects Whole Batch", func(t *testing.T) {
+		body, status := request(t, baseURL, http.MethodPost, map[string]any{
+			"items": []any{item("Netflix", 500), item("Spotify", -1)},
+		})
+
+		require.Equal(t, http.StatusBadRequest, status, string(body))
+		var problem map[string]any
+		require.NoError(t, json.Unmarshal(body, &problem))
+		assert.Equal(t, []any{map[string]any{"field": "items[1].price", "message": "must be at least 0"}}, problem["errors"])
+
+		listBody, _ := request(t, ts.URL+"/subscriptions?user_id="+userID, http.MethodGet, nil)
+		var list []model.SubscriptionResponse
+		require.NoError(t, json.Unmarshal(listBody, &list))
+		assert.Empty(t, list, "nothing is created when an item fails")
+	})
+
+	body, status := request(t, baseURL, http.MethodPost, map[string]any{
+		"items": []any{item("Netflix", 500), item("Spotify", 300)},
+	})
+	require.Equal(t, http.StatusCreated, status, string(body))
+	created := decode(t, body)
+	require.Len(t, created, 2)
+	assert.Equal(t, http.StatusCreated, created[0].Status)
+	assert.Equal(t, "Spotify", created[1].Subscription["service_name"])
+
+	t.Run("Partial Update", func(t *testing.T) {
+		first := item("Netflix", 700)
+		first["id"] = created[0].ID
+		second := item("Spotify", 400)
+		second["id"] = created[1].ID
+		second["version"] = 42
+
+		body, status := request(t, baseURL+"?atomic=false", http.MethodPut, map[string]any{
+			"items": []any{first, second, map[string]any{"id": uuid.NewString()}},
+		})
+
+		require.Equal(t, http.StatusMultiStatus, status, string(body))
+		results := decode(t, body)
+		require.Len(t, results, 3)
+		assert.Equal(t, http.StatusOK, results[0].Status)
+		assert.Equal(t, 2, results[0].Version)
+		assert.Equal(t, http.StatusPreconditionFailed, results[1].Status)
+		assert.Equal(t, http.StatusBadRequest, results[2].Status)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		missing := uuid.NewString()
+
+		body, status := request(t, baseURL+"/delete", http.MethodPost, map[string]any{
+			"ids": []string{created[0].ID, missing},
+		})
+		require.Equal(t, http.StatusNotFound, status, string(body))
+
+		body, status = request(t, baseURL+"/delete?atomic=false", http.MethodPost, map[string]any{
+			"ids": []string{created[0].ID, missing},
+		})
+		require.Equal(t, http.StatusMultiStatus, status, string(body))
+		results := decode(t, body)
+		assert.Equal(t, http.StatusNoContent, results[0].Status)
+		assert.Equal(t, http.StatusNotFound, results[1].Status)
+
+		_, status = request(t, ts.URL+"/subscriptions/"+created[0].ID, http.MethodGet, nil)
+		assert.Equal(t, http.StatusNotFound, status)
+	})
+}
+
+// TestImportCSV verifies the CSV import in dry-run and write mode, including per-row errors and
+// de-duplication against existing subscriptions.
+func TestImportCSV(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	baseURL := ts.URL + "/subscriptions"
+	userID := uuid.NewString()
+
+	_, status := postJSON(t, baseURL, map[string]any{
+		"service_name": "Netflix",
+		"price":        500,
+		"user_id":      userID,
+		"start_date":   "03-2025",
+	})
+	require.Equal(t, http.StatusCreated, status)
+
+	file := "service_name,price,currency,user_id,start_date,end_date\n" +
+		"Netflix,500,RUB," + userID + ",03-2025,\n" +
+		"Spotify,300,USD," + userID + ",03-2025,12-2025\n" +
+		"Yandex,abc,RUB," + userID + ",03-2025,\n"
+
+	importCSV := func(t *testing.T, query string) (int, map[string]any) {
+		res, err := http.Post(baseURL+"/import"+query, "text/csv", strings.NewReader(file))
+		require.NoError(t, err)
+		defer func() { _ = res.Body.Close() }()
+
+		var doc map[string]any
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&doc))
+		return res.StatusCode, doc
+	}
+
+	status, doc := importCSV(t, "?dry_run=true")
+	require.Equal(t, http.StatusOK, status, doc)
+	assert.Equal(t, float64(1), doc["imported"])
+	assert.Equal(t, float64(2), doc["failed"])
+
+	rows := doc["rows"].([]any)
+	require.Len(t, rows, 3)
+	assert.Equal(t, float64(http.StatusConflict), rows[0].(map[string]any)["status"])
+	assert.Equal(t, float64(3), rows[1].(map[string]any)["line"])
+	assert.Equal(t, float64(http.StatusBadRequest), rows[2].(map[string]any)["status"])
+
+	body, _ := request(t, baseURL+"?user_id="+userID, http.MethodGet, nil)
+	var list []model.SubscriptionResponse
+	require.NoError(t, json.Unmarshal(body, &list))
+	assert.Len(t, list, 1, "dry run does not write")
+
+	status, doc = importCSV(t, "")
+	require.Equal(t, http.StatusCreated, status, doc)
+	assert.Equal(t, float64(1), doc["imported"])
+
+	body, _ = request(t, baseURL+"?user_id="+userID, http.MethodGet, nil)
+	require.NoError(t, json.Unmarshal(body, &list))
+	assert.Len(t, list, 2)
+
+	res, err := http.Post(baseURL+"/import", "application/json", strings.NewReader(file))
+	require.NoError(t, err)
+	_ = res.Body.Close()
+	assert.Equal(t, http.StatusUnsupportedMediaType, res.StatusCode)
+}
+
+// TestExport verifies the CSV, JSON Lines and XLSX exports, their headers and the monthly cost column.
+func TestExport(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	baseURL := ts.URL + "/subscriptions"
+	userID := uuid.NewString()
+
+	for _, sub := range []map[string]any{
+		{"service_name": "Netflix", "price": 500, "user_id": userID, "start_date": "01-2025"},
+		{"service_name": "Yandex", "price": 1200, "billing_period": "yearly", "user_id": userID, "start_date": "02-2025"},
+	} {
+		_, status := postJSON(t, baseURL, sub)
+		require.Equal(t, http.StatusCreated, status)
+	}
+
+	export := func(t *testing.T, format string) (*http.Response, []byte) {
+		res, err := http.Get(baseURL + "/export?format=" + format + "&user_id=" + userID + "&sort=start_date")
+		require.NoError(t, err)
+		defer func() { _ = res.Body.Close() }()
+
+		body, err := io.ReadAll(res.Body)
+		require.NoError(t, err)
+		return res, body
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		res, body := export(t, "csv")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+		assert.Equal(t, "text/csv; charset=utf-8", res.Header.Get("Content-Type"))
+		assert.Contains(t, res.Header.Get("Content-Disposition"), `attachment; filename=subscriptions-`)
+
+		records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, "monthly_cost", records[0][8])
+		assert.Equal(t, []string{"Yandex", "1200", "100"}, []string{records[2][2], records[2][3], records[2][8]})
+	})
+
+	t.Run("JSON Lines", func(t *testing.T) {
+		res, body := export(t, "jsonl")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+		require.Len(t, lines, 2)
+
+		var row model.ExportRow
+		require.NoError(t, json.Unmarshal([]byte(lines[0]), &row))
+		assert.Equal(t, "Netflix", row.ServiceName)
+		assert.Equal(t, 500, row.MonthlyCost)
+	})
+
+	t.Run("XLSX", func(t *testing.T) {
+		res, body := export(t, "xlsx")
+		require.Equal(t, http.StatusOK, res.StatusCode)
+
+		file, err := excelize.OpenReader(bytes.NewReader(body))
+		require.NoError(t, err)
+		defer func() { _ = file.Close() }()
+
+		rows, err := file.GetRows("Sheet1")
+		require.NoError(t, err)
+		require.Len(t, rows, 3)
+		assert.Equal(t, "Netflix", rows[1][2])
+	})
+
+	t.Run("Unknown Format", func(t *testing.T) {
+		res, _ := export(t, "pdf")
+		assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+	})
+}
+
+// TestOverlapConflict verifies that a subscription overlapping another one to the same service is rejected
+// with 409 and the conflicting ID, unless allow_overlap is set.
+func TestOverlapConflict(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	baseURL := ts.URL + "/subscriptions"
+	userID := uuid.NewString()
+
+	created, status := postJSON(t, baseURL, map[string]any{
+		"service_name": "Netflix",
+		"price":        500,
+		"user_id":      userID,
+		"start_date":   "01-2025",
+		"end_date":     "06-2025",
+	})
+	require.Equal(t, http.StatusCreated, status)
+
+	overlapping := map[string]any{
+		"service_name": " netflix",
+		"price":        700,
+		"user_id":      userID,
+		"start_date":   "06-2025",
+	}
+
+	body, status := request(t, baseURL, http.MethodPost, overlapping)
+	require.Equal(t, http.StatusConflict, status, string(body))
+	var problem map[string]any
+	require.NoError(t, json.Unmarshal(body, &problem))
+	assert.Equal(t, created["id"], problem["conflicting_id"])
+
+	overlapping["start_date"] = "07-2025"
+	_, status = postJSON(t, baseURL, overlapping)
+	assert.Equal(t, http.StatusCreated, status, "periods that do not share a month do not overlap")
+
+	overlapping["start_date"] = "03-2025"
+	allowed, status := postJSON(t, baseURL+"?allow_overlap=true", overlapping)
+	require.Equal(t, http.StatusCreated, status)
+	allowedURL := baseURL + "/" + allowed["id"].(string)
+
+	// Like the overlaps grandfathered by the migration, the override is kept by writes that do not mention it.
+	body, status = request(t, allowedURL, http.MethodPatch, map[string]any{"price": 800})
+	assert.Equal(t, http.StatusOK, status, string(body))
+
+	overlapping["price"] = 900
+	body, status = request(t, allowedURL, http.MethodPut, overlapping)
+	assert.Equal(t, http.StatusOK, status, string(body))
+
+	_, status = request(t, allowedURL+"?allow_overlap=false", http.MethodPatch, map[string]any{"price": 1000})
+	assert.Equal(t, http.StatusConflict, status, "the override can still be cleared explicitly")
+
+	_, status = request(t, baseURL+"?allow_overlap=maybe", http.MethodPost, overlapping)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+// TestServiceCatalog verifies the catalog endpoints and that subscriptions created under an alias are stored
+// with the canonical name and can be filtered by catalog entry and category.
+func TestServiceCatalog(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	servicesURL := ts.URL + "/services"
+	baseURL := ts.URL + "/subscriptions"
+	userID := uuid.NewString()
+
+	entry, status := postJSON(t, servicesURL, map[string]any{
+		"name":          "Netflix",
+		"aliases":       []string{"NETFLIX ", "Нетфликс"},
+		"category":      "Video",
+		"default_price": 799,
+	})
+	require.Equal(t, http.StatusCreated, status, entry)
+	assert.Equal(t, []any{"нетфликс"}, entry["aliases"])
+	assert.Equal(t, "video", entry["category"])
+	assert.Equal(t, "RUB", entry["currency"])
+	serviceID := entry["id"].(string)
+
+	_, status = postJSON(t, servicesURL, map[string]any{"name": "Кино", "aliases": []string{"нетфликс"}})
+	assert.Equal(t, http.StatusConflict, status)
+
+	sub, status := postJSON(t, baseURL, map[string]any{
+		"service_name": " нетфликс",
+		"price":        799,
+		"user_id":      userID,
+		"start_date":   "01-2025",
+	})
+	require.Equal(t, http.StatusCreated, status, sub)
+	assert.Equal(t, "Netflix", sub["service_name"])
+	assert.Equal(t, serviceID, sub["service_id"])
+
+	_, status = postJSON(t, baseURL, map[string]any{
+		"service_name": "Yandex Plus",
+		"price":        300,
+		"user_id":      userID,
+		"start_date":   "01-2025",
+	})
+	require.Equal(t, http.StatusCreated, status)
+
+	for _, query := range []string{"service_id=" + serviceID, "category=video"} {
+		body, status := request(t, baseURL+"?user_id="+userID+"&"+query, http.MethodGet, nil)
+		require.Equal(t, http.StatusOK, status, string(body))
+		var list []model.SubscriptionResponse
+		require.NoError(t, json.Unmarshal(body, &list))
+		require.Len(t, list, 1, query)
+		assert.Equal(t, "Netflix", list[0].ServiceName)
+	}
+
+	body, status := request(t, baseURL+"/summary?from=01-2025&to=02-2025&category=video&user_id="+userID, http.MethodGet, nil)
+	require.Equal(t, http.StatusOK, status, string(body))
+	var total model.TotalResponse
+	require.NoError(t, json.Unmarshal(body, &total))
+	assert.Equal(t, 1598, total.Total)
+
+	_, status = request(t, baseURL+"?service_id=bad", http.MethodGet, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	body, status = request(t, servicesURL+"?category=VIDEO", http.MethodGet, nil)
+	require.Equal(t, http.StatusOK, status)
+	var services []model.ServiceResponse
+	require.NoError(t, json.Unmarshal(body, &services))
+	require.Len(t, services, 1)
+
+	_, status = request(t, servicesURL+"/"+serviceID, http.MethodDelete, nil)
+	assert.Equal(t, http.StatusNoContent, status)
+	_, status = request(t, servicesURL+"/"+serviceID, http.MethodGet, nil)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+// TestCategoryAnalytics verifies the spend per category and the share of each category in the total.
+func TestCategoryAnalytics(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	userID := uuid.NewString()
+
+	for _, entry := range []map[string]any{
+		{"name": "Netflix", "category": "streaming"},
+		{"name": "Spotify", "category": "music"},
+	} {
+		_, status := postJSON(t, ts.URL+"/services", entry)
+		require.Equal(t, http.StatusCreated, status)
+	}
+
+	for name, price := range map[string]int{"netflix": 900, "Spotify": 600, "Yandex": 500} {
+		_, status := postJSON(t, ts.URL+"/subscriptions", map[string]any{
+			"service_name": name,
+			"price":        price,
+			"user_id":      userID,
+			"start_date":   "01-2025",
+		})
+		require.Equal(t, http.StatusCreated, status)
+	}
+
+	body, status := request(t, ts.URL+"/analytics/categories?from=01-2025&to=02-2025&user_id="+userID, http.MethodGet, nil)
+	require.Equal(t, http.StatusOK, status, string(body))
+
+	var resp model.CategoryAnalyticsResponse
+	require.NoError(t, json.Unmarshal(body, &resp))
+	assert.Equal(t, 4000, resp.Total)
+	assert.Equal(t, "RUB", resp.Currency)
+	require.Len(t, resp.Categories, 3)
+	assert.Equal(t, "streaming", *resp.Categories[0].Category)
+	assert.Equal(t, 1800, resp.Categories[0].Total)
+	assert.Equal(t, 45.0, resp.Categories[0].Share)
+	assert.Nil(t, resp.Categories[2].Category)
+	assert.Equal(t, 25.0, resp.Categories[2].Share)
+
+	_, status = request(t, ts.URL+"/analytics/categories?from=01-2025", http.MethodGet, nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+}
+
+// TestUpcomingCharges verifies the charges listed within the look-ahead window.
+func TestUpcomingCharges(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	userID := uuid.NewString()
+	thisMonth := time.Now().UTC().Format("01-2006")
+
+	for _, payload := range []map[string]any{
+		{"service_name": "Gym", "price": 700, "billing_period": "weekly", "start_date": thisMonth},
+		{"service_name": "Old", "price": 300, "start_date": "01-2020", "end_date": "12-2020"},
+	} {
+		payload["user_id"] = userID
+		_, status := postJSON(t, ts.URL+"/subscriptions", payload)
+		require.Equal(t, http.StatusCreated, status)
+	}
+
+	body, status := request(t, ts.URL+"/users/"+userID+"/upcoming?within=30d", http.MethodGet, nil)
+	require.Equal(t, http.StatusOK, status, string(body))
+
+	var resp model.UpcomingResponse
+	require.NoError(t, json.Unmarshal(body, &resp))
+
+	// A weekly subscription is charged 4 or 5 times within 30 days; the ended one is not charged.
+	assert.GreaterOrEqual(t, len(resp.Charges), 4)
+	assert.LessOrEqual(t, len(resp.Charges), 5)
+	for _, c := range resp.Charges {
+		assert.Equal(t, "Gym", c.ServiceName)
+		assert.Equal(t, 700, c.Amount)
+		assert.GreaterOrEqual(t, c.Date, resp.From)
+		assert.LessOrEqual(t, c.Date, resp.To)
+	}
+
+	for _, within := range []string{"abc", "0d", "400d"} {
+		_, status = request(t, ts.URL+"/users/"+userID+"/upcoming?within="+within, http.MethodGet, nil)
+		assert.Equal(t, http.StatusBadRequest, status, within)
+	}
+}
+
+// TestWebhookDelivery verifies the webhook endpoints and the delivery of outbox events to an httptest receiver,
+// including signatures, dead-lettering and redelivery.
+func TestWebhookDelivery(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	database, err := db.Connect(ctx, getTestConfig())
+	require.NoError(t, err)
+	defer database.Pool.Close()
+
+	var failing atomic.Bool
+	events := make(chan model.Event, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(webhook.HeaderTimestamp), 10, 64)
+		if !webhook.Verify("test-webhook-secret", timestamp, body, r.Header.Get(webhook.HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if failing.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var event model.Event
+		_ = json.Unmarshal(body, &event)
+		events <- event
+	}))
+	defer receiver.Close()
+
+	hooksURL := ts.URL + "/webhooks"
+	hook, status := postJSON(t, hooksURL, map[string]any{
+		"url":    receiver.URL,
+		"secret": "test-webhook-secret",
+		"events": []string{model.EventTypeCreated},
+	})
+	require.Equal(t, http.StatusCreated, status, hook)
+	assert.Equal(t, "test-webhook-secret", hook["secret"])
+	hookID := hook["id"].(string)
+
+	body, status := request(t, hooksURL+"/"+hookID, http.MethodGet, nil)
+	require.Equal(t, http.StatusOK, status)
+	assert.NotContains(t, string(body), "test-webhook-secret")
+
+	_, status = postJSON(t, hooksURL, map[string]any{"url": "not a url"})
+	assert.Equal(t, http.StatusBadRequest, status)
+	_, status = postJSON(t, hooksURL, map[string]any{"url": receiver.URL, "events": []string{"subscription.renamed"}})
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	sub, status := postJSON(t, ts.URL+"/subscriptions", map[string]any{
+		"service_name": "Netflix",
+		"price":        799,
+		"user_id":      uuid.NewString(),
+		"start_date":   "01-2025",
+	})
+	require.Equal(t, http.StatusCreated, status)
+
+	// Only the subscribed event type is delivered.
+	_, status = request(t, ts.URL+"/subscriptions/"+sub["id"].(string), http.MethodDelete, nil)
+	require.Equal(t, http.StatusNoContent, status)
+
+	dispatcher := service.NewWebhookDispatcher(
+		repository.NewWebhookRepository(database.Pool),
+		repository.NewOutboxRepository(database.Pool),
+		webhook.NewHTTPSender(time.Second),
+		service.RetryPolicy{MaxAttempts: 1, Backoff: time.Second, MaxBackoff: time.Second},
+		10,
+		time.Minute,
+	)
+
+	delivered, err := dispatcher.Dispatch(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+
+	event := <-events
+	assert.Equal(t, model.EventTypeCreated, event.Type)
+	assert.Contains(t, string(event.Data), "Netflix")
+
+	// A failed delivery is dead-lettered after the last attempt and can be redelivered.
+	failing.Store(true)
+	_, status = postJSON(t, ts.URL+"/subscriptions", map[string]any{
+		"service_name": "Spotify",
+		"price":        299,
+		"user_id":      uuid.NewString(),
+		"start_date":   "01-2025",
+	})
+	require.Equal(t, http.StatusCreated, status)
+
+	delivered, err = dispatcher.Dispatch(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Zero(t, delivered)
+
+	body, status = request(t, hooksURL+"/"+hookID+"/deliveries?status=dead", http.MethodGet, nil)
+	require.Equal(t, http.StatusOK, status, string(body))
+	var dead []model.WebhookDeliveryResponse
+	require.NoError(t, json.Unmarshal(body, &dead))
+	require.Len(t, dead, 1)
+	assert.Equal(t, 1, dead[0].Attempts)
+	require.NotNil(t, dead[0].LastStatus)
+	assert.Equal(t, http.StatusInternalServerError, *dead[0].LastStatus)
+
+	failing.Store(false)
+	_, status = request(t, hooksURL+"/"+hookID+"/deliveries/"+dead[0].ID.String()+"/redeliver", http.MethodPost, nil)
+	require.Equal(t, http.StatusAccepted, status)
+
+	delivered, err = dispatcher.Dispatch(ctx, time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, delivered)
+	assert.Contains(t, string((<-events).Data), "Spotify")
+
+	_, status = request(t, hooksURL+"/"+hookID, http.MethodDelete, nil)
+	assert.Equal(t, http.StatusNoContent, status)
+	_, status = request(t, hooksURL+"/"+hookID+"/deliveries", http.MethodGet, nil)
+	assert.Equal(t, http.StatusNotFound, status)
+}
+
+// slowSender is a webhook sender that takes delay to accept every delivery and counts the deliveries it received.
+type slowSender struct {
+	delay time.Duration
+
+	mu   sync.Mutex
+	sent map[uuid.UUID]int
+}
+
+func (s *slowSender) Send(_ context.Context, delivery *model.WebhookDelivery) (int, error) {
+	time.Sleep(s.delay)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent[delivery.ID]++
+	return http.StatusNoContent, nil
+}
+
+func (s *slowSender) received() map[uuid.UUID]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.sent)
+}
+
+// TestWebhookDispatchLease verifies that concurrent dispatchers send every delivery once, even when sending a
+// claimed batch takes longer than the lease of its deliveries.
+func TestWebhookDispatchLease(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	database, err := db.Connect(ctx, getTestConfig())
+	require.NoError(t, err)
+	defer database.Pool.Close()
+
+	_, status := postJSON(t, ts.URL+"/webhooks", map[string]any{
+		"url":    "https://example.com/hooks",
+		"events": []string{model.EventTypeCreated},
+	})
+	require.Equal(t, http.StatusCreated, status)
+
+	const subscriptions = 4
+	for i := range subscriptions {
+		_, status := postJSON(t, ts.URL+"/subscriptions", map[string]any{
+			"service_name": fmt.Sprintf("Service %d", i),
+			"price":        100,
+			"user_id":      uuid.NewString(),
+			"start_date":   "01-2025",
+		})
+		require.Equal(t, http.StatusCreated, status)
+	}
+
+	// A batch takes 4 × 300ms to send, so the lease of its last deliveries expires before they are attempted.
+	sender := &slowSender{delay: 300 * time.Millisecond, sent: make(map[uuid.UUID]int)}
+	newDispatcher := func() service.WebhookDispatcher {
+		return service.NewWebhookDispatcher(
+			repository.NewWebhookRepository(database.Pool),
+			repository.NewOutboxRepository(database.Pool),
+			sender,
+			service.RetryPolicy{MaxAttempts: 3, Backoff: time.Second, MaxBackoff: time.Second},
+			10,
+			500*time.Millisecond,
+		)
+	}
+
+	var wg sync.WaitGroup
+	deadline := time.Now().Add(10 * time.Second)
+	for range 2 {
+		dispatcher := newDispatcher()
+		wg.Go(func() {
+			for len(sender.received()) < subscriptions && time.Now().Before(deadline) {
+				_, err := dispatcher.Dispatch(ctx, time.Now())
+				assert.NoError(t, err)
+				time.Sleep(50 * time.Millisecond)
+			}
+		})
+	}
+	wg.Wait()
+
+	sent := sender.received()
+	require.Len(t, sent, subscriptions)
+	for id, count := range sent {
+		assert.Equal(t, 1, count, "delivery %s was sent %d times", id, count)
+	}
+}
+
+// TestMetrics verifies that requests are labelled by route pattern and queries by repository method.
+func TestMetrics(t *testing.T) {
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+
+	created, status := postJSON(t, ts.URL+"/subscriptions", map[string]any{
+		"service_name": "Netflix",
+		"price":        500,
+		"user_id":      uuid.NewString(),
+		"start_date":   "01-2025",
+	})
+	require.Equal(t, http.StatusCreated, status)
+
+	for _, id := range []string{created["id"].(string), uuid.NewString()} {
+		request(t, ts.URL+"/subscriptions/"+id, http.MethodGet, nil)
+	}
+
+	body, status := request(t, ts.URL+"/metrics", http.MethodGet, nil)
+	require.Equal(t, http.StatusOK, status)
+
+	exposition := string(body)
+	assert.Contains(t, exposition, `http_requests_total{method="POST",route="/subscriptions",status="201"} 1`)
+	assert.Contains(t, exposition, `http_requests_total{method="GET",route="/subscriptions/{id}",status="200"} 1`)
+	assert.Contains(t, exposition, `http_requests_total{method="GET",route="/subscriptions/{id}",status="404"} 1`)
+	assert.Contains(t, exposition, `db_query_duration_seconds_count{method="subscriptionRepo.Create"}`)
+	assert.Contains(t, exposition, `db_query_duration_seconds_count{method="subscriptionRepo.GetByID"}`)
+	assert.Contains(t, exposition, "db_pool_max_conns")
+	assert.Contains(t, exposition, "subscriptions_active 1")
+	assert.Contains(t, exposition, "subscription_users_active 1")
+}
+
+// TestTracing verifies that a request continues the trace of its traceparent and that the service call and
+// the repository queries it makes are nested in the request span.
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	ts, cleanup := setupTestServer(t)
+	defer cleanup()
+	_, err := tracing.Setup(context.Background(), config.TracingConfig{})
+	require.NoError(t, err)
+
+	header := http.Header{}
+	header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	_, status := requestWithHeader(t, header, ts.URL+"/subscriptions/summary?from=01-2025&to=03-2025", http.MethodGet, nil)
+	require.Equal(t, http.StatusOK, status)
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		if span.SpanContext().TraceID().String() == "4bf92f3577b34da6a3ce929d0e0e4736" {
+			spans[span.Name()] = span
+		}
+	}
+
+	request := spans["GET /subscriptions/summary"]
+	require.NotNil(t, request)
+	assert.Equal(t, "00f067aa0ba902b7", request.Parent().SpanID().String())
+
+	aggregate := spans["SubscriptionService.Aggregate"]
+	require.NotNil(t, aggregate)
+	assert.Equal(t, request.SpanContext().SpanID(), aggregate.Parent().SpanID())
+
+	query := spans["subscriptionRepo.AggregateCost"]
+	require.NotNil(t, query)
+	assert.Equal(t, aggregate.SpanContext().SpanID(), query.Parent().SpanID())
 }